@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,20 @@ type RedisConfig struct {
 	Password string
 }
 
+// Cache backends selectable with CACHE_BACKEND
+const (
+	CacheBackendRedis  = "redis"
+	CacheBackendMemory = "memory"
+)
+
+// CacheConfig selects where cached data and sessions are stored. The memory
+// backend is local to one process, so it suits tests and single-instance runs.
+type CacheConfig struct {
+	Backend         string
+	MaxItems        int           // memory backend only; 0 leaves it unbounded. Sessions and queue, lock and version state are stored apart and never evicted
+	CleanupInterval time.Duration // memory backend only; how often expired keys are dropped
+}
+
 type Oauth2Config struct {
 	GoogleClientID     string
 	GoogleClientSecret string
@@ -39,25 +54,92 @@ type PostgresConfig struct {
 
 type Config struct {
 	AppEnv        string
+	Cache         CacheConfig
 	Redis         RedisConfig
 	Postgres      PostgresConfig
 	OAuth2        Oauth2Config
 	Observability ObservabilityConfig
 	Group         GroupConfig
+	Admin         AdminConfig
+	Tag           TagConfig
+	Experiment    ExperimentConfig
+	Feed          FeedConfig
 }
 
 type GroupConfig struct {
-	DefaultCapacity     int
-	MaxCapacity         int
-	ApplicationTTLHours int
+	DefaultCapacity      int
+	MaxCapacity          int
+	ApplicationTTLHours  int
+	AutoFormInterval     time.Duration // 0 disables scheduled auto-formation
+	MatchInterval        time.Duration // how often the matchmaking queue is scanned
+	MatchAcceptWindow    time.Duration // time users have to accept a match proposal
+	MatchEntryTTL        time.Duration // queued users are dropped after this long without polling their status, 0 keeps them
+	BuddyPassDuration    time.Duration // how long a passed user is not suggested again
+	RecommendInterval    time.Duration // rebuild of collaborative-filtering recommendations, 0 disables
+	RecommendWeight      float64       // share of collaborative filtering in discover scores
+	MemberProfileWeight  float64       // share of fit with a group's current members in content scores, 0 disables
+	CacheTTL             time.Duration // how long group details and member lists are cached, 0 disables
+	DiscoverCacheTTL     time.Duration // how long signed-in users' discover results are cached, 0 disables
+	DiscoverCacheAnonTTL time.Duration // same for anonymous results, which all visitors share
+	Ranking              RankingConfig
+}
+
+// RankingConfig tunes the optional ranking stages applied to discover results
+type RankingConfig struct {
+	DiversityLambda    float64 // 1 keeps relevance order, lower values diversify tags and owners
+	FreshnessWeight    float64 // 0 disables the freshness boost
+	FreshnessHalfLife  time.Duration
+	PopularityWeight   float64 // 0 disables the popularity boost
+	PopularityHalfLife time.Duration
+	BookmarkWeight     float64 // 0 disables the bookmark boost
+}
+
+type TagConfig struct {
+	PopularWindow   time.Duration // usage older than this is ignored by popular tags
+	CacheTTL        time.Duration
+	RefreshInterval time.Duration // background refresh of popular tags
+}
+
+// ExperimentConfig locates the A/B experiment definitions
+type ExperimentConfig struct {
+	File           string        // JSON experiment definitions, empty disables experiments
+	ReloadInterval time.Duration // how often the file is checked for changes
+}
+
+// FeedConfig tunes the public Atom and JSON feeds
+type FeedConfig struct {
+	BaseURL  string        // public URL of the API, used for links in feeds
+	CacheTTL time.Duration // how long a rendered feed is served from Redis
+	MaxItems int
+}
+
+type AdminConfig struct {
+	UserIDs []string
 }
 
 func Load() (*Config, error) {
 	var errs []error
 
 	appEnv := mustEnv("APP_ENV", &errs)
-	host := mustEnv("REDIS_HOST", &errs)
-	port := mustEnv("REDIS_PORT", &errs)
+
+	// ==========
+	// Cache
+	// ==========
+	cacheBackend := getEnvOrDefault("CACHE_BACKEND", CacheBackendRedis)
+	cacheMaxItems := getEnvAsIntOrDefault("CACHE_MAX_ITEMS", 100000)
+	cacheCleanupInterval := getEnvAsDurationOrDefault("CACHE_CLEANUP_INTERVAL", time.Minute)
+
+	var host, port string
+	switch cacheBackend {
+	case CacheBackendRedis:
+		host = mustEnv("REDIS_HOST", &errs)
+		port = mustEnv("REDIS_PORT", &errs)
+	case CacheBackendMemory:
+		host = getEnvOrDefault("REDIS_HOST", "")
+		port = getEnvOrDefault("REDIS_PORT", "")
+	default:
+		errs = append(errs, errors.New("invalid CACHE_BACKEND: "+cacheBackend))
+	}
 	password := getEnvOrDefault("REDIS_PASSWORD", "")
 
 	// ==========
@@ -104,9 +186,56 @@ func Load() (*Config, error) {
 	defaultCapacity := getEnvAsIntOrDefault("GROUP_DEFAULT_CAPACITY", 5)
 	maxCapacity := getEnvAsIntOrDefault("GROUP_MAX_CAPACITY", 10)
 	applicationTTL := getEnvAsIntOrDefault("GROUP_APPLICATION_TTL_HOURS", 72)
+	autoFormInterval := getEnvAsDurationOrDefault("GROUP_AUTO_FORM_INTERVAL", 0)
+	matchInterval := getEnvAsDurationOrDefault("GROUP_MATCH_INTERVAL", 5*time.Second)
+	matchAcceptWindow := getEnvAsDurationOrDefault("GROUP_MATCH_ACCEPT_WINDOW", 2*time.Minute)
+	matchEntryTTL := getEnvAsDurationOrDefault("GROUP_MATCH_ENTRY_TTL", 10*time.Minute)
+	buddyPassDuration := getEnvAsDurationOrDefault("GROUP_BUDDY_PASS_DURATION", 30*24*time.Hour)
+	recommendInterval := getEnvAsDurationOrDefault("GROUP_RECOMMEND_INTERVAL", time.Hour)
+	recommendWeight := getEnvAsFloatOrDefault("GROUP_RECOMMEND_WEIGHT", 0.3)
+	memberProfileWeight := getEnvAsFloatOrDefault("GROUP_MEMBER_PROFILE_WEIGHT", 0)
+	groupCacheTTL := getEnvAsDurationOrDefault("GROUP_CACHE_TTL", 5*time.Minute)
+	discoverCacheTTL := getEnvAsDurationOrDefault("GROUP_DISCOVER_CACHE_TTL", time.Minute)
+	discoverCacheAnonTTL := getEnvAsDurationOrDefault("GROUP_DISCOVER_CACHE_ANON_TTL", 5*time.Minute)
+	diversityLambda := getEnvAsFloatOrDefault("GROUP_RANK_DIVERSITY_LAMBDA", 1)
+	freshnessWeight := getEnvAsFloatOrDefault("GROUP_RANK_FRESHNESS_WEIGHT", 0)
+	freshnessHalfLife := getEnvAsDurationOrDefault("GROUP_RANK_FRESHNESS_HALF_LIFE", 7*24*time.Hour)
+	popularityWeight := getEnvAsFloatOrDefault("GROUP_RANK_POPULARITY_WEIGHT", 0)
+	popularityHalfLife := getEnvAsDurationOrDefault("GROUP_RANK_POPULARITY_HALF_LIFE", 3*24*time.Hour)
+	bookmarkWeight := getEnvAsFloatOrDefault("GROUP_RANK_BOOKMARK_WEIGHT", 0)
+
+	// ==========
+	// Tag configuration
+	// ==========
+	tagPopularWindow := getEnvAsDurationOrDefault("TAG_POPULAR_WINDOW", 30*24*time.Hour)
+	tagCacheTTL := getEnvAsDurationOrDefault("TAG_CACHE_TTL", 10*time.Minute)
+	tagRefreshInterval := getEnvAsDurationOrDefault("TAG_REFRESH_INTERVAL", 5*time.Minute)
+
+	// ==========
+	// Experiments
+	// ==========
+	experimentsFile := getEnvOrDefault("EXPERIMENTS_FILE", "")
+	experimentsReloadInterval := getEnvAsDurationOrDefault("EXPERIMENTS_RELOAD_INTERVAL", 30*time.Second)
+
+	// ==========
+	// Feeds
+	// ==========
+	feedBaseURL := getEnvOrDefault("FEED_BASE_URL", "http://localhost:8080")
+	feedCacheTTL := getEnvAsDurationOrDefault("FEED_CACHE_TTL", 5*time.Minute)
+	feedMaxItems := getEnvAsIntOrDefault("FEED_MAX_ITEMS", 50)
+
+	// ==========
+	// Admin
+	// ==========
+	adminUserIDs := getEnvAsList("ADMIN_USER_IDS")
 
 	return &Config{
 		AppEnv: appEnv,
+		Cache: CacheConfig{
+			Backend:         cacheBackend,
+			MaxItems:        cacheMaxItems,
+			CleanupInterval: cacheCleanupInterval,
+		},
 		Redis: RedisConfig{
 			Host:     host,
 			Port:     port,
@@ -133,9 +262,45 @@ func Load() (*Config, error) {
 			SSLMode:  pgSSL,
 		},
 		Group: GroupConfig{
-			DefaultCapacity:     defaultCapacity,
-			MaxCapacity:         maxCapacity,
-			ApplicationTTLHours: applicationTTL,
+			DefaultCapacity:      defaultCapacity,
+			MaxCapacity:          maxCapacity,
+			ApplicationTTLHours:  applicationTTL,
+			AutoFormInterval:     autoFormInterval,
+			MatchInterval:        matchInterval,
+			MatchAcceptWindow:    matchAcceptWindow,
+			MatchEntryTTL:        matchEntryTTL,
+			BuddyPassDuration:    buddyPassDuration,
+			RecommendInterval:    recommendInterval,
+			RecommendWeight:      recommendWeight,
+			MemberProfileWeight:  memberProfileWeight,
+			CacheTTL:             groupCacheTTL,
+			DiscoverCacheTTL:     discoverCacheTTL,
+			DiscoverCacheAnonTTL: discoverCacheAnonTTL,
+			Ranking: RankingConfig{
+				DiversityLambda:    diversityLambda,
+				FreshnessWeight:    freshnessWeight,
+				FreshnessHalfLife:  freshnessHalfLife,
+				PopularityWeight:   popularityWeight,
+				PopularityHalfLife: popularityHalfLife,
+				BookmarkWeight:     bookmarkWeight,
+			},
+		},
+		Admin: AdminConfig{
+			UserIDs: adminUserIDs,
+		},
+		Tag: TagConfig{
+			PopularWindow:   tagPopularWindow,
+			CacheTTL:        tagCacheTTL,
+			RefreshInterval: tagRefreshInterval,
+		},
+		Experiment: ExperimentConfig{
+			File:           experimentsFile,
+			ReloadInterval: experimentsReloadInterval,
+		},
+		Feed: FeedConfig{
+			BaseURL:  feedBaseURL,
+			CacheTTL: feedCacheTTL,
+			MaxItems: feedMaxItems,
 		},
 	}, nil
 }
@@ -171,3 +336,42 @@ func getEnvAsIntOrDefault(key string, defaultValue int) int {
 
 	return value
 }
+
+func getEnvAsDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+func getEnvAsFloatOrDefault(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvAsList splits a comma separated environment variable, skipping empty items.
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	return values
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bmatch/internal/service/group"
+)
+
+// Dataset is a snapshot of users, groups and the joins that happened
+type Dataset struct {
+	Users    []*group.UserProfile `json:"users"`
+	Groups   []*group.Group       `json:"groups"`
+	Joins    []Join               `json:"joins"`
+	Taxonomy map[string]string    `json:"taxonomy"` // child tag -> parent tag
+}
+
+type Join struct {
+	UserID   string    `json:"user_id"`
+	GroupID  string    `json:"group_id"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// loadDataset reads a JSON file, or a directory of CSV files:
+//
+//	users.csv     user_id,tags,skill_level,availability,intent
+//	groups.csv    id,owner_id,title,tags,capacity,join_type,created_at
+//	joins.csv     user_id,group_id,joined_at
+//	taxonomy.csv  tag,parent (optional)
+//
+// CSV files start with a header row, list columns are separated by ";"
+// and times are RFC 3339.
+func loadDataset(path string) (*Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ds *Dataset
+	if info.IsDir() {
+		ds, err = loadCSV(path)
+	} else {
+		ds, err = loadJSON(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if ds.Taxonomy == nil {
+		ds.Taxonomy = map[string]string{}
+	}
+
+	return ds, nil
+}
+
+func loadJSON(path string) (*Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return &ds, nil
+}
+
+func loadCSV(dir string) (*Dataset, error) {
+	ds := &Dataset{Taxonomy: map[string]string{}}
+
+	err := readCSV(filepath.Join(dir, "users.csv"), 5, func(r []string) error {
+		ds.Users = append(ds.Users, &group.UserProfile{
+			UserID:       r[0],
+			Tags:         splitList(r[1]),
+			SkillLevel:   r[2],
+			Availability: splitList(r[3]),
+			Intent:       r[4],
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readCSV(filepath.Join(dir, "groups.csv"), 7, func(r []string) error {
+		capacity, err := strconv.Atoi(r[4])
+		if err != nil {
+			return fmt.Errorf("capacity: %w", err)
+		}
+		createdAt, err := time.Parse(time.RFC3339, r[6])
+		if err != nil {
+			return fmt.Errorf("created_at: %w", err)
+		}
+		ds.Groups = append(ds.Groups, &group.Group{
+			ID:        r[0],
+			OwnerID:   r[1],
+			Title:     r[2],
+			Tags:      splitList(r[3]),
+			Capacity:  capacity,
+			JoinType:  r[5],
+			Status:    group.StatusOpen,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readCSV(filepath.Join(dir, "joins.csv"), 3, func(r []string) error {
+		joinedAt, err := time.Parse(time.RFC3339, r[2])
+		if err != nil {
+			return fmt.Errorf("joined_at: %w", err)
+		}
+		ds.Joins = append(ds.Joins, Join{UserID: r[0], GroupID: r[1], JoinedAt: joinedAt})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readCSV(filepath.Join(dir, "taxonomy.csv"), 2, func(r []string) error {
+		ds.Taxonomy[r[0]] = r[1]
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// readCSV calls fn for every row after the header
+func readCSV(path string, columns int, fn func([]string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = columns
+
+	if _, err := r.Read(); err != nil {
+		return fmt.Errorf("%s: read header: %w", path, err)
+	}
+
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := fn(record); err != nil {
+			return fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+	}
+}
+
+func splitList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// split divides joins into history and the joins to predict. With a zero
+// cutoff each user's latest join is held out; otherwise joins at or after
+// the cutoff are held out.
+func split(joins []Join, cutoff time.Time) (train, test []Join) {
+	if !cutoff.IsZero() {
+		for _, j := range joins {
+			if j.JoinedAt.Before(cutoff) {
+				train = append(train, j)
+			} else {
+				test = append(test, j)
+			}
+		}
+		return train, test
+	}
+
+	sorted := append([]Join(nil), joins...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].JoinedAt.Before(sorted[j].JoinedAt) })
+
+	latest := make(map[string]int)
+	for i, j := range sorted {
+		latest[j.UserID] = i
+	}
+
+	for i, j := range sorted {
+		if latest[j.UserID] == i {
+			test = append(test, j)
+		} else {
+			train = append(train, j)
+		}
+	}
+	return train, test
+}
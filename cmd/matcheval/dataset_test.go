@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bmatch/internal/service/group"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2025, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func TestLoadDataset_JSON(t *testing.T) {
+	ds, err := loadDataset("testdata/sample.json")
+	require.NoError(t, err)
+
+	assert.Len(t, ds.Users, 8)
+	assert.Len(t, ds.Groups, 8)
+	assert.Len(t, ds.Joins, 16)
+	assert.Equal(t, "backend", ds.Taxonomy["go"])
+
+	assert.Equal(t, []string{"go", "kubernetes"}, ds.Users[0].Tags)
+	assert.Equal(t, "g1", ds.Groups[0].ID)
+	assert.Equal(t, 5, ds.Groups[0].Capacity)
+	assert.Equal(t, Join{UserID: "u1", GroupID: "g7", JoinedAt: date(time.February, 11)}, ds.Joins[0])
+}
+
+func TestLoadDataset_CSV(t *testing.T) {
+	ds, err := loadDataset("testdata/csv")
+	require.NoError(t, err)
+
+	assert.Equal(t, []*group.UserProfile{
+		{UserID: "u1", Tags: []string{"go", "kubernetes"}, SkillLevel: "INTERMEDIATE", Availability: []string{"WEEKENDS"}, Intent: "SERIOUS"},
+		{UserID: "u2", Tags: []string{"react"}, SkillLevel: "BEGINNER", Availability: []string{}, Intent: "CASUAL"},
+	}, ds.Users)
+
+	created := time.Date(2025, time.January, 5, 10, 0, 0, 0, time.UTC)
+	require.Len(t, ds.Groups, 2)
+	assert.Equal(t, &group.Group{
+		ID:        "g1",
+		OwnerID:   "u2",
+		Title:     "Go, the hard way",
+		Tags:      []string{"go", "kubernetes"},
+		Capacity:  5,
+		JoinType:  "OPEN",
+		Status:    group.StatusOpen,
+		CreatedAt: created,
+		UpdatedAt: created,
+	}, ds.Groups[0])
+
+	assert.Equal(t, []Join{
+		{UserID: "u1", GroupID: "g1", JoinedAt: date(time.February, 11)},
+		{UserID: "u2", GroupID: "g2", JoinedAt: date(time.February, 12)},
+		{UserID: "u2", GroupID: "g1", JoinedAt: date(time.February, 20)},
+	}, ds.Joins)
+	assert.Equal(t, map[string]string{"go": "backend", "react": "frontend"}, ds.Taxonomy)
+
+	t.Run("taxonomy is optional", func(t *testing.T) {
+		dir := copyFixture(t, "users.csv", "groups.csv", "joins.csv")
+
+		ds, err := loadDataset(dir)
+		require.NoError(t, err)
+		assert.Empty(t, ds.Taxonomy)
+		assert.Len(t, ds.Joins, 3)
+	})
+
+	t.Run("errors name the file and line", func(t *testing.T) {
+		dir := copyFixture(t, "users.csv", "joins.csv")
+		writeFile(t, dir, "groups.csv", "id,owner_id,title,tags,capacity,join_type,created_at\n"+
+			"g1,u2,Go,go,five,OPEN,2025-01-05T10:00:00Z\n")
+
+		_, err := loadDataset(dir)
+		assert.ErrorContains(t, err, "groups.csv line 2: capacity")
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		_, err := loadDataset(copyFixture(t, "users.csv", "groups.csv"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+// copyFixture copies files of testdata/csv into a temporary directory
+func copyFixture(t *testing.T, files ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join("testdata", "csv", name))
+		require.NoError(t, err)
+		writeFile(t, dir, name, string(data))
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func joinKeys(joins []Join) []string {
+	keys := make([]string, len(joins))
+	for i, j := range joins {
+		keys[i] = j.UserID + ":" + j.GroupID
+	}
+	return keys
+}
+
+func TestSplit(t *testing.T) {
+	joins := []Join{
+		{UserID: "u1", GroupID: "g2", JoinedAt: date(time.February, 20)},
+		{UserID: "u1", GroupID: "g1", JoinedAt: date(time.February, 11)},
+		{UserID: "u2", GroupID: "g1", JoinedAt: date(time.February, 12)},
+		{UserID: "u1", GroupID: "g3", JoinedAt: date(time.February, 15)},
+		{UserID: "u3", GroupID: "g3", JoinedAt: date(time.February, 17)},
+	}
+
+	cases := []struct {
+		name   string
+		cutoff time.Time
+		train  []string
+		test   []string
+	}{
+		{
+			name:  "latest join per user is held out",
+			train: []string{"u1:g1", "u1:g3"},
+			test:  []string{"u2:g1", "u3:g3", "u1:g2"},
+		},
+		{
+			name:   "joins at or after the cutoff are held out",
+			cutoff: date(time.February, 15),
+			train:  []string{"u1:g1", "u2:g1"},
+			test:   []string{"u1:g2", "u1:g3", "u3:g3"},
+		},
+		{
+			name:   "cutoff before every join",
+			cutoff: date(time.January, 1),
+			train:  []string{},
+			test:   []string{"u1:g2", "u1:g1", "u2:g1", "u1:g3", "u3:g3"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			train, test := split(joins, tc.cutoff)
+			assert.Equal(t, tc.train, joinKeys(train))
+			assert.Equal(t, tc.test, joinKeys(test))
+		})
+	}
+
+	t.Run("sample dataset", func(t *testing.T) {
+		ds, err := loadDataset("testdata/sample.json")
+		require.NoError(t, err)
+
+		train, test := split(ds.Joins, time.Time{})
+		assert.Len(t, train, 8)
+		assert.Len(t, test, 8)
+
+		// Every user keeps a history and has exactly one later join to predict
+		held := make(map[string]Join)
+		for _, j := range test {
+			assert.NotContains(t, held, j.UserID)
+			held[j.UserID] = j
+		}
+		for _, j := range train {
+			assert.True(t, j.JoinedAt.Before(held[j.UserID].JoinedAt), "%s:%s joined after the held-out join", j.UserID, j.GroupID)
+		}
+	})
+}
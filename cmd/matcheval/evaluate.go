@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"bmatch/internal/service/group"
+	"bmatch/pkg/rankeval"
+	"bmatch/pkg/taxonomy"
+)
+
+// strategies maps names accepted by -strategy and -baseline to matchers
+var strategies = map[string]func(repo group.Repository, tax *taxonomy.Taxonomy) group.GroupMatcher{
+	// Tag similarity with taxonomy partial credit, as served by discover
+	"content": func(repo group.Repository, tax *taxonomy.Taxonomy) group.GroupMatcher {
+		return group.NewPostgresMatcher(repo, staticTaxonomy{tax})
+	},
+	// Plain Jaccard similarity, ignoring the taxonomy
+	"jaccard": func(repo group.Repository, _ *taxonomy.Taxonomy) group.GroupMatcher {
+		return group.NewPostgresMatcher(repo, staticTaxonomy{taxonomy.New(nil)})
+	},
+	// Item-based collaborative filtering from history joins
+	"collaborative": func(repo group.Repository, tax *taxonomy.Taxonomy) group.GroupMatcher {
+		return group.NewCollaborativeMatcher(repo, staticTaxonomy{tax})
+	},
+	// Content and collaborative filtering blended as in discover's defaults
+	"blended": func(repo group.Repository, tax *taxonomy.Taxonomy) group.GroupMatcher {
+		return group.NewBlendedMatcher(
+			group.WeightedMatcher{Matcher: group.NewPostgresMatcher(repo, staticTaxonomy{tax}), Weight: 0.7},
+			group.WeightedMatcher{Matcher: group.NewCollaborativeMatcher(repo, staticTaxonomy{tax}), Weight: 0.3},
+		)
+	},
+	// Content with diversity re-ranking
+	"diverse": func(repo group.Repository, tax *taxonomy.Taxonomy) group.GroupMatcher {
+		return group.NewRankedMatcher(
+			group.NewPostgresMatcher(repo, staticTaxonomy{tax}),
+			group.MMRReranker{Lambda: 0.7},
+		)
+	},
+}
+
+func strategyNames() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Report holds metrics averaged over every user with at least one held-out join
+type Report struct {
+	Strategy  string  `json:"strategy"`
+	Users     int     `json:"users"`
+	Precision float64 `json:"precision_at_k"`
+	Recall    float64 `json:"recall_at_k"`
+	NDCG      float64 `json:"ndcg_at_k"`
+	Coverage  float64 `json:"coverage"`
+}
+
+// evaluate asks matcher for groups for every user in test and scores the
+// top k against the groups they actually joined
+func evaluate(ctx context.Context, name string, matcher group.GroupMatcher, ds *Dataset, test []Join, k, pool int) (*Report, error) {
+	relevant := make(map[string]map[string]bool)
+	for _, j := range test {
+		if relevant[j.UserID] == nil {
+			relevant[j.UserID] = make(map[string]bool)
+		}
+		relevant[j.UserID][j.GroupID] = true
+	}
+
+	report := &Report{Strategy: name}
+	rankings := make([][]string, 0, len(relevant))
+
+	for _, user := range ds.Users {
+		want := relevant[user.UserID]
+		if len(want) == 0 {
+			continue
+		}
+
+		matches, err := matcher.FindMatches(ctx, *user, group.DiscoverGroupsRequest{Limit: pool})
+		if err != nil {
+			return nil, fmt.Errorf("%s: user %s: %w", name, user.UserID, err)
+		}
+
+		ranked := make([]string, len(matches))
+		for i, m := range matches {
+			ranked[i] = m.Group.ID
+		}
+		rankings = append(rankings, ranked)
+
+		report.Users++
+		report.Precision += rankeval.PrecisionAtK(ranked, want, k)
+		report.Recall += rankeval.RecallAtK(ranked, want, k)
+		report.NDCG += rankeval.NDCGAtK(ranked, want, k)
+	}
+
+	if report.Users > 0 {
+		n := float64(report.Users)
+		report.Precision /= n
+		report.Recall /= n
+		report.NDCG /= n
+	}
+	report.Coverage = rankeval.Coverage(rankings, len(ds.Groups), k)
+
+	return report, nil
+}
@@ -0,0 +1,124 @@
+// Command matcheval replays a dataset of users, groups and historical joins
+// against GroupMatcher strategies, in memory, and reports ranking metrics.
+//
+//	go run ./cmd/matcheval -data cmd/matcheval/testdata/sample.json -strategy content -baseline jaccard
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bmatch/pkg/taxonomy"
+)
+
+func main() {
+	dataPath := flag.String("data", "", "dataset: JSON file or directory of CSV files")
+	strategy := flag.String("strategy", "content", "strategy to evaluate: "+strings.Join(strategyNames(), ", "))
+	baseline := flag.String("baseline", "", "optional strategy to compare against, side by side")
+	k := flag.Int("k", 10, "cutoff for precision, recall, nDCG and coverage")
+	pool := flag.Int("pool", 100, "groups requested from each matcher per user")
+	splitAt := flag.String("split-at", "", "RFC 3339 time; joins from then on are held out (default: each user's latest join)")
+	asJSON := flag.Bool("json", false, "print reports as JSON")
+	flag.Parse()
+
+	if *dataPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var cutoff time.Time
+	if *splitAt != "" {
+		var err error
+		if cutoff, err = time.Parse(time.RFC3339, *splitAt); err != nil {
+			log.Fatalf("invalid -split-at: %v", err)
+		}
+	}
+
+	ds, err := loadDataset(*dataPath)
+	if err != nil {
+		log.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	history, test := split(ds.Joins, cutoff)
+	repo := newMemoryRepository(ds, history, *pool)
+	tax := taxonomy.New(ds.Taxonomy)
+
+	names := []string{*strategy}
+	if *baseline != "" {
+		names = []string{*baseline, *strategy}
+	}
+
+	ctx := context.Background()
+	reports := make([]*Report, 0, len(names))
+	for _, name := range names {
+		build, ok := strategies[name]
+		if !ok {
+			log.Fatalf("unknown strategy %q (available: %s)", name, strings.Join(strategyNames(), ", "))
+		}
+
+		report, err := evaluate(ctx, name, build(repo, tax), ds, test, *k, *pool)
+		if err != nil {
+			log.Fatalf("Evaluation failed: %v", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			log.Fatalf("Failed to encode reports: %v", err)
+		}
+		return
+	}
+
+	printReports(reports, *k, len(history), len(test))
+}
+
+// printReports prints one column per strategy, plus the difference between
+// the strategy and the baseline when there are two
+func printReports(reports []*Report, k, historyJoins, testJoins int) {
+	fmt.Printf("history joins: %d, held-out joins: %d, users evaluated: %d\n\n",
+		historyJoins, testJoins, reports[0].Users)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	header := "metric"
+	for _, r := range reports {
+		header += "\t" + r.Strategy
+	}
+	if len(reports) == 2 {
+		header += "\tdelta"
+	}
+	fmt.Fprintln(w, header)
+
+	rows := []struct {
+		name  string
+		value func(*Report) float64
+	}{
+		{fmt.Sprintf("precision@%d", k), func(r *Report) float64 { return r.Precision }},
+		{fmt.Sprintf("recall@%d", k), func(r *Report) float64 { return r.Recall }},
+		{fmt.Sprintf("ndcg@%d", k), func(r *Report) float64 { return r.NDCG }},
+		{fmt.Sprintf("coverage@%d", k), func(r *Report) float64 { return r.Coverage }},
+	}
+
+	for _, row := range rows {
+		line := row.name
+		for _, r := range reports {
+			line += fmt.Sprintf("\t%.4f", row.value(r))
+		}
+		if len(reports) == 2 {
+			line += fmt.Sprintf("\t%+.4f", row.value(reports[1])-row.value(reports[0]))
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	w.Flush()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	"bmatch/internal/service/group"
+	"bmatch/pkg/taxonomy"
+)
+
+// memoryRepository serves the discovery reads of group.Repository from a
+// dataset. Every group counts as open with room, since the dataset records
+// its final state rather than the state at the time of each join. Other
+// repository methods are not needed by matchers and panic if called.
+type memoryRepository struct {
+	group.Repository
+
+	groups          []*group.Group
+	joined          map[string]map[string]bool // user -> groups joined in history
+	recommendations map[string][]group.GroupRecommendation
+}
+
+func newMemoryRepository(ds *Dataset, history []Join, perUser int) *memoryRepository {
+	groups := make([]*group.Group, 0, len(ds.Groups))
+	memberships := make([]*group.GroupMember, 0, len(ds.Groups)+len(history))
+	joined := make(map[string]map[string]bool)
+
+	addMember := func(userID, groupID string) {
+		if joined[userID] == nil {
+			joined[userID] = make(map[string]bool)
+		}
+		joined[userID][groupID] = true
+		memberships = append(memberships, &group.GroupMember{GroupID: groupID, UserID: userID})
+	}
+
+	for _, g := range ds.Groups {
+		open := *g
+		open.Status = group.StatusOpen
+		open.CurrentCount = 0
+		open.Capacity = max(open.Capacity, 1)
+		groups = append(groups, &open)
+		addMember(g.OwnerID, g.ID)
+	}
+	for _, j := range history {
+		addMember(j.UserID, j.GroupID)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].CreatedAt.After(groups[j].CreatedAt) })
+
+	recommendations := make(map[string][]group.GroupRecommendation)
+	for _, rec := range group.BuildRecommendations(groups, memberships, perUser) {
+		recommendations[rec.UserID] = append(recommendations[rec.UserID], rec)
+	}
+
+	return &memoryRepository{
+		groups:          groups,
+		joined:          joined,
+		recommendations: recommendations,
+	}
+}
+
+// FindGroupsByTags mirrors the SQL query: groups sharing a tag, newest first,
+// excluding groups the user owns or joined. Text search is not supported.
+func (r *memoryRepository) FindGroupsByTags(ctx context.Context, userID string, tags []string, filters group.DiscoverGroupsRequest) ([]*group.GroupCandidate, error) {
+	wanted := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		wanted[t] = true
+	}
+
+	candidates := make([]*group.GroupCandidate, 0)
+	for _, g := range r.groups {
+		if len(candidates) == limitOf(filters) {
+			break
+		}
+		if g.OwnerID == userID || r.joined[userID][g.ID] {
+			continue
+		}
+		if filters.JoinType != "" && g.JoinType != filters.JoinType {
+			continue
+		}
+		if len(tags) > 0 && !sharesTag(g.Tags, wanted) {
+			continue
+		}
+		candidates = append(candidates, &group.GroupCandidate{Group: g})
+	}
+
+	return candidates, nil
+}
+
+// GetRecommendedGroups returns collaborative-filtering recommendations built
+// from the history joins, keeping groups that share one of tags
+func (r *memoryRepository) GetRecommendedGroups(ctx context.Context, userID string, tags []string, filters group.DiscoverGroupsRequest) ([]group.GroupMatch, error) {
+	byID := make(map[string]*group.Group, len(r.groups))
+	for _, g := range r.groups {
+		byID[g.ID] = g
+	}
+	wanted := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		wanted[t] = true
+	}
+
+	matches := make([]group.GroupMatch, 0)
+	for _, rec := range r.recommendations[userID] {
+		if len(matches) == limitOf(filters) {
+			break
+		}
+		g := byID[rec.GroupID]
+		if g.OwnerID == userID || (filters.JoinType != "" && g.JoinType != filters.JoinType) {
+			continue
+		}
+		if len(tags) > 0 && !sharesTag(g.Tags, wanted) {
+			continue
+		}
+		matches = append(matches, group.GroupMatch{Group: g, SimilarityScore: rec.Score})
+	}
+
+	return matches, nil
+}
+
+func limitOf(filters group.DiscoverGroupsRequest) int {
+	if filters.Limit == 0 {
+		return 50
+	}
+	return filters.Limit
+}
+
+func sharesTag(tags []string, wanted map[string]bool) bool {
+	for _, t := range tags {
+		if wanted[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// staticTaxonomy implements group.TaxonomyProvider with a fixed hierarchy
+type staticTaxonomy struct {
+	tax *taxonomy.Taxonomy
+}
+
+func (s staticTaxonomy) Taxonomy(ctx context.Context) (*taxonomy.Taxonomy, error) {
+	return s.tax, nil
+}
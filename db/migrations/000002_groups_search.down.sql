@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_groups_search_vector_gin;
+
+ALTER TABLE groups DROP COLUMN IF EXISTS search_vector;
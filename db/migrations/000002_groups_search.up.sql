@@ -0,0 +1,10 @@
+-- Weighted full-text document: title ranks above proposal, proposal above description
+ALTER TABLE groups
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(proposal, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_groups_search_vector_gin ON groups USING GIN (search_vector);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tag_aliases;
+DROP TABLE IF EXISTS tags;
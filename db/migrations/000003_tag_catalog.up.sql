@@ -0,0 +1,66 @@
+-- Canonical tag catalog
+CREATE TABLE IF NOT EXISTS tags (
+    name VARCHAR(64) PRIMARY KEY, -- normalized: lower case, trimmed, single spaced
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Alternative spellings (ALIAS) and equivalent terms (SYNONYM) resolved to a canonical tag
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    alias VARCHAR(64) PRIMARY KEY,
+    tag_name VARCHAR(64) NOT NULL REFERENCES tags(name) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL DEFAULT 'ALIAS', -- ALIAS, SYNONYM
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_alias_differs CHECK (alias <> tag_name)
+);
+
+CREATE INDEX idx_tag_aliases_tag_name ON tag_aliases(tag_name);
+
+-- Seed common aliases
+INSERT INTO tags (name) VALUES
+    ('go'), ('javascript'), ('typescript'), ('python'), ('kubernetes'),
+    ('postgresql'), ('machine learning'), ('frontend'), ('backend')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO tag_aliases (alias, tag_name, kind) VALUES
+    ('golang', 'go', 'ALIAS'),
+    ('js', 'javascript', 'ALIAS'),
+    ('ts', 'typescript', 'ALIAS'),
+    ('py', 'python', 'ALIAS'),
+    ('k8s', 'kubernetes', 'ALIAS'),
+    ('postgres', 'postgresql', 'ALIAS'),
+    ('ml', 'machine learning', 'ALIAS'),
+    ('front-end', 'frontend', 'SYNONYM'),
+    ('back-end', 'backend', 'SYNONYM')
+ON CONFLICT DO NOTHING;
+
+-- Rewrite existing tags: fold case, trim, collapse whitespace, resolve aliases, drop duplicates
+UPDATE users u SET tags = (
+    SELECT COALESCE(jsonb_agg(DISTINCT COALESCE(a.tag_name, n.name)), '[]'::jsonb)
+    FROM (
+        SELECT lower(btrim(regexp_replace(value, '\s+', ' ', 'g'))) AS name
+        FROM jsonb_array_elements_text(u.tags)
+    ) n
+    LEFT JOIN tag_aliases a ON a.alias = n.name
+    WHERE n.name <> ''
+);
+
+UPDATE groups g SET tags = (
+    SELECT COALESCE(jsonb_agg(DISTINCT COALESCE(a.tag_name, n.name)), '[]'::jsonb)
+    FROM (
+        SELECT lower(btrim(regexp_replace(value, '\s+', ' ', 'g'))) AS name
+        FROM jsonb_array_elements_text(g.tags)
+    ) n
+    LEFT JOIN tag_aliases a ON a.alias = n.name
+    WHERE n.name <> ''
+);
+
+-- Register every tag already in use as canonical
+INSERT INTO tags (name)
+SELECT DISTINCT value FROM (
+    SELECT jsonb_array_elements_text(tags) AS value FROM users
+    UNION
+    SELECT jsonb_array_elements_text(tags) AS value FROM groups
+) used
+WHERE length(value) <= 64
+ON CONFLICT DO NOTHING;
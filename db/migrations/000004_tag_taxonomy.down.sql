@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tags_parent_name;
+
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS chk_tag_parent_differs;
+ALTER TABLE tags DROP COLUMN IF EXISTS parent_name;
@@ -0,0 +1,22 @@
+-- Parent/child relationships between canonical tags, e.g. frontend -> react
+ALTER TABLE tags
+    ADD COLUMN IF NOT EXISTS parent_name VARCHAR(64) REFERENCES tags(name) ON DELETE SET NULL;
+
+ALTER TABLE tags
+    ADD CONSTRAINT chk_tag_parent_differs CHECK (parent_name IS NULL OR parent_name <> name);
+
+CREATE INDEX idx_tags_parent_name ON tags(parent_name);
+
+-- Seed a starter taxonomy
+INSERT INTO tags (name) VALUES
+    ('react'), ('vue'), ('angular'), ('svelte'),
+    ('rust'), ('java'), ('node'),
+    ('devops'), ('docker'),
+    ('data'), ('deep learning')
+ON CONFLICT DO NOTHING;
+
+UPDATE tags SET parent_name = 'frontend' WHERE name IN ('react', 'vue', 'angular', 'svelte', 'javascript', 'typescript');
+UPDATE tags SET parent_name = 'backend' WHERE name IN ('go', 'rust', 'java', 'node', 'python', 'postgresql');
+UPDATE tags SET parent_name = 'devops' WHERE name IN ('kubernetes', 'docker');
+UPDATE tags SET parent_name = 'data' WHERE name IN ('machine learning');
+UPDATE tags SET parent_name = 'machine learning' WHERE name IN ('deep learning');
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_groups_created_at;
+DROP INDEX IF EXISTS idx_users_updated_at;
+
+DROP TRIGGER IF EXISTS trg_groups_register_tags ON groups;
+DROP TRIGGER IF EXISTS trg_users_register_tags ON users;
+DROP FUNCTION IF EXISTS register_used_tags();
+
+DROP TABLE IF EXISTS tag_candidates;
+
+DROP INDEX IF EXISTS idx_tag_aliases_alias_trgm;
+DROP INDEX IF EXISTS idx_tags_name_trgm;
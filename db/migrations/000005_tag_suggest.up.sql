@@ -0,0 +1,53 @@
+-- Trigram indexes for fuzzy tag autocomplete
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_tags_name_trgm ON tags USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_alias_trgm ON tag_aliases USING GIN (alias gin_trgm_ops);
+
+-- Tags users and groups use that are neither canonical nor aliases. They are
+-- suggested below canonical tags and stay out of the catalog until an admin
+-- creates them as tags or aliases them to one.
+CREATE TABLE IF NOT EXISTS tag_candidates (
+    name VARCHAR(64) PRIMARY KEY,
+    use_count INTEGER NOT NULL DEFAULT 1,
+    first_used_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tag_candidates_name_trgm ON tag_candidates USING GIN (name gin_trgm_ops);
+
+-- Count new uses of unknown tags as soon as a user or group adds them
+CREATE OR REPLACE FUNCTION register_used_tags() RETURNS trigger AS $$
+DECLARE
+    old_tags JSONB := '[]'::jsonb;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        old_tags := OLD.tags;
+    END IF;
+
+    INSERT INTO tag_candidates (name)
+    SELECT DISTINCT value
+    FROM jsonb_array_elements_text(NEW.tags) AS value
+    WHERE value <> ''
+      AND length(value) <= 64
+      AND NOT old_tags ? value
+      AND NOT EXISTS (SELECT 1 FROM tags t WHERE t.name = value)
+      AND NOT EXISTS (SELECT 1 FROM tag_aliases a WHERE a.alias = value)
+    ON CONFLICT (name) DO UPDATE
+        SET use_count = tag_candidates.use_count + 1,
+            last_used_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_users_register_tags
+    AFTER INSERT OR UPDATE OF tags ON users
+    FOR EACH ROW EXECUTE FUNCTION register_used_tags();
+
+CREATE TRIGGER trg_groups_register_tags
+    AFTER INSERT OR UPDATE OF tags ON groups
+    FOR EACH ROW EXECUTE FUNCTION register_used_tags();
+
+-- Popular tags are aggregated over recently active profiles and recent groups
+CREATE INDEX IF NOT EXISTS idx_users_updated_at ON users(updated_at);
+CREATE INDEX IF NOT EXISTS idx_groups_created_at ON groups(created_at);
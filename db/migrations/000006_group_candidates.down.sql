@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS group_invites;
+DROP TABLE IF EXISTS user_blocks;
+
+DROP INDEX IF EXISTS idx_users_open_to_invites;
+ALTER TABLE users DROP COLUMN IF EXISTS open_to_invites;
@@ -0,0 +1,30 @@
+-- Users opt in to being recommended to group owners
+ALTER TABLE users ADD COLUMN IF NOT EXISTS open_to_invites BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_users_open_to_invites ON users(open_to_invites) WHERE open_to_invites;
+
+-- A blocked pair is never matched in either direction
+CREATE TABLE IF NOT EXISTS user_blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT chk_block_self CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX idx_user_blocks_blocked_id ON user_blocks(blocked_id);
+
+-- Owner invitations to candidate members
+CREATE TABLE IF NOT EXISTS group_invites (
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invited_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL DEFAULT 'PENDING', -- PENDING, ACCEPTED, DECLINED
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMP,
+
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX idx_group_invites_user_id_status ON group_invites(user_id, status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_auto_match;
+ALTER TABLE users DROP COLUMN IF EXISTS auto_match;
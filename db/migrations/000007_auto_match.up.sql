@@ -0,0 +1,4 @@
+-- Users opt in to being placed into auto-formed groups
+ALTER TABLE users ADD COLUMN IF NOT EXISTS auto_match BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_users_auto_match ON users(auto_match) WHERE auto_match;
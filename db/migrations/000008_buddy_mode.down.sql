@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS buddy_matches;
+DROP TABLE IF EXISTS buddy_reactions;
+DROP INDEX IF EXISTS idx_users_buddy_mode;
+ALTER TABLE users DROP COLUMN IF EXISTS buddy_mode;
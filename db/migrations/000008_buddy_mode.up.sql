@@ -0,0 +1,30 @@
+-- Users opt in to one-to-one buddy suggestions
+ALTER TABLE users ADD COLUMN IF NOT EXISTS buddy_mode BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_users_buddy_mode ON users(buddy_mode) WHERE buddy_mode;
+
+-- Interest or pass from one user on a suggested buddy; the latest reaction wins
+CREATE TABLE IF NOT EXISTS buddy_reactions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reaction VARCHAR(50) NOT NULL, -- INTEREST, PASS
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, target_id),
+    CONSTRAINT chk_buddy_reaction_self CHECK (user_id <> target_id)
+);
+
+CREATE INDEX idx_buddy_reactions_target_id ON buddy_reactions(target_id);
+
+-- Mutual interest creates a pair group; user_a < user_b keeps each pair unique
+CREATE TABLE IF NOT EXISTS buddy_matches (
+    group_id UUID PRIMARY KEY REFERENCES groups(id) ON DELETE CASCADE,
+    user_a UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_b UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_buddy_matches_pair UNIQUE (user_a, user_b),
+    CONSTRAINT chk_buddy_matches_order CHECK (user_a < user_b)
+);
+
+CREATE INDEX idx_buddy_matches_user_b ON buddy_matches(user_b);
@@ -0,0 +1,11 @@
+-- Collaborative-filtering recommendations, rebuilt periodically from membership history
+CREATE TABLE IF NOT EXISTS group_recommendations (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, group_id)
+);
+
+CREATE INDEX idx_group_recommendations_user_score ON group_recommendations(user_id, score DESC);
@@ -0,0 +1,13 @@
+-- Exposure and outcome events of A/B experiments
+CREATE TABLE IF NOT EXISTS experiment_events (
+    id BIGSERIAL PRIMARY KEY,
+    experiment_key VARCHAR(100) NOT NULL,
+    arm VARCHAR(100) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    group_id UUID REFERENCES groups(id) ON DELETE SET NULL,
+    event_type VARCHAR(50) NOT NULL, -- IMPRESSION, VIEW, APPLY, JOIN
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- user_id last so reports can find a user's impression in an arm
+CREATE INDEX idx_experiment_events_key_arm_type ON experiment_events(experiment_key, arm, event_type, user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS schedule;
+ALTER TABLE users DROP COLUMN IF EXISTS schedule;
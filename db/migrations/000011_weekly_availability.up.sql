@@ -0,0 +1,27 @@
+-- Structured weekly availability: {"time_zone": "Europe/Berlin", "slots": [{"day": "MON", "start": "18:00", "end": "21:00"}]}
+-- Times are local to time_zone; an end at or before the start runs past midnight
+ALTER TABLE users ADD COLUMN IF NOT EXISTS schedule JSONB NOT NULL DEFAULT '{"time_zone": "UTC", "slots": []}'::jsonb;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS schedule JSONB NOT NULL DEFAULT '{"time_zone": "UTC", "slots": []}'::jsonb;
+
+-- Backfill users from the coarse availability strings, using the same
+-- mapping as availability.FromLegacy. The real time zone is unknown, so
+-- UTC is assumed until the user updates their profile.
+WITH legacy (value, days, start_time, end_time) AS (
+    VALUES
+        ('WEEKDAYS', ARRAY['MON', 'TUE', 'WED', 'THU', 'FRI'], '09:00', '17:00'),
+        ('WEEKENDS', ARRAY['SAT', 'SUN'], '09:00', '21:00'),
+        ('EVENINGS', ARRAY['MON', 'TUE', 'WED', 'THU', 'FRI', 'SAT', 'SUN'], '18:00', '22:00'),
+        ('MORNINGS', ARRAY['MON', 'TUE', 'WED', 'THU', 'FRI', 'SAT', 'SUN'], '07:00', '10:00')
+),
+slots AS (
+    SELECT u.id, jsonb_agg(jsonb_build_object('day', d.day, 'start', l.start_time, 'end', l.end_time)) AS slots
+    FROM users u
+    CROSS JOIN LATERAL jsonb_array_elements_text(u.availability) AS a(value)
+    INNER JOIN legacy l ON l.value = upper(trim(a.value))
+    CROSS JOIN LATERAL unnest(l.days) AS d(day)
+    GROUP BY u.id
+)
+UPDATE users u
+SET schedule = jsonb_build_object('time_zone', 'UTC', 'slots', s.slots)
+FROM slots s
+WHERE u.id = s.id;
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_groups_meeting_mode;
+
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS chk_groups_meeting_mode;
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS chk_groups_location;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_location;
+
+ALTER TABLE groups DROP COLUMN IF EXISTS time_zone;
+ALTER TABLE groups DROP COLUMN IF EXISTS city;
+ALTER TABLE groups DROP COLUMN IF EXISTS longitude;
+ALTER TABLE groups DROP COLUMN IF EXISTS latitude;
+ALTER TABLE groups DROP COLUMN IF EXISTS meeting_mode;
+
+ALTER TABLE users DROP COLUMN IF EXISTS time_zone;
+ALTER TABLE users DROP COLUMN IF EXISTS city;
+ALTER TABLE users DROP COLUMN IF EXISTS longitude;
+ALTER TABLE users DROP COLUMN IF EXISTS latitude;
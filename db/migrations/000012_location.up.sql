@@ -0,0 +1,26 @@
+-- Optional location and IANA time zone on users and groups
+ALTER TABLE users ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS meeting_mode VARCHAR(50) NOT NULL DEFAULT 'ONLINE'; -- ONLINE, IN_PERSON, HYBRID
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE users ADD CONSTRAINT chk_users_location
+    CHECK ((latitude IS NULL) = (longitude IS NULL));
+ALTER TABLE groups ADD CONSTRAINT chk_groups_location
+    CHECK ((latitude IS NULL) = (longitude IS NULL));
+ALTER TABLE groups ADD CONSTRAINT chk_groups_meeting_mode
+    CHECK (meeting_mode IN ('ONLINE', 'IN_PERSON', 'HYBRID'));
+
+-- Users who already set a weekly schedule keep its time zone
+UPDATE users
+SET time_zone = schedule->>'time_zone'
+WHERE jsonb_array_length(schedule->'slots') > 0
+  AND schedule->>'time_zone' <> 'UTC';
+
+CREATE INDEX IF NOT EXISTS idx_groups_meeting_mode ON groups(meeting_mode);
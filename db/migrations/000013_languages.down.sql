@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_groups_language;
+
+ALTER TABLE groups DROP CONSTRAINT IF EXISTS chk_groups_required_proficiency;
+ALTER TABLE groups DROP COLUMN IF EXISTS required_proficiency;
+ALTER TABLE groups DROP COLUMN IF EXISTS language;
+
+ALTER TABLE users DROP COLUMN IF EXISTS languages;
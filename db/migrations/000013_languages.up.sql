@@ -0,0 +1,12 @@
+-- Spoken languages: [{"code": "en", "proficiency": "NATIVE"}]
+ALTER TABLE users ADD COLUMN IF NOT EXISTS languages JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+-- Primary language of a group (ISO 639 code, empty when unspecified) and the
+-- proficiency required of new members, empty when not screened
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS language VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS required_proficiency VARCHAR(50) NOT NULL DEFAULT ''; -- BASIC, CONVERSATIONAL, FLUENT, NATIVE
+
+ALTER TABLE groups ADD CONSTRAINT chk_groups_required_proficiency
+    CHECK (required_proficiency = '' OR language <> '');
+
+CREATE INDEX IF NOT EXISTS idx_groups_language ON groups(language);
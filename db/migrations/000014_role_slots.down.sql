@@ -0,0 +1,3 @@
+ALTER TABLE group_members DROP COLUMN IF EXISTS slot;
+
+ALTER TABLE groups DROP COLUMN IF EXISTS role_slots;
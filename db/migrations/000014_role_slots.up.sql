@@ -0,0 +1,5 @@
+-- Seats reserved for roles: [{"name": "backend", "tags": ["golang"], "count": 2, "filled": 0}]
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS role_slots JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+-- Role slot a member joined through, empty for unreserved seats
+ALTER TABLE group_members ADD COLUMN IF NOT EXISTS slot VARCHAR(100) NOT NULL DEFAULT '';
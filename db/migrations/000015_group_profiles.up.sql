@@ -0,0 +1,10 @@
+-- Composite matching profile of a group, recomputed from its members on
+-- membership changes
+CREATE TABLE IF NOT EXISTS group_profiles (
+    group_id UUID PRIMARY KEY REFERENCES groups(id) ON DELETE CASCADE,
+    member_count INTEGER NOT NULL DEFAULT 0,
+    tag_counts JSONB NOT NULL DEFAULT '{}'::jsonb,      -- {"golang": 3}
+    skill_levels JSONB NOT NULL DEFAULT '{}'::jsonb,    -- {"BEGINNER": 1, "ADVANCED": 2}
+    member_schedules JSONB NOT NULL DEFAULT '[]'::jsonb, -- members' weekly schedules, each in its own time zone
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,17 @@
+-- Discover searches users want alerts for when a new group matches
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}'::jsonb, -- DiscoverGroupsRequest
+    min_score DOUBLE PRECISION NOT NULL DEFAULT 0.3,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    last_matched_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_saved_searches_user ON saved_searches(user_id);
+
+-- Active searches are found by tag overlap with a new group
+CREATE INDEX idx_saved_searches_tags_gin ON saved_searches USING GIN ((filters->'tags')) WHERE NOT paused;
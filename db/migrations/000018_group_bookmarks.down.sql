@@ -0,0 +1,3 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS bookmark_count;
+
+DROP TABLE IF EXISTS group_bookmarks;
@@ -0,0 +1,11 @@
+-- Groups users shortlisted; bookmark_count feeds the bookmark ranking boost
+CREATE TABLE IF NOT EXISTS group_bookmarks (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    group_id UUID NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, group_id)
+);
+
+CREATE INDEX idx_group_bookmarks_group ON group_bookmarks(group_id);
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS bookmark_count INTEGER NOT NULL DEFAULT 0;
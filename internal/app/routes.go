@@ -2,7 +2,11 @@ package app
 
 import (
 	"bmatch/internal/service/auth"
+	"bmatch/internal/service/experiment"
+	"bmatch/internal/service/feed"
 	"bmatch/internal/service/group"
+	"bmatch/internal/service/notification"
+	"bmatch/internal/service/tag"
 	"bmatch/internal/service/user"
 	"bmatch/pkg/oauth2"
 
@@ -53,6 +57,8 @@ func (o *Routes) setupUserRoutes(auth *auth.Handler, uv *user.Service) {
 	{
 		authorized.GET("/profile", userHandler.GetProfile)
 		authorized.PUT("/profile", userHandler.UpdateProfile)
+		authorized.POST("/blocks/:user_id", userHandler.BlockUser)
+		authorized.DELETE("/blocks/:user_id", userHandler.UnblockUser)
 	}
 }
 
@@ -60,19 +66,119 @@ func (o *Routes) setupUserRoutes(auth *auth.Handler, uv *user.Service) {
 func (o *Routes) setupGroupRoutes(auth *auth.Handler, gv *group.Service) {
 	groupHandler := group.NewHandler(gv)
 
-	o.r.GET("/groups/discover", groupHandler.DiscoverGroups)
-	o.r.GET("/groups/:id", groupHandler.GetGroup)
+	o.r.GET("/groups/discover", auth.OptionalAuthMiddleware(), groupHandler.DiscoverGroups)
+	o.r.GET("/groups/:id", auth.OptionalAuthMiddleware(), groupHandler.GetGroup)
 
 	authorized := o.r.Group("/", auth.AuthMiddleware())
 	{
 		authorized.POST("/groups", groupHandler.CreateGroup)
 		authorized.POST("/groups/:id/join", groupHandler.JoinGroup)
 		authorized.POST("/groups/:id/apply", groupHandler.ApplyToGroup)
+		authorized.POST("/groups/:id/leave", groupHandler.LeaveGroup)
+
+		// Bookmarks shortlist groups; bookmarkers hear when a group almost fills up or reopens
+		authorized.POST("/groups/:id/bookmark", groupHandler.BookmarkGroup)
+		authorized.DELETE("/groups/:id/bookmark", groupHandler.UnbookmarkGroup)
 
 		// Application management (owner only, validated in handler)
 		authorized.POST("/groups/:id/applications/:user_id/approve", groupHandler.ApproveApplication)
 
+		// Candidate recommendations and invites (owner only, validated in service)
+		authorized.GET("/groups/:id/candidates", groupHandler.GetCandidates)
+		authorized.GET("/groups/:id/profile", groupHandler.GetGroupProfile)
+		authorized.POST("/groups/:id/invites", groupHandler.InviteUser)
+		authorized.POST("/groups/:id/invites/respond", groupHandler.RespondToInvite)
+
 		// User's groups
 		authorized.GET("/my-groups", groupHandler.GetMyGroups)
+		authorized.GET("/my-invites", groupHandler.GetMyInvites)
+		authorized.GET("/my-bookmarks", groupHandler.GetMyBookmarks)
+
+		// Saved searches alert their owner about matching new groups
+		authorized.POST("/saved-searches", groupHandler.CreateSavedSearch)
+		authorized.GET("/saved-searches", groupHandler.GetSavedSearches)
+		authorized.PATCH("/saved-searches/:id", groupHandler.UpdateSavedSearch)
+		authorized.DELETE("/saved-searches/:id", groupHandler.DeleteSavedSearch)
+	}
+}
+
+// setupTagRoutes registers tag catalog endpoints
+func (o *Routes) setupTagRoutes(auth *auth.Handler, tv *tag.Service, adminIDs []string) {
+	tagHandler := tag.NewHandler(tv)
+
+	o.r.GET("/tags/suggest", tagHandler.SuggestTags)
+	o.r.GET("/tags/popular", tagHandler.PopularTags)
+
+	admin := o.r.Group("/admin", auth.AuthMiddleware(), auth.AdminMiddleware(adminIDs))
+	{
+		admin.GET("/tags", tagHandler.ListTags)
+		admin.GET("/tags/candidates", tagHandler.ListCandidates)
+		admin.POST("/tags", tagHandler.CreateTag)
+		admin.PUT("/tags/:name/parent", tagHandler.SetParent)
+		admin.POST("/tags/:name/aliases", tagHandler.CreateAlias)
+		admin.DELETE("/tags/aliases/:alias", tagHandler.DeleteAlias)
+	}
+}
+
+// setupMatchmakingRoutes registers the matchmaking queue and admin matchmaking endpoints
+func (o *Routes) setupMatchmakingRoutes(auth *auth.Handler, matchmaker *group.Matchmaker, former *group.AutoFormer, adminIDs []string) {
+	queueHandler := group.NewQueueHandler(matchmaker)
+	autoFormHandler := group.NewAutoFormHandler(former)
+
+	authorized := o.r.Group("/", auth.AuthMiddleware())
+	{
+		authorized.GET("/queue", queueHandler.GetQueueStatus)
+		authorized.POST("/queue", queueHandler.EnterQueue)
+		authorized.DELETE("/queue", queueHandler.LeaveQueue)
+		authorized.POST("/queue/proposals/:id/respond", queueHandler.RespondToProposal)
+	}
+
+	admin := o.r.Group("/admin", auth.AuthMiddleware(), auth.AdminMiddleware(adminIDs))
+	{
+		admin.POST("/matchmaking/auto-form", autoFormHandler.AutoForm)
 	}
 }
+
+// setupBuddyRoutes registers one-to-one buddy matching endpoints
+func (o *Routes) setupBuddyRoutes(auth *auth.Handler, bv *group.BuddyService) {
+	buddyHandler := group.NewBuddyHandler(bv)
+
+	authorized := o.r.Group("/buddies", auth.AuthMiddleware())
+	{
+		authorized.GET("/suggestions", buddyHandler.GetSuggestions)
+		authorized.GET("/matches", buddyHandler.GetMatches)
+		authorized.POST("/:user_id/interest", buddyHandler.ExpressInterest)
+		authorized.POST("/:user_id/pass", buddyHandler.Pass)
+	}
+}
+
+// setupExperimentRoutes registers admin experiment endpoints
+func (o *Routes) setupExperimentRoutes(auth *auth.Handler, ev *experiment.Service, adminIDs []string) {
+	experimentHandler := experiment.NewHandler(ev)
+
+	admin := o.r.Group("/admin", auth.AuthMiddleware(), auth.AdminMiddleware(adminIDs))
+	{
+		admin.GET("/experiments", experimentHandler.ListExperiments)
+		admin.GET("/experiments/:key/report", experimentHandler.GetReport)
+	}
+}
+
+// setupNotificationRoutes registers the signed-in user's notification endpoints
+func (o *Routes) setupNotificationRoutes(auth *auth.Handler, nv *notification.Service) {
+	notificationHandler := notification.NewHandler(nv)
+
+	authorized := o.r.Group("/notifications", auth.AuthMiddleware())
+	{
+		authorized.GET("", notificationHandler.ListNotifications)
+		authorized.POST("/:id/read", notificationHandler.MarkRead)
+		authorized.POST("/read-all", notificationHandler.MarkAllRead)
+	}
+}
+
+// setupFeedRoutes registers the public feeds of new groups
+func (o *Routes) setupFeedRoutes(fv *feed.Service) {
+	feedHandler := feed.NewHandler(fv)
+
+	o.r.GET("/feeds/groups.atom", feedHandler.GroupsAtom)
+	o.r.GET("/feeds/groups.json", feedHandler.GroupsJSON)
+}
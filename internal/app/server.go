@@ -7,11 +7,16 @@ import (
 
 	"bmatch/cfg"
 	"bmatch/internal/service/auth"
+	"bmatch/internal/service/experiment"
+	"bmatch/internal/service/feed"
 	"bmatch/internal/service/group"
+	"bmatch/internal/service/notification"
 	"bmatch/internal/service/session"
+	"bmatch/internal/service/tag"
 	"bmatch/internal/service/user"
 	"bmatch/pkg/cache"
 	"bmatch/pkg/db"
+	pkgexperiment "bmatch/pkg/experiment"
 	"bmatch/pkg/logger"
 	"bmatch/pkg/oauth2"
 
@@ -25,14 +30,25 @@ type Server struct {
 	logger        *logger.AppLogger
 	db            *db.SQLClient
 	cache         cache.Cache
+	stateCache    cache.Cache
 	sessionClient session.Client
 	oauth2Manager *oauth2.Manager
+	experiments   *pkgexperiment.Registry
 	shutdown      func(context.Context) error
+	stopJobs      context.CancelFunc
 
 	// internal service
-	userService  *user.Service
-	groupService *group.Service
-	authService  *auth.Service
+	userService         *user.Service
+	groupService        *group.Service
+	authService         *auth.Service
+	tagService          *tag.Service
+	autoFormer          *group.AutoFormer
+	matchmaker          *group.Matchmaker
+	buddyService        *group.BuddyService
+	recommender         *group.Recommender
+	experimentService   *experiment.Service
+	notificationService *notification.Service
+	feedService         *feed.Service
 }
 
 // NewServer creates and initializes a new server instance
@@ -58,13 +74,18 @@ func NewServer(ctx context.Context, config *cfg.Config) (*Server, error) {
 		return nil, fmt.Errorf("cache init: %w", err)
 	}
 
-	s.sessionClient = session.NewRedisStore(s.cache)
+	s.sessionClient = session.NewRedisStore(s.stateCache)
 
 	if err := s.initOAuth2(ctx); err != nil {
 		return nil, fmt.Errorf("oauth2 init: %w", err)
 	}
 
+	if err := s.initExperiments(); err != nil {
+		return nil, fmt.Errorf("experiments init: %w", err)
+	}
+
 	s.initServicesAndRoutes()
+	s.startBackgroundJobs(ctx)
 
 	s.logger.Info(ctx, "Server initialized successfully")
 	return s, nil
@@ -91,8 +112,18 @@ func (s *Server) initDatabase() error {
 }
 
 func (s *Server) initCache() error {
-	addr := s.config.Redis.Host + ":" + s.config.Redis.Port
-	s.cache = cache.NewRedisCache(addr)
+	switch s.config.Cache.Backend {
+	case cfg.CacheBackendMemory:
+		s.cache = cache.NewMemoryCache(s.config.Cache.MaxItems)
+		// Sessions and coordination state (the match queue, locks and
+		// discover versions) get their own unbounded store, so cache churn
+		// never evicts them; they still expire with their TTL
+		s.stateCache = cache.NewMemoryCache(0)
+	default:
+		addr := s.config.Redis.Host + ":" + s.config.Redis.Port
+		s.cache = cache.NewRedisCache(addr)
+		s.stateCache = s.cache
+	}
 	return nil
 }
 
@@ -105,6 +136,15 @@ func (s *Server) initOAuth2(ctx context.Context) error {
 	return nil
 }
 
+func (s *Server) initExperiments() error {
+	registry, err := pkgexperiment.NewRegistry(s.config.Experiment.File)
+	if err != nil {
+		return err
+	}
+	s.experiments = registry
+	return nil
+}
+
 func (s *Server) initServicesAndRoutes() {
 	s.authService = auth.NewService(
 		s.oauth2Manager,
@@ -122,13 +162,37 @@ func (s *Server) initServicesAndRoutes() {
 		return s.authService.HandleCallback(ctx, provider, userInfo, tokenSet)
 	}
 
+	// Initialize Tag Service
+	tagRepo := tag.NewRepository(s.db)
+	s.tagService = tag.NewService(tagRepo, s.cache, s.config.Tag, s.logger)
 	// Initialize User Service
 	userRepo := user.NewRepository(s.db)
-	s.userService = user.NewService(userRepo, s.logger)
+	s.userService = user.NewService(userRepo, s.tagService, s.logger)
+	// Initialize Experiment Service
+	experimentRepo := experiment.NewRepository(s.db)
+	s.experimentService = experiment.NewService(experimentRepo, s.experiments, s.logger)
+	// Initialize Notification Service
+	notificationRepo := notification.NewRepository(s.db)
+	s.notificationService = notification.NewService(notificationRepo, s.logger)
 	// Initialize Group Service
 	groupRepo := group.NewRepository(s.db, *s.logger)
-	groupMatcher := group.NewPostgresMatcher(groupRepo)
-	s.groupService = group.NewService(groupRepo, groupMatcher, s.cache, s.logger)
+	groupMatcher := group.NewPostgresMatcher(groupRepo, s.tagService)
+	discoverMatcher := group.NewExperimentMatcher(groupRepo, s.tagService, s.config.Group, s.experimentService, s.logger)
+	savedSearches := group.NewSavedSearchMatcher(groupRepo, s.tagService, s.notificationService, s.logger)
+	groupCache := group.NewGroupCache(s.cache, s.config.Group, s.logger)
+	discoverCache := group.NewDiscoverCache(s.cache, s.stateCache, s.tagService, s.experimentService, s.config.Group, s.logger)
+	s.groupService = group.NewService(groupRepo, discoverMatcher, groupMatcher, s.tagService, s.experimentService, savedSearches, s.notificationService, groupCache, discoverCache, s.logger)
+	s.tagService.OnRewrite = func(ctx context.Context, rewrite tag.Rewrite) {
+		s.groupService.TagsRewritten(ctx, rewrite.GroupIDs, rewrite.UserIDs)
+	}
+	s.userService.OnProfileUpdate = s.groupService.ProfileUpdated
+	// Initialize Feed Service
+	feedRepo := feed.NewRepository(s.db)
+	s.feedService = feed.NewService(feedRepo, s.tagService, s.cache, s.config.Feed, s.logger)
+	s.recommender = group.NewRecommender(groupRepo, discoverCache, s.config.Group, s.logger)
+	s.autoFormer = group.NewAutoFormer(groupRepo, s.tagService, s.stateCache, discoverCache, s.config.Group, s.logger)
+	s.buddyService = group.NewBuddyService(groupRepo, s.config.Group, s.logger)
+	s.matchmaker = group.NewMatchmaker(groupRepo, s.tagService, s.tagService, s.stateCache, s.config.Group, s.logger)
 
 	r := gin.New()
 	r.Use(gin.Recovery())
@@ -139,10 +203,35 @@ func (s *Server) initServicesAndRoutes() {
 	routes.setupAuthRoutes(authHandler, s.oauth2Manager)
 	routes.setupGroupRoutes(authHandler, s.groupService)
 	routes.setupUserRoutes(authHandler, s.userService)
+	routes.setupTagRoutes(authHandler, s.tagService, s.config.Admin.UserIDs)
+	routes.setupBuddyRoutes(authHandler, s.buddyService)
+	routes.setupMatchmakingRoutes(authHandler, s.matchmaker, s.autoFormer, s.config.Admin.UserIDs)
+	routes.setupExperimentRoutes(authHandler, s.experimentService, s.config.Admin.UserIDs)
+	routes.setupNotificationRoutes(authHandler, s.notificationService)
+	routes.setupFeedRoutes(s.feedService)
 
 	s.router = r
 }
 
+// startBackgroundJobs starts periodic jobs; they stop on Shutdown
+func (s *Server) startBackgroundJobs(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.stopJobs = cancel
+
+	for _, c := range []cache.Cache{s.cache, s.stateCache} {
+		if mem, ok := c.(*cache.MemoryCache); ok {
+			mem.StartCleanup(ctx, s.config.Cache.CleanupInterval)
+		}
+	}
+	s.tagService.StartPopularTagsRefresher(ctx)
+	s.autoFormer.Start(ctx)
+	s.matchmaker.Start(ctx)
+	s.recommender.Start(ctx)
+	s.experiments.Watch(ctx, s.config.Experiment.ReloadInterval, func(err error) {
+		s.logger.Warn(ctx, "failed to reload experiments", logger.Field{Key: "error", Value: err})
+	})
+}
+
 // Run starts the HTTP server
 func (s *Server) Run(addr string) error {
 	log.Printf("Server listening on %s", addr)
@@ -151,6 +240,9 @@ func (s *Server) Run(addr string) error {
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.stopJobs != nil {
+		s.stopJobs()
+	}
 	if s.shutdown != nil {
 		if err := s.shutdown(ctx); err != nil {
 			return fmt.Errorf("observability shutdown: %w", err)
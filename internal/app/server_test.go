@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bmatch/cfg"
+	"bmatch/internal/service/group"
+	"bmatch/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// profileRepo serves a profile for every user
+type profileRepo struct {
+	group.Repository
+}
+
+func (profileRepo) GetUserProfile(_ context.Context, userID string) (*group.UserProfile, error) {
+	return &group.UserProfile{UserID: userID, Tags: []string{"go"}, Intent: group.IntentCasual}, nil
+}
+
+type sameTags struct{}
+
+func (sameTags) Normalize(_ context.Context, tags []string) ([]string, error) {
+	return tags, nil
+}
+
+func TestServer_InitCache_StateSurvivesEviction(t *testing.T) {
+	ctx := context.Background()
+	s := &Server{config: &cfg.Config{Cache: cfg.CacheConfig{Backend: cfg.CacheBackendMemory, MaxItems: 10}}}
+	require.NoError(t, s.initCache())
+
+	m := group.NewMatchmaker(profileRepo{}, nil, sameTags{}, s.stateCache, cfg.GroupConfig{}, logger.NewLogger("test"))
+	for _, userID := range []string{"u1", "u2"} {
+		_, err := m.Enter(ctx, userID, group.EnterQueueRequest{GroupSize: 3})
+		require.NoError(t, err)
+	}
+
+	// Fill the cache well past its limit
+	for i := range 100 {
+		require.NoError(t, s.cache.Set(ctx, fmt.Sprintf("page:%d", i), "x", 0))
+	}
+	n, err := s.cache.Exists(ctx, "page:0")
+	require.NoError(t, err)
+	require.Zero(t, n, "the cache evicts")
+
+	for i, userID := range []string{"u1", "u2"} {
+		status, err := m.Status(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, status.Queued, userID)
+		assert.Equal(t, i+1, status.Position, userID)
+		require.NotNil(t, status.Entry, userID)
+		assert.Equal(t, []string{"go"}, status.Entry.Tags)
+	}
+}
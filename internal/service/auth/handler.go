@@ -104,3 +104,44 @@ func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAuthMiddleware identifies the caller when a valid session is present
+// but lets anonymous requests through
+func (h *Handler) OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID, err := c.Cookie(SessionCookieName)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		sessionData, err := h.service.ValidateAndRefreshSession(c.Request.Context(), sessionID)
+		if err != nil {
+			h.logger.Debug(c, "optional session invalid", logger.Field{Key: "error", Value: err})
+			c.Next()
+			return
+		}
+
+		c.Set("user_id", sessionData.UserID)
+		c.Next()
+	}
+}
+
+// AdminMiddleware restricts access to the given user IDs.
+// It must run after AuthMiddleware.
+func (h *Handler) AdminMiddleware(adminIDs []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists || !admins[userID.(string)] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,7 @@
+package experiment
+
+import "errors"
+
+var (
+	ErrExperimentNotFound = errors.New("experiment not found")
+)
@@ -0,0 +1,48 @@
+package experiment
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// ListExperiments handles GET /admin/experiments
+func (h *Handler) ListExperiments(c *gin.Context) {
+	experiments := h.service.ListExperiments()
+
+	c.JSON(http.StatusOK, gin.H{
+		"experiments": experiments,
+		"total":       len(experiments),
+	})
+}
+
+// GetReport handles GET /admin/experiments/:key/report
+func (h *Handler) GetReport(c *gin.Context) {
+	report, err := h.service.Report(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrExperimentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
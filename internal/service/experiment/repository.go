@@ -0,0 +1,98 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+
+	"bmatch/pkg/db"
+	"bmatch/pkg/experiment"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	InsertEvents(ctx context.Context, events []Event) error
+	CountEvents(ctx context.Context, experimentKey string) ([]EventCount, error)
+}
+
+type repository struct {
+	db db.SQLExecutor
+}
+
+func NewRepository(database db.SQLExecutor) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+// InsertEvents stores events in a single statement
+func (r *repository) InsertEvents(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(events))
+	arms := make([]string, len(events))
+	userIDs := make([]string, len(events))
+	groupIDs := make([]string, len(events))
+	types := make([]string, len(events))
+	for i, e := range events {
+		keys[i] = e.ExperimentKey
+		arms[i] = e.Arm
+		userIDs[i] = e.UserID
+		groupIDs[i] = e.GroupID
+		types[i] = e.EventType
+	}
+
+	query := `
+		INSERT INTO experiment_events (experiment_key, arm, user_id, group_id, event_type)
+		SELECT k, a, u, NULLIF(g, '')::uuid, t
+		FROM unnest($1::text[], $2::text[], $3::uuid[], $4::text[], $5::text[]) AS e(k, a, u, g, t)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		pq.Array(keys), pq.Array(arms), pq.Array(userIDs), pq.Array(groupIDs), pq.Array(types))
+	if err != nil {
+		return fmt.Errorf("insert experiment events: %w", err)
+	}
+
+	return nil
+}
+
+// CountEvents counts distinct users per arm and event type. Outcomes only
+// count users who also had an impression in the same arm, so joins through
+// links or invites of users never shown discover results are left out.
+func (r *repository) CountEvents(ctx context.Context, experimentKey string) ([]EventCount, error) {
+	query := `
+		SELECT e.arm, e.event_type, COUNT(DISTINCT e.user_id) FILTER (
+		    WHERE e.event_type = $2 OR EXISTS (
+		        SELECT 1 FROM experiment_events i
+		        WHERE i.experiment_key = e.experiment_key
+		          AND i.arm = e.arm
+		          AND i.event_type = $2
+		          AND i.user_id = e.user_id
+		    )
+		)
+		FROM experiment_events e
+		WHERE e.experiment_key = $1
+		GROUP BY e.arm, e.event_type
+		ORDER BY e.arm
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, experimentKey, experiment.EventImpression)
+	if err != nil {
+		return nil, fmt.Errorf("query experiment events: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]EventCount, 0)
+	for rows.Next() {
+		var c EventCount
+		if err := rows.Scan(&c.Arm, &c.EventType, &c.Users); err != nil {
+			return nil, fmt.Errorf("scan event count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, nil
+}
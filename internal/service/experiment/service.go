@@ -0,0 +1,143 @@
+package experiment
+
+import (
+	"context"
+	"sort"
+
+	"bmatch/pkg/experiment"
+	"bmatch/pkg/logger"
+)
+
+// Service assigns users to the active experiment and records what they do.
+// Only the first enabled experiment runs at a time.
+type Service struct {
+	repo     Repository
+	registry *experiment.Registry
+	logger   logger.Logger
+}
+
+func NewService(repo Repository, registry *experiment.Registry, logger logger.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// Assign returns the user's arm in the active experiment. Anonymous users
+// are never enrolled.
+func (s *Service) Assign(userID string) (experiment.Assignment, bool) {
+	if userID == "" {
+		return experiment.Assignment{}, false
+	}
+
+	active, ok := s.registry.Active()
+	if !ok {
+		return experiment.Assignment{}, false
+	}
+
+	return active.Assign(userID)
+}
+
+// Track records an event for each group if the user is enrolled. Failures
+// are logged rather than returned so tracking never breaks a request.
+func (s *Service) Track(ctx context.Context, userID, eventType string, groupIDs ...string) {
+	assignment, ok := s.Assign(userID)
+	if !ok {
+		return
+	}
+
+	if len(groupIDs) == 0 {
+		groupIDs = []string{""}
+	}
+
+	events := make([]Event, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		events = append(events, Event{
+			ExperimentKey: assignment.Experiment,
+			Arm:           assignment.Arm,
+			UserID:        userID,
+			GroupID:       groupID,
+			EventType:     eventType,
+		})
+	}
+
+	if err := s.repo.InsertEvents(ctx, events); err != nil {
+		s.logger.Warn(ctx, "failed to track experiment event",
+			logger.Field{Key: "experiment", Value: assignment.Experiment},
+			logger.Field{Key: "event_type", Value: eventType},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+// ListExperiments returns the experiments currently defined in config
+func (s *Service) ListExperiments() []experiment.Experiment {
+	return s.registry.Experiments()
+}
+
+// Report computes per-arm conversion: distinct exposed users with each
+// outcome divided by distinct users who saw an impression
+func (s *Service) Report(ctx context.Context, key string) (*ExperimentReport, error) {
+	counts, err := s.repo.CountEvents(ctx, key)
+	if err != nil {
+		s.logger.Error(ctx, "failed to count experiment events",
+			logger.Field{Key: "experiment", Value: key},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	def, defined := s.registry.Get(key)
+	if !defined && len(counts) == 0 {
+		return nil, ErrExperimentNotFound
+	}
+
+	arms := make(map[string]*ArmReport)
+	armReport := func(name string) *ArmReport {
+		if arms[name] == nil {
+			arms[name] = &ArmReport{
+				Arm:         name,
+				Conversions: make(map[string]int, len(outcomeEvents)),
+				Rates:       make(map[string]float64, len(outcomeEvents)),
+			}
+			for _, event := range outcomeEvents {
+				arms[name].Conversions[event] = 0
+			}
+		}
+		return arms[name]
+	}
+
+	// Defined arms are reported even before they have events
+	for _, arm := range def.Arms {
+		armReport(arm.Name)
+	}
+
+	for _, c := range counts {
+		report := armReport(c.Arm)
+		if c.EventType == experiment.EventImpression {
+			report.Exposed = c.Users
+		} else {
+			report.Conversions[c.EventType] = c.Users
+		}
+	}
+
+	result := &ExperimentReport{
+		Key:     key,
+		Enabled: def.Enabled,
+		Arms:    make([]ArmReport, 0, len(arms)),
+	}
+	for _, report := range arms {
+		for event, users := range report.Conversions {
+			if report.Exposed > 0 {
+				report.Rates[event] = float64(users) / float64(report.Exposed)
+			} else {
+				report.Rates[event] = 0
+			}
+		}
+		result.Arms = append(result.Arms, *report)
+	}
+	sort.Slice(result.Arms, func(i, j int) bool { return result.Arms[i].Arm < result.Arms[j].Arm })
+
+	return result, nil
+}
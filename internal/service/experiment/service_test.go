@@ -0,0 +1,105 @@
+package experiment
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bmatch/pkg/experiment"
+	"bmatch/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countRepo serves fixed event counts
+type countRepo struct {
+	Repository
+	counts []EventCount
+}
+
+func (r *countRepo) CountEvents(context.Context, string) ([]EventCount, error) {
+	return r.counts, nil
+}
+
+func newTestRegistry(t *testing.T) *experiment.Registry {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "experiments.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"experiments": [
+		{"key": "ranking", "enabled": true, "arms": [{"name": "control", "weight": 1}, {"name": "mmr", "weight": 1}]}
+	]}`), 0o644))
+
+	registry, err := experiment.NewRegistry(path)
+	require.NoError(t, err)
+	return registry
+}
+
+func TestService_Report(t *testing.T) {
+	registry := newTestRegistry(t)
+	ctx := context.Background()
+
+	t.Run("conversion per arm", func(t *testing.T) {
+		repo := &countRepo{counts: []EventCount{
+			{Arm: "control", EventType: experiment.EventImpression, Users: 10},
+			{Arm: "control", EventType: experiment.EventView, Users: 5},
+			{Arm: "control", EventType: experiment.EventJoin, Users: 1},
+			{Arm: "mmr", EventType: experiment.EventImpression, Users: 8},
+			{Arm: "mmr", EventType: experiment.EventApply, Users: 2},
+		}}
+		s := NewService(repo, registry, logger.NewLogger("test"))
+
+		report, err := s.Report(ctx, "ranking")
+		require.NoError(t, err)
+		assert.Equal(t, &ExperimentReport{
+			Key:     "ranking",
+			Enabled: true,
+			Arms: []ArmReport{
+				{
+					Arm:         "control",
+					Exposed:     10,
+					Conversions: map[string]int{experiment.EventView: 5, experiment.EventApply: 0, experiment.EventJoin: 1},
+					Rates:       map[string]float64{experiment.EventView: 0.5, experiment.EventApply: 0, experiment.EventJoin: 0.1},
+				},
+				{
+					Arm:         "mmr",
+					Exposed:     8,
+					Conversions: map[string]int{experiment.EventView: 0, experiment.EventApply: 2, experiment.EventJoin: 0},
+					Rates:       map[string]float64{experiment.EventView: 0, experiment.EventApply: 0.25, experiment.EventJoin: 0},
+				},
+			},
+		}, report)
+	})
+
+	t.Run("arms without events", func(t *testing.T) {
+		s := NewService(&countRepo{}, registry, logger.NewLogger("test"))
+
+		report, err := s.Report(ctx, "ranking")
+		require.NoError(t, err)
+		require.Len(t, report.Arms, 2)
+		for _, arm := range report.Arms {
+			assert.Zero(t, arm.Exposed)
+			assert.Equal(t, map[string]float64{experiment.EventView: 0, experiment.EventApply: 0, experiment.EventJoin: 0}, arm.Rates)
+		}
+	})
+
+	t.Run("removed experiment with events", func(t *testing.T) {
+		repo := &countRepo{counts: []EventCount{{Arm: "old", EventType: experiment.EventImpression, Users: 4}}}
+		s := NewService(repo, registry, logger.NewLogger("test"))
+
+		report, err := s.Report(ctx, "retired")
+		require.NoError(t, err)
+		assert.False(t, report.Enabled)
+		require.Len(t, report.Arms, 1)
+		assert.Equal(t, "old", report.Arms[0].Arm)
+		assert.Equal(t, 4, report.Arms[0].Exposed)
+	})
+
+	t.Run("unknown experiment", func(t *testing.T) {
+		s := NewService(&countRepo{}, registry, logger.NewLogger("test"))
+
+		_, err := s.Report(ctx, "unknown")
+		assert.ErrorIs(t, err, ErrExperimentNotFound)
+	})
+}
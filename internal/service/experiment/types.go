@@ -0,0 +1,40 @@
+package experiment
+
+import (
+	"time"
+
+	"bmatch/pkg/experiment"
+)
+
+// Outcomes reported as conversions, in funnel order
+var outcomeEvents = []string{experiment.EventView, experiment.EventApply, experiment.EventJoin}
+
+type Event struct {
+	ExperimentKey string    `json:"experiment_key"`
+	Arm           string    `json:"arm"`
+	UserID        string    `json:"user_id"`
+	GroupID       string    `json:"group_id,omitempty"`
+	EventType     string    `json:"event_type"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// EventCount is the number of distinct users with an event in an arm. For
+// outcome events it only counts users who also had an impression there.
+type EventCount struct {
+	Arm       string
+	EventType string
+	Users     int
+}
+
+type ArmReport struct {
+	Arm         string             `json:"arm"`
+	Exposed     int                `json:"exposed_users"`
+	Conversions map[string]int     `json:"conversions"`      // users per outcome event
+	Rates       map[string]float64 `json:"conversion_rates"` // conversions / exposed users
+}
+
+type ExperimentReport struct {
+	Key     string      `json:"key"`
+	Enabled bool        `json:"enabled"`
+	Arms    []ArmReport `json:"arms"`
+}
@@ -0,0 +1,7 @@
+package feed
+
+import "errors"
+
+var (
+	ErrUnknownFormat = errors.New("unknown feed format")
+)
@@ -0,0 +1,80 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"bmatch/pkg/feed"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GroupsAtom handles GET /feeds/groups.atom
+func (h *Handler) GroupsAtom(c *gin.Context) {
+	h.serveGroups(c, FormatAtom)
+}
+
+// GroupsJSON handles GET /feeds/groups.json
+func (h *Handler) GroupsJSON(c *gin.Context) {
+	h.serveGroups(c, FormatJSON)
+}
+
+// serveGroups writes the groups feed, answering conditional requests with
+// 304 Not Modified when the reader's copy is current
+func (h *Handler) serveGroups(c *gin.Context, format string) {
+	var req GroupsFeedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tags []string
+	for _, tag := range req.Tags {
+		for _, t := range strings.Split(tag, ",") {
+			if trimmed := strings.TrimSpace(t); trimmed != "" {
+				tags = append(tags, trimmed)
+			}
+		}
+	}
+	req.Tags = tags
+
+	doc, err := h.service.GroupsFeed(c.Request.Context(), format, req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Header("ETag", doc.ETag)
+	if !doc.LastModified.IsZero() {
+		c.Header("Last-Modified", doc.LastModified.UTC().Format(http.TimeFormat))
+	}
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedMaxAge.Seconds())))
+
+	if feed.NotModified(c.Request, doc.ETag, doc.LastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, doc.ContentType, doc.Body)
+}
+
+func (h *Handler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownFormat):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
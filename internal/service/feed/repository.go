@@ -0,0 +1,88 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"bmatch/pkg/db"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	GetNewGroups(ctx context.Context, filters GroupsFeedRequest, limit int) ([]*Group, error)
+}
+
+type repository struct {
+	db db.SQLExecutor
+}
+
+func NewRepository(database db.SQLExecutor) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+// GetNewGroups retrieves the newest open groups with free seats matching the filters
+func (r *repository) GetNewGroups(ctx context.Context, filters GroupsFeedRequest, limit int) ([]*Group, error) {
+	args := []interface{}{}
+	argIdx := 1
+
+	query := `
+		SELECT g.id, g.title, g.description, g.tags, g.join_type, g.capacity, g.current_count, g.created_at, g.updated_at
+		FROM groups g
+		WHERE g.status = 'OPEN'
+		  AND g.current_count < g.capacity
+	`
+
+	if len(filters.Tags) > 0 {
+		query += fmt.Sprintf(" AND g.tags ?| $%d", argIdx)
+		args = append(args, pq.Array(filters.Tags))
+		argIdx++
+	}
+
+	if filters.JoinType != "" {
+		query += fmt.Sprintf(" AND g.join_type = $%d", argIdx)
+		args = append(args, filters.JoinType)
+		argIdx++
+	}
+
+	if filters.SkillLevel != "" {
+		query += fmt.Sprintf(`
+		  AND EXISTS (
+		      SELECT 1 FROM group_profiles gp
+		      WHERE gp.group_id = g.id AND gp.skill_levels ? $%d
+		  )`, argIdx)
+		args = append(args, filters.SkillLevel)
+		argIdx++
+	}
+
+	query += fmt.Sprintf(" ORDER BY g.created_at DESC LIMIT $%d", argIdx)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query feed groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make([]*Group, 0)
+	for rows.Next() {
+		var g Group
+		var tagsJSON []byte
+
+		err := rows.Scan(&g.ID, &g.Title, &g.Description, &tagsJSON, &g.JoinType, &g.Capacity, &g.CurrentCount, &g.CreatedAt, &g.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan feed group: %w", err)
+		}
+
+		if err := json.Unmarshal(tagsJSON, &g.Tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+
+		groups = append(groups, &g)
+	}
+
+	return groups, rows.Err()
+}
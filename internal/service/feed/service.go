@@ -0,0 +1,162 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/feed"
+	"bmatch/pkg/logger"
+)
+
+// TagNormalizer resolves free-form tags to their canonical form
+type TagNormalizer interface {
+	Normalize(ctx context.Context, tags []string) ([]string, error)
+}
+
+type Service struct {
+	repo   Repository
+	tags   TagNormalizer
+	cache  cache.Cache
+	config cfg.FeedConfig
+	logger logger.Logger
+}
+
+func NewService(repo Repository, tags TagNormalizer, cache cache.Cache, config cfg.FeedConfig, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		tags:   tags,
+		cache:  cache,
+		config: config,
+		logger: logger,
+	}
+}
+
+// GroupsFeed renders the newest open groups matching the filters as an Atom
+// or JSON feed. Rendered feeds are cached for FEED_CACHE_TTL, so new groups
+// show up within that time.
+func (s *Service) GroupsFeed(ctx context.Context, format string, req GroupsFeedRequest) (*Document, error) {
+	if format != FormatAtom && format != FormatJSON {
+		return nil, ErrUnknownFormat
+	}
+
+	tags, err := s.tags.Normalize(ctx, req.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("normalize tags: %w", err)
+	}
+	slices.Sort(tags)
+	req.Tags = tags
+
+	query := canonicalQuery(req)
+	key := feedCacheKeyPrefix + format + ":" + query
+	if doc, err := cache.GetJSON[Document](ctx, s.cache, key); err == nil {
+		return &doc, nil
+	}
+
+	groups, err := s.repo.GetNewGroups(ctx, req, s.config.MaxItems)
+	if err != nil {
+		s.logger.Error(ctx, "failed to get feed groups", logger.Field{Key: "error", Value: err})
+		return nil, err
+	}
+
+	doc, err := s.render(format, query, groups)
+	if err != nil {
+		return nil, fmt.Errorf("render feed: %w", err)
+	}
+
+	s.store(ctx, key, doc)
+	return doc, nil
+}
+
+// render builds the feed document and its validators
+func (s *Service) render(format, query string, groups []*Group) (*Document, error) {
+	baseURL := strings.TrimRight(s.config.BaseURL, "/")
+	feedURL := baseURL + "/feeds/groups." + format
+	if query != "" {
+		feedURL += "?" + query
+	}
+
+	f := &feed.Feed{
+		ID:          feedURL,
+		Title:       "New groups",
+		Description: "Newly created groups with open seats",
+		HomeURL:     baseURL + "/groups/discover",
+		FeedURL:     feedURL,
+		Items:       make([]feed.Item, 0, len(groups)),
+	}
+
+	for _, g := range groups {
+		f.Items = append(f.Items, feed.Item{
+			ID:        "urn:uuid:" + g.ID,
+			URL:       baseURL + "/groups/" + g.ID,
+			Title:     g.Title,
+			Content:   itemContent(g),
+			Tags:      g.Tags,
+			Published: g.CreatedAt,
+			Updated:   g.UpdatedAt,
+		})
+	}
+
+	var body []byte
+	var err error
+	contentType := feed.AtomContentType
+	if format == FormatJSON {
+		body, err = f.JSON()
+		contentType = feed.JSONContentType
+	} else {
+		body, err = f.Atom()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Body:         body,
+		ContentType:  contentType,
+		ETag:         feed.ETag(body),
+		LastModified: f.LastModified(),
+	}, nil
+}
+
+// itemContent describes a group in plain text
+func itemContent(g *Group) string {
+	joining := "Open to join"
+	if g.JoinType != "OPEN" {
+		joining = "Join by application"
+	}
+
+	summary := fmt.Sprintf("%s. %d of %d seats taken.", joining, g.CurrentCount, g.Capacity)
+	if g.Description == "" {
+		return summary
+	}
+	return g.Description + "\n\n" + summary
+}
+
+// canonicalQuery encodes the filters in a stable order, for cache keys and feed URLs
+func canonicalQuery(req GroupsFeedRequest) string {
+	values := url.Values{}
+	if len(req.Tags) > 0 {
+		values.Set("tags", strings.Join(req.Tags, ","))
+	}
+	if req.JoinType != "" {
+		values.Set("join_type", req.JoinType)
+	}
+	if req.SkillLevel != "" {
+		values.Set("skill_level", req.SkillLevel)
+	}
+	return values.Encode()
+}
+
+// store caches a rendered feed. Cache failures are logged and otherwise ignored.
+func (s *Service) store(ctx context.Context, key string, doc *Document) {
+	if err := cache.SetJSON(ctx, s.cache, key, doc, s.config.CacheTTL); err != nil {
+		s.logger.Warn(ctx, "failed to cache feed",
+			logger.Field{Key: "key", Value: key},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
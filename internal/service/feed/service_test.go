@@ -0,0 +1,49 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"bmatch/cfg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Render_LastModified(t *testing.T) {
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := &Service{config: cfg.FeedConfig{BaseURL: "https://example.com"}}
+
+	cases := map[string]struct {
+		groups []*Group
+		want   time.Time
+	}{
+		"newest creation": {
+			groups: []*Group{
+				{ID: "a", CreatedAt: created.Add(time.Hour), UpdatedAt: created.Add(time.Hour)},
+				{ID: "b", CreatedAt: created, UpdatedAt: created},
+			},
+			want: created.Add(time.Hour),
+		},
+		"older group changed since": {
+			groups: []*Group{
+				{ID: "a", CreatedAt: created.Add(time.Hour), UpdatedAt: created.Add(time.Hour)},
+				{ID: "b", CreatedAt: created, UpdatedAt: created.Add(2 * time.Hour)},
+			},
+			want: created.Add(2 * time.Hour),
+		},
+		"empty feed": {
+			groups: []*Group{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, format := range []string{FormatAtom, FormatJSON} {
+				doc, err := s.render(format, "", tc.groups)
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, doc.LastModified, format)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package feed
+
+import "time"
+
+// Feed formats
+const (
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+const (
+	feedCacheKeyPrefix = "feed:groups:"
+
+	// How long readers and proxies may reuse a feed without revalidating
+	feedMaxAge = time.Minute
+)
+
+// Group is an open group as listed in feeds
+type Group struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Tags         []string  `json:"tags"`
+	JoinType     string    `json:"join_type"`
+	Capacity     int       `json:"capacity"`
+	CurrentCount int       `json:"current_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"` // last change, e.g. seats taken
+}
+
+// Document is a rendered feed with its validators, as cached
+type Document struct {
+	Body         []byte    `json:"body"`
+	ContentType  string    `json:"content_type"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// DTOs
+type GroupsFeedRequest struct {
+	Tags       []string `form:"tags"` // comma separated or repeated, groups having any of them
+	JoinType   string   `form:"join_type" binding:"omitempty,oneof=OPEN APPLICATION"`
+	SkillLevel string   `form:"skill_level" binding:"omitempty,oneof=BEGINNER INTERMEDIATE ADVANCED"` // groups with members at this level
+}
@@ -0,0 +1,428 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/logger"
+	"bmatch/pkg/taxonomy"
+
+	"github.com/google/uuid"
+)
+
+// AutoFormer clusters users who opted into auto-matching into new groups
+type AutoFormer struct {
+	repo     Repository
+	taxonomy TaxonomyProvider
+	cache    cache.Cache
+	discover *DiscoverCache
+	config   cfg.GroupConfig
+	logger   logger.Logger
+}
+
+func NewAutoFormer(repo Repository, taxonomy TaxonomyProvider, cache cache.Cache, discover *DiscoverCache, config cfg.GroupConfig, logger logger.Logger) *AutoFormer {
+	return &AutoFormer{
+		repo:     repo,
+		taxonomy: taxonomy,
+		cache:    cache,
+		discover: discover,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Run clusters unmatched opted-in users and, unless DryRun is set, creates
+// a group per cluster. The same users, seed and reference time always yield
+// the same groups.
+func (f *AutoFormer) Run(ctx context.Context, req AutoFormRequest) (*AutoFormResult, error) {
+	if !req.DryRun {
+		// Only one instance may create groups at a time
+		lock, err := cache.TryLock(ctx, f.cache, autoFormLockKey, autoFormLockTTL)
+		if err != nil {
+			return nil, fmt.Errorf("acquire auto-form lock: %w", err)
+		}
+		if lock == nil {
+			return nil, ErrAutoFormRunning
+		}
+		defer func() {
+			if err := lock.Release(ctx); err != nil {
+				f.logger.Warn(ctx, "failed to release auto-form lock", logger.Field{Key: "error", Value: err})
+			}
+		}()
+	}
+
+	at := req.At
+	if at.IsZero() {
+		at = startOfWeek(time.Now())
+	}
+
+	tax, err := f.taxonomy.Taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := f.repo.FindAutoMatchUsers(ctx)
+	if err != nil {
+		f.logger.Error(ctx, "failed to load auto-match users", logger.Field{Key: "error", Value: err})
+		return nil, err
+	}
+
+	clusters, leftovers := formClusters(users, tax, f.config.DefaultCapacity, f.config.MaxCapacity, req.Seed, at)
+
+	result := &AutoFormResult{
+		Seed:      req.Seed,
+		At:        at,
+		DryRun:    req.DryRun,
+		Groups:    make([]ProposedGroup, 0, len(clusters)),
+		Unmatched: make([]string, 0, len(leftovers)),
+	}
+	for _, u := range leftovers {
+		result.Unmatched = append(result.Unmatched, u.UserID)
+	}
+
+	for _, members := range clusters {
+		proposal := f.propose(members, tax, at)
+
+		if !req.DryRun {
+			if err := f.create(ctx, proposal); err != nil {
+				f.logger.Error(ctx, "failed to create auto-formed group",
+					logger.Field{Key: "leader_id", Value: proposal.LeaderID},
+					logger.Field{Key: "error", Value: err},
+				)
+				result.Unmatched = append(result.Unmatched, proposal.MemberIDs...)
+				continue
+			}
+		}
+
+		result.Groups = append(result.Groups, proposal)
+	}
+
+	f.logger.Info(ctx, "auto-formation finished",
+		logger.Field{Key: "seed", Value: req.Seed},
+		logger.Field{Key: "dry_run", Value: req.DryRun},
+		logger.Field{Key: "groups", Value: len(result.Groups)},
+		logger.Field{Key: "unmatched", Value: len(result.Unmatched)},
+	)
+
+	return result, nil
+}
+
+// Start runs auto-formation every AutoFormInterval until ctx is done
+func (f *AutoFormer) Start(ctx context.Context) {
+	if f.config.AutoFormInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(f.config.AutoFormInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				if _, err := f.Run(ctx, AutoFormRequest{Seed: t.UnixNano(), At: startOfWeek(t)}); err != nil {
+					f.logger.Warn(ctx, "scheduled auto-formation skipped", logger.Field{Key: "error", Value: err})
+				}
+			}
+		}
+	}()
+}
+
+// propose builds the group for a cluster and elects its leader
+func (f *AutoFormer) propose(members []*UserProfile, tax *taxonomy.Taxonomy, at time.Time) ProposedGroup {
+	leader, cohesion := electLeader(members, tax, at)
+
+	memberIDs := make([]string, len(members))
+	for i, m := range members {
+		memberIDs[i] = m.UserID
+	}
+
+	return ProposedGroup{
+		Group:     newMatchedGroup(members, leader, max(f.config.MaxCapacity, len(members))),
+		MemberIDs: memberIDs,
+		LeaderID:  leader.UserID,
+		Cohesion:  cohesion,
+	}
+}
+
+// newMatchedGroup describes a group formed by matching rather than by a user.
+// Title, description and proposal are derived from what members have in common.
+func newMatchedGroup(members []*UserProfile, leader *UserProfile, capacity int) *Group {
+	tags := topTags(members, autoFormMaxTags)
+	skill := dominant(members, func(u *UserProfile) []string { return []string{u.SkillLevel} })
+	availability := dominant(members, func(u *UserProfile) []string { return u.Availability })
+	intent := dominant(members, func(u *UserProfile) []string { return []string{u.Intent} })
+
+	title := "Matched group"
+	if len(tags) > 0 {
+		title = strings.Join(tags[:min(2, len(tags))], " & ") + " group"
+	}
+	description := fmt.Sprintf("A %s group of %d members formed automatically from shared interests.",
+		strings.ToLower(intent), len(members))
+	proposal := fmt.Sprintf("Work together on %s. Most members are %s",
+		strings.Join(tags, ", "), strings.ToLower(skill))
+	if availability != "" {
+		proposal += fmt.Sprintf(" and available on %s", strings.ToLower(availability))
+	}
+	proposal += "."
+
+	status := StatusOpen
+	if len(members) >= capacity {
+		status = StatusClosed
+	}
+
+	return &Group{
+		OwnerID:      leader.UserID,
+		Title:        title,
+		Description:  description,
+		Proposal:     proposal,
+		Tags:         tags,
+		Capacity:     capacity,
+		CurrentCount: len(members),
+		JoinType:     JoinTypeApplication,
+		Status:       status,
+		Applications: []Application{},
+		MeetingMode:  MeetingModeOnline,
+		TimeZone:     leader.TimeZone,
+	}
+}
+
+// create persists a proposed group with all of its members
+func (f *AutoFormer) create(ctx context.Context, p ProposedGroup) error {
+	p.Group.ID = uuid.New().String()
+
+	if err := createMatchedGroup(ctx, f.repo, p.Group, p.MemberIDs); err != nil {
+		return err
+	}
+
+	f.discover.InvalidateGroup(ctx, p.Group)
+	tryRefreshGroupProfile(ctx, f.repo, f.logger, p.Group.ID)
+	return nil
+}
+
+// createMatchedGroup inserts group with all members in its own transaction
+func createMatchedGroup(ctx context.Context, repo Repository, group *Group, memberIDs []string) error {
+	return repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
+		return insertMatchedGroup(ctx, tx, repo, group, memberIDs)
+	})
+}
+
+// insertMatchedGroup inserts group with all members; the owner becomes leader
+func insertMatchedGroup(ctx context.Context, tx *sql.Tx, repo Repository, group *Group, memberIDs []string) error {
+	if err := repo.CreateGroup(ctx, tx, group); err != nil {
+		return fmt.Errorf("create group: %w", err)
+	}
+
+	for _, userID := range memberIDs {
+		role := RoleMember
+		if userID == group.OwnerID {
+			role = RoleLeader
+		}
+
+		member := &GroupMember{
+			GroupID: group.ID,
+			UserID:  userID,
+			Role:    role,
+		}
+
+		if err := repo.AddMember(ctx, tx, member); err != nil {
+			return fmt.Errorf("add member: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// formClusters greedily grows clusters of up to target users with the same
+// intent around seed users picked in a seeded random order. Users that end up
+// in clusters smaller than autoFormMinSize join the best-fitting cluster with
+// room up to maxSize, or are returned as leftovers. Schedules are compared
+// in the week of at.
+func formClusters(users []*UserProfile, tax *taxonomy.Taxonomy, target, maxSize int, seed int64, at time.Time) ([][]*UserProfile, []*UserProfile) {
+	ordered := append([]*UserProfile(nil), users...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].UserID < ordered[j].UserID })
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+
+	byIntent := make(map[string][]*UserProfile)
+	for _, u := range ordered {
+		byIntent[u.Intent] = append(byIntent[u.Intent], u)
+	}
+
+	intents := make([]string, 0, len(byIntent))
+	for intent := range byIntent {
+		intents = append(intents, intent)
+	}
+	sort.Strings(intents)
+
+	clusters := make([][]*UserProfile, 0)
+	leftovers := make([]*UserProfile, 0)
+	for _, intent := range intents {
+		pool := byIntent[intent]
+		formed := make([][]*UserProfile, 0)
+		unplaced := make([]*UserProfile, 0)
+
+		for len(pool) > 0 {
+			members := []*UserProfile{pool[0]}
+			pool = pool[1:]
+
+			for len(members) < target {
+				best := bestFit(pool, members, tax, at)
+				if best == -1 {
+					break
+				}
+				members = append(members, pool[best])
+				pool = append(pool[:best], pool[best+1:]...)
+			}
+
+			if len(members) >= autoFormMinSize {
+				formed = append(formed, members)
+			} else {
+				unplaced = append(unplaced, members...)
+			}
+		}
+
+		for _, u := range unplaced {
+			best, bestScore := -1, autoFormMinFit
+			for i, c := range formed {
+				if len(c) >= maxSize {
+					continue
+				}
+				if fit := clusterFit(u, c, tax, at); fit >= bestScore && (best == -1 || fit > bestScore) {
+					best, bestScore = i, fit
+				}
+			}
+			if best == -1 {
+				leftovers = append(leftovers, u)
+				continue
+			}
+			formed[best] = append(formed[best], u)
+		}
+
+		clusters = append(clusters, formed...)
+	}
+
+	return clusters, leftovers
+}
+
+// bestFit returns the index of the pool user fitting members best, or -1 if
+// nobody reaches autoFormMinFit. Ties go to the earlier user.
+func bestFit(pool, members []*UserProfile, tax *taxonomy.Taxonomy, at time.Time) int {
+	best, bestScore := -1, autoFormMinFit
+	for i, u := range pool {
+		if fit := clusterFit(u, members, tax, at); fit >= bestScore && (best == -1 || fit > bestScore) {
+			best, bestScore = i, fit
+		}
+	}
+	return best
+}
+
+// clusterFit is the mean similarity between u and each member
+func clusterFit(u *UserProfile, members []*UserProfile, tax *taxonomy.Taxonomy, at time.Time) float64 {
+	total := 0.0
+	for _, m := range members {
+		total += userSimilarity(u, m, tax, at)
+	}
+	return total / float64(len(members))
+}
+
+// userSimilarity scores two users with the same weights used to rank
+// candidates, comparing schedules in the week of at
+func userSimilarity(a, b *UserProfile, tax *taxonomy.Taxonomy, at time.Time) float64 {
+	score := candidateTagWeight * CalculateTaxonomyScore(a.Tags, b.Tags, tax)
+	score += candidateSkillWeight * (1 - math.Abs(float64(skillRank(a.SkillLevel)-skillRank(b.SkillLevel)))/2)
+	score += candidateAvailabilityWeight * availabilityScore(a, b, at)
+	if a.Intent == b.Intent {
+		score += candidateIntentWeight
+	}
+	return score
+}
+
+// electLeader picks the member most similar to the rest of the cluster
+// (lowest user ID on ties) and returns it with the cluster's cohesion
+func electLeader(members []*UserProfile, tax *taxonomy.Taxonomy, at time.Time) (*UserProfile, float64) {
+	leader := members[0]
+	bestScore := -1.0
+	total := 0.0
+	pairs := 0
+
+	for i, m := range members {
+		sum := 0.0
+		for j, other := range members {
+			if i == j {
+				continue
+			}
+			sim := userSimilarity(m, other, tax, at)
+			sum += sim
+			if j > i {
+				total += sim
+				pairs++
+			}
+		}
+
+		if sum > bestScore || (sum == bestScore && m.UserID < leader.UserID) {
+			leader, bestScore = m, sum
+		}
+	}
+
+	if pairs == 0 {
+		return leader, 0
+	}
+	return leader, total / float64(pairs)
+}
+
+// startOfWeek is Monday 00:00 UTC of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC().Truncate(24 * time.Hour)
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// topTags returns the n most frequent member tags, alphabetical on ties
+func topTags(members []*UserProfile, n int) []string {
+	return topValues(members, func(u *UserProfile) []string { return u.Tags }, n)
+}
+
+// dominant returns the most frequent value, or "" when there is none
+func dominant(members []*UserProfile, values func(*UserProfile) []string) string {
+	top := topValues(members, values, 1)
+	if len(top) == 0 {
+		return ""
+	}
+	return top[0]
+}
+
+func topValues(members []*UserProfile, values func(*UserProfile) []string, n int) []string {
+	counts := make(map[string]int)
+	for _, m := range members {
+		for _, v := range union(values(m), nil) {
+			counts[v]++
+		}
+	}
+
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
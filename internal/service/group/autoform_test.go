@@ -0,0 +1,179 @@
+package group
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"bmatch/pkg/availability"
+	"bmatch/pkg/taxonomy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A Wednesday in January, outside European and US daylight saving
+var winter = time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+// A Wednesday in July, during European and US daylight saving
+var summer = time.Date(2025, time.July, 16, 12, 0, 0, 0, time.UTC)
+
+func testProfile(id, intent string, tags ...string) *UserProfile {
+	return &UserProfile{UserID: id, Intent: intent, Tags: tags, SkillLevel: SkillLevelBeginner}
+}
+
+func evenings(timeZone string) availability.Schedule {
+	return availability.Schedule{TimeZone: timeZone, Slots: []availability.Slot{
+		{Day: availability.Monday, Start: "18:00", End: "21:00"},
+		{Day: availability.Wednesday, Start: "18:00", End: "21:00"},
+	}}
+}
+
+func clusterIDs(clusters [][]*UserProfile) [][]string {
+	ids := make([][]string, len(clusters))
+	for i, c := range clusters {
+		for _, u := range c {
+			ids[i] = append(ids[i], u.UserID)
+		}
+	}
+	return ids
+}
+
+func TestFormClusters(t *testing.T) {
+	tax := taxonomy.New(map[string]string{"go": "backend", "python": "backend"})
+
+	cases := []struct {
+		name      string
+		users     []*UserProfile
+		target    int
+		maxSize   int
+		sizes     []int
+		leftovers []string
+	}{
+		{
+			name: "clusters by intent",
+			users: []*UserProfile{
+				testProfile("c1", IntentCasual, "go"),
+				testProfile("c2", IntentCasual, "go"),
+				testProfile("c3", IntentCasual, "go"),
+				testProfile("c4", IntentCasual, "go"),
+				testProfile("s1", IntentSerious, "python"),
+				testProfile("s2", IntentSerious, "python"),
+			},
+			target:  3,
+			maxSize: 5,
+			// The fourth casual user joins the casual cluster up to maxSize
+			sizes:     []int{4, 2},
+			leftovers: []string{},
+		},
+		{
+			name: "lone intent is left over",
+			users: []*UserProfile{
+				testProfile("c1", IntentCasual, "go"),
+				testProfile("c2", IntentCasual, "go"),
+				testProfile("c3", IntentCasual, "go"),
+				testProfile("s1", IntentSerious, "go"),
+			},
+			target:    3,
+			maxSize:   5,
+			sizes:     []int{3},
+			leftovers: []string{"s1"},
+		},
+		{
+			name: "full clusters leave users over",
+			users: []*UserProfile{
+				testProfile("c1", IntentCasual, "go"),
+				testProfile("c2", IntentCasual, "go"),
+				testProfile("c3", IntentCasual, "go"),
+				testProfile("c4", IntentCasual, "go"),
+				testProfile("c5", IntentCasual, "go"),
+			},
+			target:  2,
+			maxSize: 2,
+			sizes:   []int{2, 2},
+		},
+		{
+			name:      "no users",
+			users:     []*UserProfile{},
+			target:    3,
+			maxSize:   5,
+			sizes:     []int{},
+			leftovers: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			clusters, leftovers := formClusters(tc.users, tax, tc.target, tc.maxSize, 42, winter)
+
+			sizes := make([]int, len(clusters))
+			seen := make(map[string]bool)
+			for i, c := range clusters {
+				sizes[i] = len(c)
+				for _, u := range c {
+					assert.Equal(t, c[0].Intent, u.Intent, "cluster mixes intents")
+					assert.False(t, seen[u.UserID], "user %s placed twice", u.UserID)
+					seen[u.UserID] = true
+				}
+			}
+			for _, u := range leftovers {
+				assert.False(t, seen[u.UserID], "user %s placed twice", u.UserID)
+				seen[u.UserID] = true
+			}
+
+			assert.Equal(t, tc.sizes, sizes)
+			assert.Len(t, seen, len(tc.users))
+			if tc.leftovers != nil {
+				leftoverIDs := make([]string, 0, len(leftovers))
+				for _, u := range leftovers {
+					leftoverIDs = append(leftoverIDs, u.UserID)
+				}
+				assert.Equal(t, tc.leftovers, leftoverIDs)
+			}
+		})
+	}
+}
+
+func TestFormClusters_Deterministic(t *testing.T) {
+	tax := taxonomy.New(map[string]string{"go": "backend", "python": "backend", "react": "frontend"})
+	zones := []string{"Europe/Berlin", "America/New_York", "Asia/Tokyo", "UTC"}
+	tags := [][]string{{"go"}, {"python"}, {"react"}, {"go", "react"}}
+
+	users := make([]*UserProfile, 0, 24)
+	for i := range 24 {
+		u := testProfile(fmt.Sprintf("user-%02d", i), IntentCasual, tags[i%len(tags)]...)
+		u.Schedule = evenings(zones[i%len(zones)])
+		users = append(users, u)
+	}
+
+	reversed := append([]*UserProfile(nil), users...)
+	sort.Slice(reversed, func(i, j int) bool { return reversed[i].UserID > reversed[j].UserID })
+
+	for _, at := range []time.Time{winter, summer} {
+		clusters, leftovers := formClusters(users, tax, 4, 6, 7, at)
+		require.NotEmpty(t, clusters)
+
+		for range 3 {
+			again, againLeftovers := formClusters(reversed, tax, 4, 6, 7, at)
+			assert.Equal(t, clusterIDs(clusters), clusterIDs(again))
+			assert.Equal(t, clusterIDs([][]*UserProfile{leftovers}), clusterIDs([][]*UserProfile{againLeftovers}))
+		}
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	monday := time.Date(2025, time.January, 13, 0, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"wednesday":       winter,
+		"monday midnight": monday,
+		"sunday night":    time.Date(2025, time.January, 19, 23, 59, 0, 0, time.UTC),
+		"other zone":      time.Date(2025, time.January, 14, 0, 30, 0, 0, berlin),
+	}
+	for name, at := range cases {
+		assert.Equal(t, monday, startOfWeek(at), name)
+	}
+}
@@ -0,0 +1,129 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+
+	"bmatch/pkg/logger"
+)
+
+// bookmarkAlert is the data of a bookmark notification
+type bookmarkAlert struct {
+	GroupTitle string `json:"group_title"`
+	SeatsLeft  int    `json:"seats_left"`
+}
+
+// BookmarkGroup shortlists a group for the user. Bookmarking a group twice
+// is not an error.
+func (s *Service) BookmarkGroup(ctx context.Context, groupID, userID string) error {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	added := false
+	err = s.repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		added, err = s.repo.AddBookmark(ctx, tx, userID, groupID)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return nil
+		}
+
+		return s.repo.AdjustBookmarkCount(ctx, tx, groupID, 1)
+	})
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to bookmark group",
+			logger.Field{Key: "group_id", Value: groupID},
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return err
+	}
+	if !added {
+		return nil
+	}
+
+	// Invalidate cache; bookmark counts rank discover results
+	s.groupCache.InvalidateGroup(ctx, groupID)
+	s.discover.InvalidateGroup(ctx, group)
+
+	return nil
+}
+
+// UnbookmarkGroup removes a group from the user's shortlist
+func (s *Service) UnbookmarkGroup(ctx context.Context, groupID, userID string) error {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
+		removed, err := s.repo.RemoveBookmark(ctx, tx, userID, groupID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return ErrBookmarkNotFound
+		}
+
+		return s.repo.AdjustBookmarkCount(ctx, tx, groupID, -1)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Invalidate cache; bookmark counts rank discover results
+	s.groupCache.InvalidateGroup(ctx, groupID)
+	s.discover.InvalidateGroup(ctx, group)
+
+	return nil
+}
+
+// GetBookmarks retrieves the user's bookmarked groups
+func (s *Service) GetBookmarks(ctx context.Context, userID string) ([]*Bookmark, error) {
+	bookmarks, err := s.repo.GetUserBookmarks(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "failed to get bookmarks",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	return bookmarks, nil
+}
+
+// notifyIfAlmostFull tells bookmarkers when a join leaves the group with
+// bookmarkAlmostFullSeats seats. Groups close once full, so this is also
+// their last call before closing.
+func (s *Service) notifyIfAlmostFull(ctx context.Context, group *Group) {
+	if seatsLeft := group.Capacity - group.CurrentCount; seatsLeft == bookmarkAlmostFullSeats {
+		s.notifyBookmarkers(ctx, group, NotificationGroupAlmostFull)
+	}
+}
+
+// notifyBookmarkers notifies users who bookmarked the group, except its
+// members, of a change in its availability. Failures are only logged.
+func (s *Service) notifyBookmarkers(ctx context.Context, group *Group, notificationType string) {
+	userIDs, err := s.repo.GetBookmarkerIDs(ctx, group.ID)
+	if err != nil {
+		s.logger.Warn(ctx, "failed to get bookmarkers",
+			logger.Field{Key: "group_id", Value: group.ID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return
+	}
+
+	alert := bookmarkAlert{
+		GroupTitle: group.Title,
+		SeatsLeft:  group.Capacity - group.CurrentCount,
+	}
+	for _, userID := range userIDs {
+		s.notifier.Notify(ctx, userID, notificationType, group.ID, alert)
+	}
+}
@@ -0,0 +1,192 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"testing"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/db"
+	"bmatch/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bookmarkRepo keeps groups, bookmarks and memberships in memory. Like the
+// SQL query, GetBookmarkerIDs leaves out members.
+type bookmarkRepo struct {
+	Repository
+	groups    map[string]*Group
+	bookmarks map[string]bool // "user:group"
+	members   map[string]bool // "user:group"
+}
+
+func (r *bookmarkRepo) GetGroupByID(_ context.Context, groupID string) (*Group, error) {
+	g, ok := r.groups[groupID]
+	if !ok {
+		return nil, ErrGroupNotFound
+	}
+	copied := *g
+	return &copied, nil
+}
+
+func (r *bookmarkRepo) WithTransaction(ctx context.Context, _ sql.IsolationLevel, fn db.TxFunc) error {
+	return fn(ctx, nil)
+}
+
+func (r *bookmarkRepo) AddBookmark(_ context.Context, _ *sql.Tx, userID, groupID string) (bool, error) {
+	key := userID + ":" + groupID
+	if r.bookmarks[key] {
+		return false, nil
+	}
+	r.bookmarks[key] = true
+	return true, nil
+}
+
+func (r *bookmarkRepo) RemoveBookmark(_ context.Context, _ *sql.Tx, userID, groupID string) (bool, error) {
+	key := userID + ":" + groupID
+	if !r.bookmarks[key] {
+		return false, nil
+	}
+	delete(r.bookmarks, key)
+	return true, nil
+}
+
+func (r *bookmarkRepo) AdjustBookmarkCount(_ context.Context, _ *sql.Tx, groupID string, delta int) error {
+	r.groups[groupID].BookmarkCount += delta
+	return nil
+}
+
+func (r *bookmarkRepo) GetBookmarkerIDs(_ context.Context, groupID string) ([]string, error) {
+	userIDs := make([]string, 0)
+	for key := range r.bookmarks {
+		userID, bookmarked, _ := strings.Cut(key, ":")
+		if bookmarked == groupID && !r.members[key] {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+func newBookmarkService(repo Repository, store cache.Cache, notifier Notifier) *Service {
+	log := logger.NewLogger("test")
+	return NewService(repo, nil, nil, nil, nil, nil, notifier,
+		NewGroupCache(store, cfg.GroupConfig{}, log),
+		NewDiscoverCache(store, store, nil, nil, cfg.GroupConfig{}, log),
+		log,
+	)
+}
+
+func TestService_Bookmarks(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemoryCache(0)
+	repo := &bookmarkRepo{
+		groups:    map[string]*Group{"g": {ID: "g", Tags: []string{"go"}}},
+		bookmarks: map[string]bool{},
+		members:   map[string]bool{},
+	}
+	s := newBookmarkService(repo, store, &recordingNotifier{})
+
+	version := func() string {
+		v, err := store.Get(ctx, discoverVersionTagPrefix+"go")
+		if err != nil {
+			return ""
+		}
+		return v
+	}
+
+	t.Run("bookmark counts once", func(t *testing.T) {
+		require.NoError(t, s.BookmarkGroup(ctx, "g", "u1"))
+		assert.Equal(t, 1, repo.groups["g"].BookmarkCount)
+
+		bumped := version()
+		assert.NotEmpty(t, bumped, "discover pages ranked by the old count are dropped")
+
+		require.NoError(t, s.BookmarkGroup(ctx, "g", "u1"), "bookmarking twice is not an error")
+		assert.Equal(t, 1, repo.groups["g"].BookmarkCount)
+		assert.Equal(t, bumped, version(), "nothing changed")
+	})
+
+	t.Run("unbookmark", func(t *testing.T) {
+		before := version()
+
+		require.NoError(t, s.UnbookmarkGroup(ctx, "g", "u1"))
+		assert.Zero(t, repo.groups["g"].BookmarkCount)
+		assert.NotEqual(t, before, version())
+	})
+
+	t.Run("unbookmark not bookmarked", func(t *testing.T) {
+		before := version()
+
+		assert.ErrorIs(t, s.UnbookmarkGroup(ctx, "g", "u1"), ErrBookmarkNotFound)
+		assert.Zero(t, repo.groups["g"].BookmarkCount)
+		assert.Equal(t, before, version())
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		assert.ErrorIs(t, s.BookmarkGroup(ctx, "missing", "u1"), ErrGroupNotFound)
+		assert.ErrorIs(t, s.UnbookmarkGroup(ctx, "missing", "u1"), ErrGroupNotFound)
+	})
+}
+
+func TestService_NotifyBookmarkers(t *testing.T) {
+	ctx := context.Background()
+	repo := &bookmarkRepo{
+		groups: map[string]*Group{},
+		bookmarks: map[string]bool{
+			"u1:g":     true,
+			"member:g": true,
+			"u2:other": true,
+		},
+		members: map[string]bool{"member:g": true},
+	}
+
+	cases := []struct {
+		name   string
+		notify func(s *Service, g *Group)
+		count  int
+		want   []notification
+	}{
+		{
+			name:   "almost full",
+			notify: func(s *Service, g *Group) { s.notifyIfAlmostFull(ctx, g) },
+			count:  4,
+			want: []notification{{UserID: "u1", Type: NotificationGroupAlmostFull, GroupID: "g", Data: bookmarkAlert{
+				GroupTitle: "Go study", SeatsLeft: 1,
+			}}},
+		},
+		{
+			name:   "more than one seat left",
+			notify: func(s *Service, g *Group) { s.notifyIfAlmostFull(ctx, g) },
+			count:  3,
+		},
+		{
+			name:   "full",
+			notify: func(s *Service, g *Group) { s.notifyIfAlmostFull(ctx, g) },
+			count:  5,
+		},
+		{
+			name:   "reopened",
+			notify: func(s *Service, g *Group) { s.notifyBookmarkers(ctx, g, NotificationGroupReopened) },
+			count:  4,
+			want: []notification{{UserID: "u1", Type: NotificationGroupReopened, GroupID: "g", Data: bookmarkAlert{
+				GroupTitle: "Go study", SeatsLeft: 1,
+			}}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			s := newBookmarkService(repo, cache.NewMemoryCache(0), notifier)
+
+			tc.notify(s, &Group{ID: "g", Title: "Go study", Capacity: 5, CurrentCount: tc.count})
+			assert.Equal(t, tc.want, notifier.notified())
+		})
+	}
+}
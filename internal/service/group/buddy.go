@@ -0,0 +1,214 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// BuddyService pairs users one-to-one. Both users must express interest in
+// each other before a two-person group is created for the pair.
+type BuddyService struct {
+	repo   Repository
+	config cfg.GroupConfig
+	logger logger.Logger
+}
+
+func NewBuddyService(repo Repository, config cfg.GroupConfig, logger logger.Logger) *BuddyService {
+	return &BuddyService{
+		repo:   repo,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Suggestions ranks users in buddy mode by profile similarity. Users the
+// caller passed on within BuddyPassDuration are not suggested.
+func (s *BuddyService) Suggestions(ctx context.Context, userID string, limit int) ([]BuddySuggestion, error) {
+	if limit == 0 {
+		limit = 10
+	}
+
+	profile, err := s.repo.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	passedSince := time.Now().Add(-s.config.BuddyPassDuration)
+	candidates, err := s.repo.FindBuddyCandidates(ctx, userID, passedSince, candidatePoolSize)
+	if err != nil {
+		s.logger.Error(ctx, "failed to find buddy candidates",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	suggestions := make([]BuddySuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		suggestions = append(suggestions, BuddySuggestion{
+			User:            candidate,
+			SimilarityScore: CalculateBuddyScore(profile, candidate),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].SimilarityScore > suggestions[j].SimilarityScore
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
+
+// ExpressInterest records interest in target. If target is already
+// interested in the user, the pair group is created and returned.
+func (s *BuddyService) ExpressInterest(ctx context.Context, userID, targetID string) (*BuddyMatch, error) {
+	if userID == targetID {
+		return nil, ErrBuddySelf
+	}
+
+	available, err := s.repo.IsBuddyAvailable(ctx, userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrBuddyUnavailable
+	}
+
+	// The caller must be discoverable too, or the target could never show interest back
+	available, err = s.repo.IsBuddyAvailable(ctx, targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrBuddyModeOff
+	}
+
+	user, err := s.repo.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.GetUserProfile(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var match *BuddyMatch
+
+	// Serializable so two simultaneous interests cannot both miss each other.
+	// The loser of such a race is rolled back and retried, and then sees the
+	// winner's interest.
+	for attempt := 1; attempt <= buddyInterestAttempts; attempt++ {
+		match, err = s.expressInterest(ctx, user, target)
+		if !isSerializationFailure(err) {
+			break
+		}
+	}
+	if isSerializationFailure(err) {
+		err = ErrBuddyConflict
+	}
+	if err != nil {
+		s.logger.Error(ctx, "failed to express buddy interest",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "target_id", Value: targetID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	if match != nil {
+		tryRefreshGroupProfile(ctx, s.repo, s.logger, match.GroupID)
+
+		s.logger.Info(ctx, "buddies matched",
+			logger.Field{Key: "group_id", Value: match.GroupID},
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "buddy_id", Value: targetID},
+		)
+	}
+
+	return match, nil
+}
+
+// expressInterest saves the reaction and pairs the users if it is mutual
+func (s *BuddyService) expressInterest(ctx context.Context, user, target *UserProfile) (*BuddyMatch, error) {
+	userID, targetID := user.UserID, target.UserID
+	var match *BuddyMatch
+
+	err := s.repo.WithTransaction(ctx, sql.LevelSerializable, func(ctx context.Context, tx *sql.Tx) error {
+		if err := s.repo.SaveBuddyReaction(ctx, tx, userID, targetID, BuddyReactionInterest); err != nil {
+			return err
+		}
+
+		mutual, err := s.repo.HasBuddyInterest(ctx, tx, targetID, userID)
+		if err != nil {
+			return err
+		}
+		if !mutual {
+			return nil
+		}
+
+		// Whoever showed interest first leads the pair
+		group := newMatchedGroup([]*UserProfile{target, user}, target, buddyGroupCapacity)
+		group.ID = uuid.New().String()
+
+		if err := insertMatchedGroup(ctx, tx, s.repo, group, []string{targetID, userID}); err != nil {
+			return err
+		}
+
+		if err := s.repo.CreateBuddyMatch(ctx, tx, group.ID, userID, targetID); err != nil {
+			return err
+		}
+
+		match = &BuddyMatch{
+			GroupID:   group.ID,
+			Buddy:     target,
+			CreatedAt: time.Now(),
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return match, nil
+}
+
+// Pass hides target from the user's suggestions for BuddyPassDuration.
+// It also withdraws any interest the user had shown in target.
+func (s *BuddyService) Pass(ctx context.Context, userID, targetID string) error {
+	if userID == targetID {
+		return ErrBuddySelf
+	}
+
+	return s.repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
+		return s.repo.SaveBuddyReaction(ctx, tx, userID, targetID, BuddyReactionPass)
+	})
+}
+
+// Matches lists the user's current buddies
+func (s *BuddyService) Matches(ctx context.Context, userID string) ([]*BuddyMatch, error) {
+	return s.repo.GetBuddyMatches(ctx, userID)
+}
+
+// CalculateBuddyScore scores two profiles with the candidate weights, using
+// plain Jaccard similarity for tags and schedule overlap for availability
+func CalculateBuddyScore(a, b *UserProfile) float64 {
+	score := candidateTagWeight * CalculateJaccardScore(a.Tags, b.Tags)
+	score += candidateSkillWeight * (1 - math.Abs(float64(skillRank(a.SkillLevel)-skillRank(b.SkillLevel)))/2)
+	score += candidateAvailabilityWeight * availabilityScore(a, b, time.Now())
+	if a.Intent == b.Intent {
+		score += candidateIntentWeight
+	}
+	return score
+}
@@ -0,0 +1,69 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"bmatch/cfg"
+	"bmatch/pkg/db"
+	"bmatch/pkg/logger"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// buddyRepo stubs the repository calls made before ExpressInterest's transaction
+type buddyRepo struct {
+	Repository
+	available map[string]bool // "user:target"
+	conflicts int
+	attempts  int
+}
+
+func (r *buddyRepo) IsBuddyAvailable(_ context.Context, userID, targetID string) (bool, error) {
+	return r.available[userID+":"+targetID], nil
+}
+
+func (r *buddyRepo) GetUserProfile(_ context.Context, userID string) (*UserProfile, error) {
+	return testProfile(userID, IntentCasual), nil
+}
+
+// WithTransaction fails with a serialization error the first conflicts times
+func (r *buddyRepo) WithTransaction(_ context.Context, _ sql.IsolationLevel, _ db.TxFunc) error {
+	r.attempts++
+	if r.attempts <= r.conflicts {
+		return &pq.Error{Code: "40001"}
+	}
+	return nil
+}
+
+func TestBuddyService_ExpressInterest(t *testing.T) {
+	both := map[string]bool{"a:b": true, "b:a": true}
+
+	cases := []struct {
+		name      string
+		available map[string]bool
+		conflicts int
+		attempts  int
+		err       error
+	}{
+		{name: "saved", available: both, attempts: 1},
+		{name: "target not in buddy mode", available: map[string]bool{"b:a": true}, err: ErrBuddyUnavailable},
+		{name: "caller not in buddy mode", available: map[string]bool{"a:b": true}, err: ErrBuddyModeOff},
+		{name: "conflict is retried", available: both, conflicts: 2, attempts: 3},
+		{name: "persistent conflict", available: both, conflicts: buddyInterestAttempts, attempts: buddyInterestAttempts, err: ErrBuddyConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &buddyRepo{available: tc.available, conflicts: tc.conflicts}
+			s := NewBuddyService(repo, cfg.GroupConfig{}, logger.NewLogger("test"))
+
+			match, err := s.ExpressInterest(context.Background(), "a", "b")
+			assert.ErrorIs(t, err, tc.err)
+			assert.Nil(t, match)
+			assert.Equal(t, tc.attempts, repo.attempts)
+		})
+	}
+}
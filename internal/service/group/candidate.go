@@ -0,0 +1,223 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"bmatch/pkg/experiment"
+	"bmatch/pkg/logger"
+)
+
+// FindCandidates ranks users who opted into invites for an owner's open group
+func (s *Service) FindCandidates(ctx context.Context, groupID, ownerID string, limit int) ([]CandidateMatch, error) {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.OwnerID != ownerID {
+		return nil, ErrNotGroupOwner
+	}
+
+	if group.Status != StatusOpen {
+		return nil, ErrGroupNotOpen
+	}
+
+	if limit == 0 {
+		limit = 20
+	}
+
+	candidates, err := s.candidates.FindCandidates(ctx, group, limit)
+	if err != nil {
+		s.logger.Error(ctx, "failed to find candidates",
+			logger.Field{Key: "group_id", Value: groupID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// InviteUser invites a user who is open to invites into an owner's group
+func (s *Service) InviteUser(ctx context.Context, groupID, ownerID, userID string) (*GroupInvite, error) {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.OwnerID != ownerID {
+		return nil, ErrNotGroupOwner
+	}
+
+	if group.Status != StatusOpen {
+		return nil, ErrGroupNotOpen
+	}
+
+	if group.CurrentCount >= group.Capacity {
+		return nil, ErrGroupFull
+	}
+
+	isMember, err := s.repo.IsMember(ctx, groupID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("check membership: %w", err)
+	}
+	if isMember {
+		return nil, ErrAlreadyMember
+	}
+
+	invitable, err := s.repo.IsInvitable(ctx, ownerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !invitable {
+		return nil, ErrUserNotInvitable
+	}
+
+	invite := &GroupInvite{
+		GroupID:   groupID,
+		UserID:    userID,
+		InvitedBy: ownerID,
+		Status:    InviteStatusPending,
+	}
+
+	if err := s.repo.CreateInvite(ctx, invite); err != nil {
+		s.logger.Error(ctx, "failed to invite user",
+			logger.Field{Key: "group_id", Value: groupID},
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "user invited",
+		logger.Field{Key: "group_id", Value: groupID},
+		logger.Field{Key: "user_id", Value: userID},
+	)
+
+	return invite, nil
+}
+
+// RespondToInvite accepts or declines a pending invite. Accepting joins the
+// group regardless of its join type, taking a seat in the named role slot or
+// an unreserved seat when slot is empty. Capacity, the group's language
+// requirement and the slot's tags apply as for any other join.
+func (s *Service) RespondToInvite(ctx context.Context, groupID, userID string, accept bool, slot string) error {
+	var joined *Group
+
+	err := s.repo.WithTransaction(ctx, sql.LevelSerializable, func(ctx context.Context, tx *sql.Tx) error {
+		// 1. Lock the invite and the group
+		invite, err := s.repo.GetInviteWithLock(ctx, tx, groupID, userID)
+		if err != nil {
+			return err
+		}
+		if invite.Status != InviteStatusPending {
+			return ErrInviteNotFound
+		}
+
+		group, err := s.repo.GetGroupWithLock(ctx, tx, groupID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		invite.DecidedAt = &now
+
+		if !accept {
+			invite.Status = InviteStatusDeclined
+			return s.repo.UpdateInvite(ctx, tx, invite)
+		}
+
+		// 2. Validate group state
+		if group.Status != StatusOpen {
+			return ErrGroupNotOpen
+		}
+
+		if err := s.checkLanguageRequirement(ctx, group, userID); err != nil {
+			return err
+		}
+
+		if group.CurrentCount >= group.Capacity {
+			return ErrGroupFull
+		}
+
+		slotName, err := s.checkSlot(ctx, group, userID, slot)
+		if err != nil {
+			return err
+		}
+
+		// 3. Add member
+		member := &GroupMember{
+			GroupID: groupID,
+			UserID:  userID,
+			Role:    RoleMember,
+			Slot:    slotName,
+		}
+
+		if err := s.repo.AddMember(ctx, tx, member); err != nil {
+			return fmt.Errorf("add invited member: %w", err)
+		}
+
+		// 4. Increment counters and close group if full
+		group.CurrentCount++
+		group.fillSlot(slotName)
+		if group.CurrentCount >= group.Capacity {
+			group.Status = StatusClosed
+		}
+
+		if err := s.repo.UpdateGroup(ctx, tx, group); err != nil {
+			return fmt.Errorf("update group: %w", err)
+		}
+		joined = group
+
+		invite.Status = InviteStatusAccepted
+		return s.repo.UpdateInvite(ctx, tx, invite)
+	})
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to respond to invite",
+			logger.Field{Key: "group_id", Value: groupID},
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "accept", Value: accept},
+			logger.Field{Key: "error", Value: err},
+		)
+		return err
+	}
+
+	if accept {
+		// Invalidate cache
+		s.groupCache.InvalidateGroup(ctx, groupID)
+		s.groupCache.InvalidateMembers(ctx, groupID)
+		s.discover.InvalidateGroup(ctx, joined)
+		s.discover.InvalidateUser(ctx, userID)
+
+		tryRefreshGroupProfile(ctx, s.repo, s.logger, groupID)
+		s.notifyIfAlmostFull(ctx, joined)
+
+		s.experiments.Track(ctx, userID, experiment.EventJoin, groupID)
+	}
+
+	s.logger.Info(ctx, "invite answered",
+		logger.Field{Key: "group_id", Value: groupID},
+		logger.Field{Key: "user_id", Value: userID},
+		logger.Field{Key: "accepted", Value: accept},
+	)
+
+	return nil
+}
+
+// GetUserInvites retrieves pending invites for a user
+func (s *Service) GetUserInvites(ctx context.Context, userID string) ([]*GroupInvite, error) {
+	invites, err := s.repo.GetUserInvites(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "failed to get user invites",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	return invites, nil
+}
@@ -0,0 +1,255 @@
+package group
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var discoverCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "bmatch_discover_cache_requests_total",
+	Help: "Discover cache lookups by result (hit, miss, error).",
+}, []string{"result"})
+
+// DiscoverCache caches discover results by a hash of the caller's resolved
+// profile, the filters, the caller's experiment arm and the versions of
+// everything the results depend on:
+//
+//   - the tags searched for, widened to related tags for expand searches, or
+//     every group for searches without tags
+//   - the caller's own memberships and applications
+//   - the recommendations, rebuilt periodically
+//
+// Changes bump versions instead of deleting pages, so pages keyed by old
+// versions are never read again and expire on their own. Versions are read
+// before results are computed; a page computed while a change commits is
+// keyed by the old version and only served to requests that read it too.
+//
+// Versions live in their own store, which must not evict them: an evicted
+// version reads as empty again and would bring back pages cached under it.
+type DiscoverCache struct {
+	cache       cache.Cache
+	versions    cache.Cache
+	taxonomy    TaxonomyProvider
+	experiments ExperimentTracker
+	ttl         time.Duration
+	anonTTL     time.Duration
+	logger      logger.Logger
+}
+
+func NewDiscoverCache(cache, versions cache.Cache, taxonomy TaxonomyProvider, experiments ExperimentTracker, config cfg.GroupConfig, logger logger.Logger) *DiscoverCache {
+	return &DiscoverCache{
+		cache:       cache,
+		versions:    versions,
+		taxonomy:    taxonomy,
+		experiments: experiments,
+		ttl:         min(config.DiscoverCacheTTL, discoverVersionTTL),
+		anonTTL:     min(config.DiscoverCacheAnonTTL, discoverVersionTTL),
+		logger:      logger,
+	}
+}
+
+// discoverCacheKey is hashed into the key of a cached discover page
+type discoverCacheKey struct {
+	Profile  UserProfile           `json:"profile"`
+	Filters  DiscoverGroupsRequest `json:"filters"`
+	Arm      string                `json:"arm,omitempty"` // pipeline the results were ranked by
+	Versions []string              `json:"versions"`
+}
+
+// Key returns the cache key of a discover request with a resolved profile
+// and normalized tags. It returns false when results must not be cached,
+// including when versions cannot be read.
+func (c *DiscoverCache) Key(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) (string, bool) {
+	if c.pageTTL(userProfile.UserID) <= 0 {
+		return "", false
+	}
+
+	scope := []string{discoverVersionAnyKey}
+	if len(userProfile.Tags) > 0 {
+		tags := userProfile.Tags
+		if filters.Expand {
+			tax, err := c.taxonomy.Taxonomy(ctx)
+			if err != nil {
+				return "", false
+			}
+			tags = expandTags(tags, tax)
+		}
+
+		scope = make([]string, 0, len(tags))
+		for _, tag := range tags {
+			scope = append(scope, discoverVersionTagPrefix+tag)
+		}
+		slices.Sort(scope)
+	}
+
+	versionKeys := append([]string{discoverVersionEpochKey}, scope...)
+	if userProfile.UserID != "" {
+		versionKeys = append(versionKeys, discoverVersionUserPrefix+userProfile.UserID)
+	}
+
+	key := discoverCacheKey{
+		Profile:  userProfile,
+		Filters:  filters,
+		Versions: make([]string, 0, len(versionKeys)),
+	}
+	key.Profile.Tags = slices.Sorted(slices.Values(userProfile.Tags))
+	if assignment, ok := c.experiments.Assign(userProfile.UserID); ok {
+		key.Arm = armKey(assignment)
+	}
+
+	versions, err := c.versions.MGet(ctx, versionKeys...)
+	if err != nil {
+		c.logger.Warn(ctx, "failed to read discover cache versions",
+			logger.Field{Key: "error", Value: err},
+		)
+		return "", false
+	}
+	for _, versionKey := range versionKeys {
+		key.Versions = append(key.Versions, versionKey+"="+versions[versionKey])
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return discoverCacheKeyPrefix + hex.EncodeToString(sum[:16]), true
+}
+
+// Get returns a cached page
+func (c *DiscoverCache) Get(ctx context.Context, key string) ([]GroupMatch, bool) {
+	data, err := c.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		discoverCacheRequests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	if err != nil {
+		discoverCacheRequests.WithLabelValues("error").Inc()
+		c.logger.Warn(ctx, "failed to read discover cache",
+			logger.Field{Key: "key", Value: key},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, false
+	}
+
+	var matches []GroupMatch
+	if err := json.Unmarshal([]byte(data), &matches); err != nil {
+		discoverCacheRequests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	discoverCacheRequests.WithLabelValues("hit").Inc()
+	return matches, true
+}
+
+// Set caches a page. Anonymous pages are shared by every visitor and may
+// be kept longer than personal ones.
+func (c *DiscoverCache) Set(ctx context.Context, key, userID string, matches []GroupMatch) {
+	data, err := json.Marshal(matches)
+	if err != nil {
+		return
+	}
+
+	if err := c.cache.Set(ctx, key, string(data), c.pageTTL(userID)); err != nil {
+		c.logger.Warn(ctx, "failed to write discover cache",
+			logger.Field{Key: "key", Value: key},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+// InvalidateGroup drops pages that could include the group, after its
+// tags, status or member count changed or it was created
+func (c *DiscoverCache) InvalidateGroup(ctx context.Context, group *Group) {
+	keys := []string{discoverVersionAnyKey}
+	for _, tag := range group.Tags {
+		keys = append(keys, discoverVersionTagPrefix+tag)
+	}
+	c.bump(ctx, keys...)
+}
+
+// InvalidateUser drops a user's pages after they joined, left or applied to
+// a group, or an application of theirs was decided
+func (c *DiscoverCache) InvalidateUser(ctx context.Context, userID string) {
+	c.bump(ctx, discoverVersionUserPrefix+userID)
+}
+
+// InvalidateAll drops every page, e.g. after recommendations were rebuilt
+func (c *DiscoverCache) InvalidateAll(ctx context.Context) {
+	c.bump(ctx, discoverVersionEpochKey)
+}
+
+// bump sets new random versions rather than incrementing counters, which
+// could repeat a value after expiring. Versions outlive every page keyed by
+// them, so an expired version can never resurrect an old page.
+func (c *DiscoverCache) bump(ctx context.Context, keys ...string) {
+	versions := make(map[string]string, len(keys))
+	for _, key := range keys {
+		versions[key] = uuid.NewString()
+	}
+
+	if err := c.versions.MSet(ctx, versions, discoverVersionTTL); err != nil {
+		c.logger.Warn(ctx, "failed to bump discover cache versions",
+			logger.Field{Key: "keys", Value: keys},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+func (c *DiscoverCache) pageTTL(userID string) time.Duration {
+	if userID == "" {
+		return c.anonTTL
+	}
+	return c.ttl
+}
+
+// cachedMatches serves a cached discover page with live seats. Groups that
+// filled up or closed since the page was cached are dropped, so a stale page
+// never offers a full group. Without live seats the page is not served.
+func (s *Service) cachedMatches(ctx context.Context, key string, userTags []string) ([]GroupMatch, bool) {
+	matches, ok := s.discover.Get(ctx, key)
+	if !ok {
+		return nil, false
+	}
+
+	groupIDs := make([]string, len(matches))
+	for i, m := range matches {
+		groupIDs[i] = m.Group.ID
+	}
+
+	seats, err := s.repo.GetOpenSeats(ctx, groupIDs)
+	if err != nil {
+		s.logger.Warn(ctx, "failed to check seats of cached discover page",
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, false
+	}
+
+	open := matches[:0]
+	for _, m := range matches {
+		live, ok := seats[m.Group.ID]
+		if !ok {
+			continue
+		}
+		m.Group.CurrentCount = live.CurrentCount
+		m.Group.RoleSlots = live.RoleSlots
+		m.MatchingSlots = nil
+		open = append(open, m)
+	}
+	annotateSlots(open, userTags)
+
+	return open, true
+}
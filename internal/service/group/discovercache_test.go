@@ -0,0 +1,70 @@
+package group
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/experiment"
+	"bmatch/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// armTracker assigns users to fixed arms
+type armTracker struct {
+	arms map[string]experiment.Assignment
+}
+
+func (t *armTracker) Assign(userID string) (experiment.Assignment, bool) {
+	a, ok := t.arms[userID]
+	return a, ok
+}
+
+func (t *armTracker) Track(context.Context, string, string, ...string) {}
+
+func TestDiscoverCache_KeyByArm(t *testing.T) {
+	tracker := &armTracker{arms: map[string]experiment.Assignment{}}
+	config := cfg.GroupConfig{DiscoverCacheTTL: time.Minute, DiscoverCacheAnonTTL: time.Minute}
+	store := cache.NewMemoryCache(0)
+	c := NewDiscoverCache(store, store, nil, tracker, config, logger.NewLogger("test"))
+
+	ctx := context.Background()
+	profile := UserProfile{UserID: "u1", Tags: []string{"go"}}
+	filters := DiscoverGroupsRequest{Limit: 10}
+
+	key := func() string {
+		k, ok := c.Key(ctx, profile, filters)
+		require.True(t, ok)
+		return k
+	}
+
+	unassigned := key()
+	assert.Equal(t, unassigned, key(), "keys are stable")
+
+	cases := []struct {
+		name       string
+		assignment experiment.Assignment
+	}{
+		{name: "control", assignment: experiment.Assignment{Experiment: "ranking", Arm: "control"}},
+		{name: "treatment", assignment: experiment.Assignment{Experiment: "ranking", Arm: "diverse", Params: json.RawMessage(`{"diversity_lambda":0.5}`)}},
+		{name: "edited params", assignment: experiment.Assignment{Experiment: "ranking", Arm: "diverse", Params: json.RawMessage(`{"diversity_lambda":0.3}`)}},
+		{name: "next experiment", assignment: experiment.Assignment{Experiment: "ranking-2", Arm: "control"}},
+	}
+
+	seen := map[string]string{unassigned: "unassigned"}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker.arms["u1"] = tc.assignment
+			k := key()
+
+			prev, dup := seen[k]
+			assert.False(t, dup, "same key as %s", prev)
+			seen[k] = tc.name
+		})
+	}
+}
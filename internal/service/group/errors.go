@@ -9,6 +9,23 @@ var (
 	ErrGroupNotOpen       = errors.New("group is not accepting members")
 	ErrInvalidGroupStatus = errors.New("invalid group status")
 	ErrInvalidJoinType    = errors.New("invalid join type")
+	ErrInvalidTags        = errors.New("tags must contain at least one non-empty tag")
+	ErrInvalidSchedule    = errors.New("invalid schedule")
+	ErrScheduleRequired   = errors.New("set a weekly schedule on your profile to filter by overlap")
+	ErrInvalidLocation    = errors.New("invalid location")
+	ErrInvalidTimeZone    = errors.New("invalid time zone")
+	ErrLocationRequired   = errors.New("in-person groups need a location or city")
+	ErrOriginRequired     = errors.New("pass lat and lon or set a location on your profile to filter by radius")
+	ErrTimeZoneRequired   = errors.New("pass tz or set a time zone on your profile to filter by time zone offset")
+	ErrInvalidLanguage    = errors.New("invalid language")
+	ErrLanguagesRequired  = errors.New("pass languages or add languages to your profile to filter by language")
+	ErrLanguageRequirementNotMet = errors.New("you do not speak the group's language at the required level")
+	ErrInvalidRoleSlots   = errors.New("invalid role slots")
+	ErrSlotNotFound       = errors.New("role slot not found")
+	ErrSlotFull           = errors.New("role slot is full")
+	ErrSlotTagsNotMet     = errors.New("your tags do not match the role slot")
+	ErrSeatsReserved      = errors.New("remaining seats are reserved for role slots")
+	ErrGroupProfileNotFound = errors.New("group profile not found")
 
 	// Member errors
 	ErrAlreadyMember     = errors.New("user already in group")
@@ -23,6 +40,36 @@ var (
 	ErrCannotApplyToOpenGroup   = errors.New("cannot apply to open group, use join instead")
 	ErrCannotJoinApplicationGroup = errors.New("cannot join application group, submit application instead")
 
+	// Invite errors
+	ErrInviteExists     = errors.New("user already invited")
+	ErrInviteNotFound   = errors.New("invite not found")
+	ErrUserNotInvitable = errors.New("user is not open to invites")
+
+	// Matchmaking queue errors
+	ErrUserNotFound     = errors.New("user not found")
+	ErrAlreadyQueued    = errors.New("user is already queued or has a pending match")
+	ErrNotQueued        = errors.New("user is not in the matchmaking queue")
+	ErrProposalNotFound = errors.New("match proposal not found")
+	ErrQueueBusy        = errors.New("matchmaking queue is busy, try again")
+
+	// Buddy errors
+	ErrBuddySelf        = errors.New("cannot pair with yourself")
+	ErrBuddyUnavailable = errors.New("user is not available as a buddy")
+	ErrBuddyModeOff     = errors.New("turn on buddy mode to express interest")
+	ErrAlreadyBuddies   = errors.New("users are already buddies")
+	ErrBuddyConflict    = errors.New("buddy interest changed concurrently, try again")
+
+	// Saved search errors
+	ErrSavedSearchNotFound  = errors.New("saved search not found")
+	ErrInvalidSavedSearch   = errors.New("invalid saved search")
+	ErrTooManySavedSearches = errors.New("too many saved searches")
+
+	// Bookmark errors
+	ErrBookmarkNotFound = errors.New("group is not bookmarked")
+
+	// Auto-formation errors
+	ErrAutoFormRunning = errors.New("auto-formation is already running")
+
 	// Generic errors
 	ErrInvalidInput     = errors.New("invalid input")
 	ErrUnauthorized     = errors.New("unauthorized")
@@ -0,0 +1,172 @@
+package group
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/experiment"
+	"bmatch/pkg/logger"
+)
+
+// ExperimentTracker assigns users to experiment arms and records their events
+type ExperimentTracker interface {
+	Assign(userID string) (experiment.Assignment, bool)
+	Track(ctx context.Context, userID, eventType string, groupIDs ...string)
+}
+
+// MatcherOverrides are the discover settings an experiment arm may change,
+// given as the arm's params. Unset fields keep the configured value.
+type MatcherOverrides struct {
+	RecommendWeight     *float64 `json:"recommend_weight"`
+	MemberProfileWeight *float64 `json:"member_profile_weight"`
+	DiversityLambda     *float64 `json:"diversity_lambda"`
+	FreshnessWeight     *float64 `json:"freshness_weight"`
+	FreshnessHalfLife   *string  `json:"freshness_half_life"`
+	PopularityWeight    *float64 `json:"popularity_weight"`
+	PopularityHalfLife  *string  `json:"popularity_half_life"`
+	BookmarkWeight      *float64 `json:"bookmark_weight"`
+}
+
+// Apply returns config with the overrides applied
+func (o MatcherOverrides) Apply(config cfg.GroupConfig) (cfg.GroupConfig, error) {
+	if o.RecommendWeight != nil {
+		config.RecommendWeight = *o.RecommendWeight
+	}
+	if o.MemberProfileWeight != nil {
+		config.MemberProfileWeight = *o.MemberProfileWeight
+	}
+	if o.DiversityLambda != nil {
+		config.Ranking.DiversityLambda = *o.DiversityLambda
+	}
+	if o.FreshnessWeight != nil {
+		config.Ranking.FreshnessWeight = *o.FreshnessWeight
+	}
+	if o.PopularityWeight != nil {
+		config.Ranking.PopularityWeight = *o.PopularityWeight
+	}
+	if o.BookmarkWeight != nil {
+		config.Ranking.BookmarkWeight = *o.BookmarkWeight
+	}
+
+	if o.FreshnessHalfLife != nil {
+		d, err := time.ParseDuration(*o.FreshnessHalfLife)
+		if err != nil {
+			return config, fmt.Errorf("freshness_half_life: %w", err)
+		}
+		config.Ranking.FreshnessHalfLife = d
+	}
+	if o.PopularityHalfLife != nil {
+		d, err := time.ParseDuration(*o.PopularityHalfLife)
+		if err != nil {
+			return config, fmt.Errorf("popularity_half_life: %w", err)
+		}
+		config.Ranking.PopularityHalfLife = d
+	}
+
+	if config.RecommendWeight < 0 || config.RecommendWeight > 1 {
+		return config, fmt.Errorf("recommend_weight must be between 0 and 1")
+	}
+	if config.MemberProfileWeight < 0 || config.MemberProfileWeight > 1 {
+		return config, fmt.Errorf("member_profile_weight must be between 0 and 1")
+	}
+
+	return config, nil
+}
+
+// NewDiscoverMatcher builds the discover pipeline: content matching, scored
+// against groups' members when enabled, blended with collaborative
+// filtering, followed by the configured ranking stages
+func NewDiscoverMatcher(repo Repository, taxonomy TaxonomyProvider, config cfg.GroupConfig) GroupMatcher {
+	var content GroupMatcher = NewPostgresMatcher(repo, taxonomy)
+	if config.MemberProfileWeight > 0 {
+		content = NewMemberProfileMatcher(content, repo, config.MemberProfileWeight)
+	}
+
+	return NewRankedMatcher(
+		NewBlendedMatcher(
+			WeightedMatcher{Matcher: content, Weight: 1 - config.RecommendWeight},
+			WeightedMatcher{Matcher: NewCollaborativeMatcher(repo, taxonomy), Weight: config.RecommendWeight},
+		),
+		NewRankingStages(config.Ranking)...,
+	)
+}
+
+// armKey identifies an experiment arm and its params. Params are hashed in
+// so pipelines and cached pages of an edited arm are not reused.
+func armKey(assignment experiment.Assignment) string {
+	sum := sha256.Sum256(assignment.Params)
+	return assignment.Experiment + "/" + assignment.Arm + "/" + hex.EncodeToString(sum[:8])
+}
+
+// ExperimentMatcher implements GroupMatcher by serving each user the discover
+// pipeline of their experiment arm. Users outside an experiment, and arms
+// with invalid params, get the configured pipeline.
+type ExperimentMatcher struct {
+	repo        Repository
+	taxonomy    TaxonomyProvider
+	config      cfg.GroupConfig
+	experiments ExperimentTracker
+	logger      logger.Logger
+
+	fallback GroupMatcher
+
+	mu   sync.Mutex
+	arms map[string]GroupMatcher // by experiment, arm and params
+}
+
+func NewExperimentMatcher(repo Repository, taxonomy TaxonomyProvider, config cfg.GroupConfig, experiments ExperimentTracker, logger logger.Logger) *ExperimentMatcher {
+	return &ExperimentMatcher{
+		repo:        repo,
+		taxonomy:    taxonomy,
+		config:      config,
+		experiments: experiments,
+		logger:      logger,
+		fallback:    NewDiscoverMatcher(repo, taxonomy, config),
+		arms:        make(map[string]GroupMatcher),
+	}
+}
+
+func (m *ExperimentMatcher) FindMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	return m.matcherFor(ctx, userProfile.UserID).FindMatches(ctx, userProfile, filters)
+}
+
+func (m *ExperimentMatcher) matcherFor(ctx context.Context, userID string) GroupMatcher {
+	assignment, ok := m.experiments.Assign(userID)
+	if !ok || len(assignment.Params) == 0 {
+		return m.fallback
+	}
+
+	key := armKey(assignment)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if matcher, ok := m.arms[key]; ok {
+		return matcher
+	}
+
+	var overrides MatcherOverrides
+	config, err := m.config, json.Unmarshal(assignment.Params, &overrides)
+	if err == nil {
+		config, err = overrides.Apply(m.config)
+	}
+	if err != nil {
+		m.logger.Warn(ctx, "invalid experiment arm params, using default matcher",
+			logger.Field{Key: "experiment", Value: assignment.Experiment},
+			logger.Field{Key: "arm", Value: assignment.Arm},
+			logger.Field{Key: "error", Value: err},
+		)
+		m.arms[key] = m.fallback
+		return m.fallback
+	}
+
+	matcher := NewDiscoverMatcher(m.repo, m.taxonomy, config)
+	m.arms[key] = matcher
+	return matcher
+}
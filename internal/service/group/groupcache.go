@@ -0,0 +1,104 @@
+package group
+
+import (
+	"context"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var groupCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "bmatch_group_cache_requests_total",
+	Help: "Group cache lookups by cached entity and result (hit, miss, error).",
+}, []string{"entity", "result"})
+
+// GroupCache is a read-through cache of group details and member lists.
+// Concurrent misses for a key share one load in this instance, and a
+// short fill lock keeps other instances from loading it at the same time.
+//
+// Writers invalidate after their transaction commits. A read that loaded
+// before the commit can still store the old value; the TTL bounds how long
+// it is served.
+type GroupCache struct {
+	cache   cache.Cache
+	ttl     time.Duration
+	groups  *cache.Loader
+	members *cache.Loader
+	logger  logger.Logger
+}
+
+func NewGroupCache(store cache.Cache, config cfg.GroupConfig, logger logger.Logger) *GroupCache {
+	c := &GroupCache{
+		cache:  store,
+		ttl:    config.CacheTTL,
+		logger: logger,
+	}
+	c.groups = cache.NewLoader(store, c.loaderOptions("group"))
+	c.members = cache.NewLoader(store, c.loaderOptions("members"))
+	return c
+}
+
+func (c *GroupCache) loaderOptions(entity string) cache.LoaderOptions {
+	return cache.LoaderOptions{
+		LockTTL:  groupCacheLockTTL,
+		LockWait: groupCacheLockWait,
+		LockPoll: groupCacheLockPoll,
+		OnLookup: func(result string) {
+			groupCacheRequests.WithLabelValues(entity, result).Inc()
+		},
+		OnError: func(ctx context.Context, key string, err error) {
+			c.logger.Warn(ctx, "group cache failed",
+				logger.Field{Key: "key", Value: key},
+				logger.Field{Key: "error", Value: err},
+			)
+		},
+	}
+}
+
+// Group returns the cached group, loading it on a miss
+func (c *GroupCache) Group(ctx context.Context, groupID string, load func(ctx context.Context) (*Group, error)) (*Group, error) {
+	if c.ttl <= 0 {
+		return load(ctx)
+	}
+	return cache.GetOrLoad(ctx, c.groups, groupCacheKey(groupID), c.ttl, load)
+}
+
+// Members returns the cached member list, loading it on a miss
+func (c *GroupCache) Members(ctx context.Context, groupID string, load func(ctx context.Context) ([]*GroupMember, error)) ([]*GroupMember, error) {
+	if c.ttl <= 0 {
+		return load(ctx)
+	}
+	return cache.GetOrLoad(ctx, c.members, groupMembersCacheKey(groupID), c.ttl, load)
+}
+
+// InvalidateGroup drops the cached group after it changed
+func (c *GroupCache) InvalidateGroup(ctx context.Context, groupID string) {
+	c.del(ctx, groupCacheKey(groupID))
+}
+
+// InvalidateMembers drops the cached member list after members joined or left
+func (c *GroupCache) InvalidateMembers(ctx context.Context, groupID string) {
+	c.del(ctx, groupMembersCacheKey(groupID))
+}
+
+func (c *GroupCache) del(ctx context.Context, key string) {
+	if err := c.cache.Del(ctx, key); err != nil {
+		c.logger.Warn(ctx, "failed to invalidate group cache",
+			logger.Field{Key: "key", Value: key},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+func groupCacheKey(groupID string) string {
+	return groupCacheKeyPrefix + groupID
+}
+
+func groupMembersCacheKey(groupID string) string {
+	return groupCacheKeyPrefix + groupID + ":members"
+}
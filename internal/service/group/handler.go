@@ -2,6 +2,7 @@ package group
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"strings"
 
@@ -52,7 +53,16 @@ func (h *Handler) JoinGroup(c *gin.Context) {
 		return
 	}
 
-	err := h.service.JoinGroup(c.Request.Context(), groupID, userID.(string))
+	// The body is optional; without a slot the user takes an unreserved seat
+	var req JoinSlotRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	err := h.service.JoinGroup(c.Request.Context(), groupID, userID.(string), req.Slot)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -77,7 +87,7 @@ func (h *Handler) ApplyToGroup(c *gin.Context) {
 		return
 	}
 
-	err := h.service.ApplyToGroup(c.Request.Context(), groupID, userID.(string), req.Pitch)
+	err := h.service.ApplyToGroup(c.Request.Context(), groupID, userID.(string), req.Pitch, req.Slot)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -131,6 +141,9 @@ func (h *Handler) GetGroup(c *gin.Context) {
 
 	// Get current user ID (optional, might not be authenticated)
 	userID, _ := c.Get("user_id")
+	if userID != nil {
+		h.service.TrackGroupView(c.Request.Context(), groupID, userID.(string))
+	}
 
 	// Get members
 	members, err := h.service.GetGroupMembers(c.Request.Context(), groupID)
@@ -149,6 +162,7 @@ func (h *Handler) GetGroup(c *gin.Context) {
 		response.Members[i] = GroupMemberResponse{
 			UserID:   member.UserID,
 			Role:     member.Role,
+			Slot:     member.Slot,
 			JoinedAt: member.JoinedAt,
 		}
 
@@ -242,7 +256,289 @@ func (h *Handler) GetMyGroups(c *gin.Context) {
 	})
 }
 
+// GetCandidates handles GET /api/v1/groups/:id/candidates
+func (h *Handler) GetCandidates(c *gin.Context) {
+	groupID := c.Param("id")
+
+	var req FindCandidatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	candidates, err := h.service.FindCandidates(c.Request.Context(), groupID, ownerID.(string), req.Limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CandidatesResponse{
+		Candidates: candidates,
+		Total:      len(candidates),
+	})
+}
+
+// GetGroupProfile handles GET /api/v1/groups/:id/profile
+func (h *Handler) GetGroupProfile(c *gin.Context) {
+	groupID := c.Param("id")
+
+	ownerID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	profile, err := h.service.GetGroupProfile(c.Request.Context(), groupID, ownerID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// InviteUser handles POST /api/v1/groups/:id/invites
+func (h *Handler) InviteUser(c *gin.Context) {
+	groupID := c.Param("id")
+
+	var req InviteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invite, err := h.service.InviteUser(c.Request.Context(), groupID, ownerID.(string), req.UserID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+// RespondToInvite handles POST /api/v1/groups/:id/invites/respond
+func (h *Handler) RespondToInvite(c *gin.Context) {
+	groupID := c.Param("id")
+
+	var req RespondToInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	err := h.service.RespondToInvite(c.Request.Context(), groupID, userID.(string), *req.Accept, req.Slot)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	status := "declined"
+	if *req.Accept {
+		status = "accepted"
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invite " + status})
+}
+
+// GetMyInvites handles GET /api/v1/my-invites
+func (h *Handler) GetMyInvites(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	invites, err := h.service.GetUserInvites(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invites": invites,
+		"total":   len(invites),
+	})
+}
+
 // handleError maps domain errors to HTTP status codes
+// LeaveGroup handles POST /groups/:id/leave
+func (h *Handler) LeaveGroup(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.LeaveGroup(c.Request.Context(), groupID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "successfully left group"})
+}
+
+// BookmarkGroup handles POST /groups/:id/bookmark
+func (h *Handler) BookmarkGroup(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.BookmarkGroup(c.Request.Context(), groupID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "group bookmarked"})
+}
+
+// UnbookmarkGroup handles DELETE /groups/:id/bookmark
+func (h *Handler) UnbookmarkGroup(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.UnbookmarkGroup(c.Request.Context(), groupID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "bookmark removed"})
+}
+
+// GetMyBookmarks handles GET /my-bookmarks
+func (h *Handler) GetMyBookmarks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	bookmarks, err := h.service.GetBookmarks(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bookmarks": bookmarks,
+		"total":     len(bookmarks),
+	})
+}
+
+// CreateSavedSearch handles POST /saved-searches
+func (h *Handler) CreateSavedSearch(c *gin.Context) {
+	var req CreateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	search, err := h.service.CreateSavedSearch(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// GetSavedSearches handles GET /saved-searches
+func (h *Handler) GetSavedSearches(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	searches, err := h.service.GetSavedSearches(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SavedSearchesResponse{
+		SavedSearches: searches,
+		Total:         len(searches),
+	})
+}
+
+// UpdateSavedSearch handles PATCH /saved-searches/:id
+func (h *Handler) UpdateSavedSearch(c *gin.Context) {
+	searchID := c.Param("id")
+
+	var req UpdateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	search, err := h.service.UpdateSavedSearch(c.Request.Context(), searchID, userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// DeleteSavedSearch handles DELETE /saved-searches/:id
+func (h *Handler) DeleteSavedSearch(c *gin.Context) {
+	searchID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.service.DeleteSavedSearch(c.Request.Context(), searchID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "saved search deleted"})
+}
+
 func (h *Handler) handleError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, ErrGroupNotFound):
@@ -251,6 +547,10 @@ func (h *Handler) handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 	case errors.Is(err, ErrAlreadyMember):
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrCannotLeaveAsOwner):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	case errors.Is(err, ErrNotGroupOwner):
 		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 	case errors.Is(err, ErrGroupNotOpen):
@@ -265,6 +565,304 @@ func (h *Handler) handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	case errors.Is(err, ErrInvalidJoinType):
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTags):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrScheduleRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidLocation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTimeZone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrLocationRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrOriginRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTimeZoneRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidLanguage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrLanguagesRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrLanguageRequirementNotMet):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidRoleSlots):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSlotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSlotFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSlotTagsNotMet):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSeatsReserved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInviteExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInviteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUserNotInvitable):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrBookmarkNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSavedSearchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidSavedSearch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTooManySavedSearches):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+type AutoFormHandler struct {
+	former *AutoFormer
+}
+
+func NewAutoFormHandler(former *AutoFormer) *AutoFormHandler {
+	return &AutoFormHandler{
+		former: former,
+	}
+}
+
+// AutoForm clusters opted-in users into new groups; the body is optional
+func (h *AutoFormHandler) AutoForm(c *gin.Context) {
+	var req AutoFormRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.former.Run(c.Request.Context(), req)
+	if errors.Is(err, ErrAutoFormRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type QueueHandler struct {
+	matchmaker *Matchmaker
+}
+
+func NewQueueHandler(matchmaker *Matchmaker) *QueueHandler {
+	return &QueueHandler{
+		matchmaker: matchmaker,
+	}
+}
+
+// EnterQueue handles POST /queue
+func (h *QueueHandler) EnterQueue(c *gin.Context) {
+	var req EnterQueueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status, err := h.matchmaker.Enter(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, status)
+}
+
+// LeaveQueue handles DELETE /queue
+func (h *QueueHandler) LeaveQueue(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.matchmaker.Leave(c.Request.Context(), userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "left matchmaking queue"})
+}
+
+// GetQueueStatus handles GET /queue
+func (h *QueueHandler) GetQueueStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status, err := h.matchmaker.Status(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// RespondToProposal handles POST /queue/proposals/:id/respond
+func (h *QueueHandler) RespondToProposal(c *gin.Context) {
+	proposalID := c.Param("id")
+
+	var req RespondToProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	proposal, err := h.matchmaker.Respond(c.Request.Context(), userID.(string), proposalID, *req.Accept)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, proposal)
+}
+
+func (h *QueueHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTags):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAlreadyQueued):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotQueued):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrProposalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrQueueBusy):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
+
+type BuddyHandler struct {
+	buddies *BuddyService
+}
+
+func NewBuddyHandler(buddies *BuddyService) *BuddyHandler {
+	return &BuddyHandler{
+		buddies: buddies,
+	}
+}
+
+// GetSuggestions handles GET /buddies/suggestions
+func (h *BuddyHandler) GetSuggestions(c *gin.Context) {
+	var req BuddySuggestionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	suggestions, err := h.buddies.Suggestions(c.Request.Context(), userID.(string), req.Limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BuddySuggestionsResponse{
+		Suggestions: suggestions,
+		Total:       len(suggestions),
+	})
+}
+
+// ExpressInterest handles POST /buddies/:user_id/interest
+func (h *BuddyHandler) ExpressInterest(c *gin.Context) {
+	targetID := c.Param("user_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	match, err := h.buddies.ExpressInterest(c.Request.Context(), userID.(string), targetID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BuddyInterestResponse{
+		Matched: match != nil,
+		Match:   match,
+	})
+}
+
+// Pass handles POST /buddies/:user_id/pass
+func (h *BuddyHandler) Pass(c *gin.Context) {
+	targetID := c.Param("user_id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.buddies.Pass(c.Request.Context(), userID.(string), targetID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user passed"})
+}
+
+// GetMatches handles GET /buddies/matches
+func (h *BuddyHandler) GetMatches(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	matches, err := h.buddies.Matches(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BuddyMatchesResponse{
+		Matches: matches,
+		Total:   len(matches),
+	})
+}
+
+func (h *BuddyHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrBuddySelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrBuddyUnavailable), errors.Is(err, ErrBuddyModeOff):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAlreadyBuddies), errors.Is(err, ErrBuddyConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 	}
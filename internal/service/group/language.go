@@ -0,0 +1,74 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bmatch/pkg/language"
+)
+
+// resolveGroupLanguage validates a new group's primary language and the
+// proficiency required of its members
+func resolveGroupLanguage(req CreateGroupRequest) (code, required string, err error) {
+	if req.Language == "" {
+		if req.RequiredProficiency != "" {
+			return "", "", fmt.Errorf("%w: a required proficiency needs a language", ErrInvalidLanguage)
+		}
+		return "", "", nil
+	}
+
+	code, err = language.NormalizeCode(req.Language)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidLanguage, err)
+	}
+
+	return code, req.RequiredProficiency, nil
+}
+
+// resolveLanguages sets the languages discover matches on: query languages
+// when given, read as fluent, otherwise the caller's profile languages
+func resolveLanguages(userProfile *UserProfile, filters *DiscoverGroupsRequest) error {
+	if len(filters.Languages) > 0 {
+		spoken := make([]language.Spoken, 0, len(filters.Languages))
+		for _, value := range filters.Languages {
+			for _, c := range strings.Split(value, ",") {
+				if strings.TrimSpace(c) == "" {
+					continue
+				}
+				code, err := language.NormalizeCode(c)
+				if err != nil {
+					return fmt.Errorf("%w: %v", ErrInvalidLanguage, err)
+				}
+				spoken = append(spoken, language.Spoken{Code: code, Proficiency: language.Fluent})
+			}
+		}
+		userProfile.Languages = spoken
+	}
+
+	filters.Languages = language.Codes(userProfile.Languages)
+	if filters.LanguageMode == LanguageModeFilter && len(filters.Languages) == 0 {
+		return ErrLanguagesRequired
+	}
+
+	return nil
+}
+
+// checkLanguageRequirement screens a user joining or applying to a group
+// that requires a language
+func (s *Service) checkLanguageRequirement(ctx context.Context, group *Group, userID string) error {
+	if group.RequiredProficiency == "" {
+		return nil
+	}
+
+	profile, err := s.repo.GetUserProfile(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user profile: %w", err)
+	}
+
+	if !language.Speaks(profile.Languages, group.Language, group.RequiredProficiency) {
+		return ErrLanguageRequirementNotMet
+	}
+
+	return nil
+}
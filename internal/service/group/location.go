@@ -0,0 +1,136 @@
+package group
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bmatch/pkg/availability"
+	"bmatch/pkg/geo"
+)
+
+// groupLocation is the validated meeting mode, place and time zone of a new group
+type groupLocation struct {
+	meetingMode string
+	location    *geo.Point
+	city        string
+	timeZone    string
+}
+
+// resolveGroupLocation validates where and in which time zone a group meets.
+// The meeting mode defaults to online and the time zone to the schedule's.
+func resolveGroupLocation(req CreateGroupRequest, schedule availability.Schedule) (groupLocation, error) {
+	loc := groupLocation{
+		meetingMode: req.MeetingMode,
+		location:    req.Location,
+		city:        strings.TrimSpace(req.City),
+		timeZone:    req.TimeZone,
+	}
+
+	if loc.meetingMode == "" {
+		loc.meetingMode = MeetingModeOnline
+	}
+
+	if loc.location != nil {
+		if err := loc.location.Validate(); err != nil {
+			return loc, fmt.Errorf("%w: %v", ErrInvalidLocation, err)
+		}
+	}
+	if loc.meetingMode == MeetingModeInPerson && loc.location == nil && loc.city == "" {
+		return loc, ErrLocationRequired
+	}
+
+	if loc.timeZone == "" && !schedule.IsZero() {
+		loc.timeZone = schedule.TimeZone
+	}
+	if err := availability.ValidateTimeZone(loc.timeZone); err != nil {
+		return loc, fmt.Errorf("%w: %v", ErrInvalidTimeZone, err)
+	}
+
+	return loc, nil
+}
+
+// locationFilter is a discover request's location and time zone filters
+// resolved against the caller's profile
+type locationFilter struct {
+	origin      *geo.Point
+	radiusKm    float64
+	city        string
+	timeZone    string
+	maxTZOffset *time.Duration
+}
+
+// newLocationFilter resolves the filters, preferring query parameters over
+// the caller's profile. The resolved origin and time zone are written back to
+// filters so the repository can apply the same filters before its LIMIT.
+func newLocationFilter(userProfile UserProfile, filters *DiscoverGroupsRequest) (locationFilter, error) {
+	f := locationFilter{
+		origin:   userProfile.Location,
+		radiusKm: filters.RadiusKm,
+		city:     strings.TrimSpace(filters.City),
+		timeZone: userProfile.TimeZone,
+	}
+
+	if filters.Latitude != nil && filters.Longitude != nil {
+		f.origin = &geo.Point{Lat: *filters.Latitude, Lon: *filters.Longitude}
+	}
+	if f.radiusKm > 0 && f.origin == nil {
+		return f, ErrOriginRequired
+	}
+
+	if filters.TimeZone != "" {
+		if err := availability.ValidateTimeZone(filters.TimeZone); err != nil {
+			return f, fmt.Errorf("%w: %v", ErrInvalidTimeZone, err)
+		}
+		f.timeZone = filters.TimeZone
+	}
+	if filters.MaxTZOffsetHours != nil {
+		if f.timeZone == "" {
+			return f, ErrTimeZoneRequired
+		}
+		maxOffset := time.Duration(*filters.MaxTZOffsetHours * float64(time.Hour))
+		f.maxTZOffset = &maxOffset
+	}
+
+	if f.origin != nil {
+		filters.Latitude, filters.Longitude = &f.origin.Lat, &f.origin.Lon
+	}
+	filters.TimeZone = f.timeZone
+
+	return f, nil
+}
+
+// apply drops in-person groups outside the radius or city and groups whose
+// time zone is too far off, and annotates matches with their distance.
+// Online and hybrid groups are never dropped for their location; groups
+// without a time zone count as UTC, as they do when created. The repository
+// applies the same rules in SQL, see locationConditions.
+func (f locationFilter) apply(matches []GroupMatch, now time.Time) []GroupMatch {
+	filtered := make([]GroupMatch, 0, len(matches))
+	for _, m := range matches {
+		g := m.Group
+		inPerson := g.MeetingMode == MeetingModeInPerson
+
+		if f.origin != nil && g.Location != nil && g.MeetingMode != MeetingModeOnline {
+			distance := geo.Distance(*f.origin, *g.Location)
+			m.DistanceKm = &distance
+		}
+
+		if inPerson && f.radiusKm > 0 && (m.DistanceKm == nil || *m.DistanceKm > f.radiusKm) {
+			continue
+		}
+		if inPerson && f.city != "" && !strings.EqualFold(g.City, f.city) {
+			continue
+		}
+
+		if f.maxTZOffset != nil {
+			diff, err := availability.OffsetDifference(f.timeZone, g.TimeZone, now)
+			if err != nil || diff > *f.maxTZOffset {
+				continue
+			}
+		}
+
+		filtered = append(filtered, m)
+	}
+	return filtered
+}
@@ -0,0 +1,190 @@
+package group
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"bmatch/pkg/geo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	berlin = geo.Point{Lat: 52.52, Lon: 13.405}
+	paris  = geo.Point{Lat: 48.8566, Lon: 2.3522}
+	tokyo  = geo.Point{Lat: 35.6762, Lon: 139.6503}
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestNewLocationFilter(t *testing.T) {
+	profile := UserProfile{Location: &berlin, TimeZone: "Europe/Berlin"}
+
+	cases := map[string]struct {
+		profile  UserProfile
+		filters  DiscoverGroupsRequest
+		err      error
+		origin   *geo.Point
+		timeZone string
+	}{
+		"profile defaults": {
+			profile:  profile,
+			filters:  DiscoverGroupsRequest{RadiusKm: 10},
+			origin:   &berlin,
+			timeZone: "Europe/Berlin",
+		},
+		"query wins over profile": {
+			profile:  profile,
+			filters:  DiscoverGroupsRequest{RadiusKm: 10, Latitude: float(paris.Lat), Longitude: float(paris.Lon), TimeZone: "Europe/Paris"},
+			origin:   &paris,
+			timeZone: "Europe/Paris",
+		},
+		"radius without origin": {
+			filters: DiscoverGroupsRequest{RadiusKm: 10},
+			err:     ErrOriginRequired,
+		},
+		"invalid time zone": {
+			profile: profile,
+			filters: DiscoverGroupsRequest{TimeZone: "Mars/Olympus"},
+			err:     ErrInvalidTimeZone,
+		},
+		"offset without time zone": {
+			filters: DiscoverGroupsRequest{MaxTZOffsetHours: float(2)},
+			err:     ErrTimeZoneRequired,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, err := newLocationFilter(tc.profile, &tc.filters)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.origin, f.origin)
+			assert.Equal(t, tc.timeZone, f.timeZone)
+
+			// Resolved values are written back for the repository
+			require.NotNil(t, tc.filters.Latitude)
+			assert.Equal(t, tc.origin.Lat, *tc.filters.Latitude)
+			assert.Equal(t, tc.origin.Lon, *tc.filters.Longitude)
+			assert.Equal(t, tc.timeZone, tc.filters.TimeZone)
+		})
+	}
+}
+
+func TestLocationFilter_Apply(t *testing.T) {
+	groups := []*Group{
+		{ID: "berlin", MeetingMode: MeetingModeInPerson, Location: &berlin, City: "Berlin", TimeZone: "Europe/Berlin"},
+		{ID: "paris", MeetingMode: MeetingModeInPerson, Location: &paris, City: "Paris", TimeZone: "Europe/Paris"},
+		{ID: "city-only", MeetingMode: MeetingModeInPerson, City: "berlin", TimeZone: "Europe/Berlin"},
+		{ID: "online", MeetingMode: MeetingModeOnline, Location: &berlin, TimeZone: "America/New_York"},
+		{ID: "hybrid", MeetingMode: MeetingModeHybrid, Location: &tokyo, TimeZone: "Asia/Tokyo"},
+		{ID: "no-zone", MeetingMode: MeetingModeOnline},
+	}
+
+	cases := map[string]struct {
+		filters DiscoverGroupsRequest
+		want    []string
+	}{
+		"no filters": {
+			want: []string{"berlin", "paris", "city-only", "online", "hybrid", "no-zone"},
+		},
+		"radius drops distant and unplaced in-person groups": {
+			filters: DiscoverGroupsRequest{RadiusKm: 50},
+			want:    []string{"berlin", "online", "hybrid", "no-zone"},
+		},
+		"city ignores case": {
+			filters: DiscoverGroupsRequest{City: "BERLIN"},
+			want:    []string{"berlin", "city-only", "online", "hybrid", "no-zone"},
+		},
+		"time zone offset treats missing zones as UTC": {
+			filters: DiscoverGroupsRequest{TimeZone: "Europe/London", MaxTZOffsetHours: float(1)},
+			want:    []string{"berlin", "paris", "city-only", "no-zone"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			matches := make([]GroupMatch, len(groups))
+			for i, g := range groups {
+				matches[i] = GroupMatch{Group: g}
+			}
+
+			f, err := newLocationFilter(UserProfile{Location: &berlin}, &tc.filters)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, matchIDs(f.apply(matches, winter)))
+		})
+	}
+
+	t.Run("annotates distance of placed groups", func(t *testing.T) {
+		f, err := newLocationFilter(UserProfile{Location: &berlin}, &DiscoverGroupsRequest{})
+		require.NoError(t, err)
+
+		distances := make(map[string]*float64)
+		for _, m := range f.apply([]GroupMatch{{Group: groups[0]}, {Group: groups[3]}, {Group: groups[4]}}, winter) {
+			distances[m.Group.ID] = m.DistanceKm
+		}
+
+		require.NotNil(t, distances["berlin"])
+		assert.Zero(t, *distances["berlin"])
+		assert.Nil(t, distances["online"])
+		require.NotNil(t, distances["hybrid"])
+		assert.InDelta(t, geo.Distance(berlin, tokyo), *distances["hybrid"], 1e-9)
+	})
+
+	t.Run("offset follows daylight saving", func(t *testing.T) {
+		filters := DiscoverGroupsRequest{TimeZone: "America/New_York", MaxTZOffsetHours: float(5)}
+		f, err := newLocationFilter(UserProfile{}, &filters)
+		require.NoError(t, err)
+
+		// Berlin and New York are 6 hours apart in winter, but only 5 for
+		// the weeks in March when the US has switched and Europe has not
+		march := time.Date(2025, time.March, 20, 12, 0, 0, 0, time.UTC)
+		assert.Empty(t, f.apply([]GroupMatch{{Group: groups[0]}}, winter))
+		assert.Len(t, f.apply([]GroupMatch{{Group: groups[0]}}, march), 1)
+	})
+}
+
+func TestLocationConditions(t *testing.T) {
+	cases := map[string]struct {
+		filters DiscoverGroupsRequest
+		args    int
+	}{
+		"none":      {filters: DiscoverGroupsRequest{}, args: 0},
+		"radius":    {filters: DiscoverGroupsRequest{RadiusKm: 10, Latitude: float(berlin.Lat), Longitude: float(berlin.Lon)}, args: 7},
+		"city":      {filters: DiscoverGroupsRequest{City: " Berlin "}, args: 1},
+		"time zone": {filters: DiscoverGroupsRequest{TimeZone: "Europe/Berlin", MaxTZOffsetHours: float(2)}, args: 2},
+		"all": {
+			filters: DiscoverGroupsRequest{
+				RadiusKm: 10, Latitude: float(berlin.Lat), Longitude: float(berlin.Lon),
+				City: "Berlin", TimeZone: "Europe/Berlin", MaxTZOffsetHours: float(2),
+			},
+			args: 10,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			conds, args, next := locationConditions(tc.filters, "g.", 3)
+
+			assert.Len(t, args, tc.args)
+			assert.Equal(t, 3+tc.args, next)
+			for i := 3; i < next; i++ {
+				assert.Contains(t, conds, fmt.Sprintf("$%d", i))
+			}
+			assert.NotContains(t, conds, fmt.Sprintf("$%d", next))
+			if tc.args > 0 {
+				assert.True(t, strings.HasPrefix(strings.TrimSpace(conds), "AND"))
+			}
+		})
+	}
+}
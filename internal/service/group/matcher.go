@@ -2,6 +2,14 @@ package group
 
 import (
 	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"bmatch/pkg/availability"
+	"bmatch/pkg/language"
+	"bmatch/pkg/taxonomy"
 )
 
 // GroupMatcher defines the interface for finding matching groups
@@ -9,32 +17,64 @@ type GroupMatcher interface {
 	FindMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error)
 }
 
+// CandidateMatcher defines the interface for ranking users for a group
+type CandidateMatcher interface {
+	FindCandidates(ctx context.Context, group *Group, limit int) ([]CandidateMatch, error)
+}
+
+// TaxonomyProvider supplies the tag hierarchy used for partial-credit matching
+type TaxonomyProvider interface {
+	Taxonomy(ctx context.Context) (*taxonomy.Taxonomy, error)
+}
+
 // PostgresMatcher implements GroupMatcher using PostgreSQL with GIN indexes
 type PostgresMatcher struct {
-	repo Repository
+	repo     Repository
+	taxonomy TaxonomyProvider
 }
 
-func NewPostgresMatcher(repo Repository) *PostgresMatcher {
+func NewPostgresMatcher(repo Repository, taxonomy TaxonomyProvider) *PostgresMatcher {
 	return &PostgresMatcher{
-		repo: repo,
+		repo:     repo,
+		taxonomy: taxonomy,
 	}
 }
 
-// FindMatches finds groups matching user profile and calculates Jaccard similarity
+// FindMatches finds groups matching user profile and calculates Jaccard similarity,
+// blended with full-text relevance when a search query is given
 func (m *PostgresMatcher) FindMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	tax, err := m.taxonomy.Taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get candidate groups from database using GIN index
-	groups, err := m.repo.FindGroupsByTags(ctx, userProfile.Tags, filters)
+	candidates, err := m.repo.FindGroupsByTags(ctx, userProfile.UserID, userProfile.Tags, filters)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate Jaccard similarity for each group
-	matches := make([]GroupMatch, 0, len(groups))
-	for _, group := range groups {
-		score := CalculateJaccardScore(userProfile.Tags, group.Tags)
+	// Widen to related tags when exact matches are scarce
+	if filters.Expand && len(userProfile.Tags) > 0 && len(candidates) < expandMinResults {
+		widened := expandTags(userProfile.Tags, tax)
+		if len(widened) > len(userProfile.Tags) {
+			related, err := m.repo.FindGroupsByTags(ctx, userProfile.UserID, widened, filters)
+			if err != nil {
+				return nil, err
+			}
+			candidates = mergeCandidates(candidates, related)
+		}
+	}
+
+	hasQuery := strings.TrimSpace(filters.Query) != ""
+	now := time.Now()
+
+	// Calculate similarity for each group
+	matches := make([]GroupMatch, 0, len(candidates))
+	for _, c := range candidates {
 		matches = append(matches, GroupMatch{
-			Group:           group,
-			SimilarityScore: score,
+			Group:           c.Group,
+			SimilarityScore: scoreCandidate(userProfile, c, hasQuery, tax, now),
 		})
 	}
 
@@ -44,6 +84,231 @@ func (m *PostgresMatcher) FindMatches(ctx context.Context, userProfile UserProfi
 	return matches, nil
 }
 
+// scoreCandidate scores a group for a user: tag similarity, blended with
+// text relevance, schedule coverage, language fit and open role slots
+func scoreCandidate(userProfile UserProfile, c *GroupCandidate, hasQuery bool, tax *taxonomy.Taxonomy, now time.Time) float64 {
+	score := CalculateTaxonomyScore(userProfile.Tags, c.Group.Tags, tax)
+	if hasQuery {
+		score = blendTextRank(score, c.TextRank, len(userProfile.Tags) > 0)
+	}
+	if !userProfile.Schedule.IsZero() && !c.Group.Schedule.IsZero() {
+		coverage := availability.Coverage(userProfile.Schedule, c.Group.Schedule, now)
+		score = (1-discoverAvailabilityWeight)*score + discoverAvailabilityWeight*coverage
+	}
+	if len(userProfile.Languages) > 0 && c.Group.Language != "" {
+		fit := language.Score(userProfile.Languages, c.Group.Language)
+		score = (1-discoverLanguageWeight)*score + discoverLanguageWeight*fit
+	}
+	if fit, open := slotFit(userProfile.Tags, c.Group, tax); open && len(userProfile.Tags) > 0 {
+		score = (1-discoverSlotWeight)*score + discoverSlotWeight*fit
+	}
+	return score
+}
+
+// WeightedMatcher is one strategy of a BlendedMatcher
+type WeightedMatcher struct {
+	Matcher GroupMatcher
+	Weight  float64
+}
+
+// BlendedMatcher implements GroupMatcher by combining several strategies.
+// A group's score is the weighted mean of its scores, counting 0 for
+// strategies that did not return it. Strategies returning nothing, such as
+// collaborative filtering for anonymous users, are left out of the mean.
+type BlendedMatcher struct {
+	strategies []WeightedMatcher
+}
+
+func NewBlendedMatcher(strategies ...WeightedMatcher) *BlendedMatcher {
+	return &BlendedMatcher{
+		strategies: strategies,
+	}
+}
+
+func (m *BlendedMatcher) FindMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	results := make([][]GroupMatch, len(m.strategies))
+	totalWeight := 0.0
+	for i, s := range m.strategies {
+		if s.Weight <= 0 {
+			continue
+		}
+
+		matches, err := s.Matcher.FindMatches(ctx, userProfile, filters)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) > 0 {
+			results[i] = matches
+			totalWeight += s.Weight
+		}
+	}
+
+	scores := make(map[string]float64)
+	blended := make([]GroupMatch, 0)
+	for i, matches := range results {
+		for _, match := range matches {
+			if _, seen := scores[match.Group.ID]; !seen {
+				blended = append(blended, GroupMatch{Group: match.Group})
+			}
+			scores[match.Group.ID] += m.strategies[i].Weight * match.SimilarityScore / totalWeight
+		}
+	}
+
+	for i := range blended {
+		blended[i].SimilarityScore = scores[blended[i].Group.ID]
+	}
+
+	sort.SliceStable(blended, func(i, j int) bool {
+		return blended[i].SimilarityScore > blended[j].SimilarityScore
+	})
+
+	limit := filters.Limit
+	if limit == 0 {
+		limit = 50
+	}
+	if len(blended) > limit {
+		blended = blended[:limit]
+	}
+
+	return blended, nil
+}
+
+// FindCandidates ranks users open to invites by how well they fit the group's
+// tags and its current members' skill level, availability and intent
+func (m *PostgresMatcher) FindCandidates(ctx context.Context, group *Group, limit int) ([]CandidateMatch, error) {
+	tax, err := m.taxonomy.Taxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := m.repo.GetMemberProfiles(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := m.repo.FindCandidateUsers(ctx, group, candidatePoolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]CandidateMatch, 0, len(users))
+	for _, u := range users {
+		matches = append(matches, CandidateMatch{
+			User:            u,
+			SimilarityScore: CalculateCandidateScore(group, members, u, tax),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].SimilarityScore > matches[j].SimilarityScore
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// CalculateCandidateScore combines tag similarity to the group with how well
+// the candidate fits the members: skill level distance, availability overlap
+// and the share of members with the same intent
+func CalculateCandidateScore(group *Group, members []*UserProfile, candidate *UserProfile, tax *taxonomy.Taxonomy) float64 {
+	score := candidateTagWeight * CalculateTaxonomyScore(candidate.Tags, group.Tags, tax)
+	if len(members) == 0 {
+		return score
+	}
+
+	skillSum := 0
+	sameIntent := 0
+	for _, m := range members {
+		skillSum += skillRank(m.SkillLevel)
+		if m.Intent == candidate.Intent {
+			sameIntent++
+		}
+	}
+
+	// Ranks span 0..2, so the largest possible distance is 2
+	avgSkill := float64(skillSum) / float64(len(members))
+	skillScore := 1 - math.Abs(float64(skillRank(candidate.SkillLevel))-avgSkill)/2
+
+	score += candidateSkillWeight * skillScore
+	score += candidateAvailabilityWeight * membersAvailabilityScore(candidate, members, time.Now())
+	score += candidateIntentWeight * float64(sameIntent) / float64(len(members))
+
+	return score
+}
+
+// membersAvailabilityScore is the candidate's mean schedule similarity with
+// the members who have a schedule. Without schedules on both sides it falls
+// back to Jaccard against the union of the members' legacy values.
+func membersAvailabilityScore(candidate *UserProfile, members []*UserProfile, now time.Time) float64 {
+	if !candidate.Schedule.IsZero() {
+		total, n := 0.0, 0
+		for _, m := range members {
+			if m.Schedule.IsZero() {
+				continue
+			}
+			total += availability.Similarity(candidate.Schedule, m.Schedule, now)
+			n++
+		}
+		if n > 0 {
+			return total / float64(n)
+		}
+	}
+
+	var legacy []string
+	for _, m := range members {
+		legacy = union(legacy, m.Availability)
+	}
+	return CalculateJaccardScore(candidate.Availability, legacy)
+}
+
+// availabilityScore compares two users' availability, preferring weekly
+// schedules over the legacy coarse values
+func availabilityScore(a, b *UserProfile, now time.Time) float64 {
+	if !a.Schedule.IsZero() && !b.Schedule.IsZero() {
+		return availability.Similarity(a.Schedule, b.Schedule, now)
+	}
+	return CalculateJaccardScore(a.Availability, b.Availability)
+}
+
+// filterByOverlap keeps groups whose schedule overlaps the user's by at
+// least minOverlap a week; groups without a schedule are dropped
+func filterByOverlap(matches []GroupMatch, schedule availability.Schedule, minOverlap time.Duration, now time.Time) []GroupMatch {
+	filtered := make([]GroupMatch, 0, len(matches))
+	for _, m := range matches {
+		if m.Group.Schedule.IsZero() {
+			continue
+		}
+		if availability.Overlap(schedule, m.Group.Schedule, now) >= minOverlap {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// skillRank orders skill levels, treating unknown values as beginner
+func skillRank(level string) int {
+	switch level {
+	case SkillLevelIntermediate:
+		return 1
+	case SkillLevelAdvanced:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// blendTextRank mixes tag similarity with text relevance. Without tags to
+// compare against, text relevance is the only signal.
+func blendTextRank(tagScore, textRank float64, hasTags bool) float64 {
+	if !hasTags {
+		return textRank
+	}
+	return (1-textRankWeight)*tagScore + textRankWeight*textRank
+}
+
 // CalculateJaccardScore calculates the Jaccard similarity index
 // J(A,B) = |A ∩ B| / |A ∪ B|
 func CalculateJaccardScore(userTags, groupTags []string) float64 {
@@ -61,6 +326,70 @@ func CalculateJaccardScore(userTags, groupTags []string) float64 {
 	return float64(len(intersection)) / float64(len(union))
 }
 
+// CalculateTaxonomyScore generalizes Jaccard similarity with partial credit
+// for related tags. Each user tag earns taxonomyDecay^d for its closest group
+// tag at taxonomy distance d (1 for an exact match), and the summed credit I
+// replaces the intersection size: I / (|A| + |B| - I). With no related tags
+// this is exactly the Jaccard index.
+func CalculateTaxonomyScore(userTags, groupTags []string, tax *taxonomy.Taxonomy) float64 {
+	if tax == nil {
+		return CalculateJaccardScore(userTags, groupTags)
+	}
+
+	a := union(userTags, nil)
+	b := union(groupTags, nil)
+	if len(a) == 0 || len(b) == 0 {
+		return 0.0
+	}
+
+	credit := 0.0
+	for _, u := range a {
+		best := 0.0
+		for _, g := range b {
+			d, ok := tax.Distance(u, g)
+			if !ok || d > taxonomyMaxDistance {
+				continue
+			}
+			if c := math.Pow(taxonomyDecay, float64(d)); c > best {
+				best = c
+			}
+		}
+		credit += best
+	}
+
+	// Several user tags may lean on the same group tag; never exceed a perfect match
+	credit = math.Min(credit, float64(min(len(a), len(b))))
+
+	return credit / (float64(len(a)+len(b)) - credit)
+}
+
+// expandTags returns tags plus every tag within taxonomyMaxDistance of them
+func expandTags(tags []string, tax *taxonomy.Taxonomy) []string {
+	expanded := union(tags, nil)
+	if tax == nil {
+		return expanded
+	}
+	for _, t := range tags {
+		expanded = union(expanded, tax.Related(t, taxonomyMaxDistance))
+	}
+	return expanded
+}
+
+// mergeCandidates appends candidates from extra that are not already in base
+func mergeCandidates(base, extra []*GroupCandidate) []*GroupCandidate {
+	seen := make(map[string]bool, len(base))
+	for _, c := range base {
+		seen[c.Group.ID] = true
+	}
+	for _, c := range extra {
+		if !seen[c.Group.ID] {
+			seen[c.Group.ID] = true
+			base = append(base, c)
+		}
+	}
+	return base
+}
+
 // intersect returns the intersection of two string slices
 func intersect(a, b []string) []string {
 	set := make(map[string]bool)
@@ -0,0 +1,68 @@
+package group
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scoredMatches(scores map[string]float64) []GroupMatch {
+	matches := make([]GroupMatch, 0, len(scores))
+	for id, score := range scores {
+		matches = append(matches, GroupMatch{Group: &Group{ID: id}, SimilarityScore: score})
+	}
+	return matches
+}
+
+func TestBlendedMatcher_FindMatches(t *testing.T) {
+	content := scoredMatches(map[string]float64{"g1": 0.8, "g2": 0.4})
+
+	cases := []struct {
+		name          string
+		collaborative map[string]float64
+		want          map[string]float64
+	}{
+		{
+			name:          "weighted mean over strategies",
+			collaborative: map[string]float64{"g1": 0.5, "g3": 1},
+			want:          map[string]float64{"g1": 0.7*0.8 + 0.3*0.5, "g2": 0.7 * 0.4, "g3": 0.3},
+		},
+		{
+			name: "empty strategies are left out",
+			want: map[string]float64{"g1": 0.8, "g2": 0.4},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewBlendedMatcher(
+				WeightedMatcher{Matcher: &stubMatcher{matches: content}, Weight: 0.7},
+				WeightedMatcher{Matcher: &stubMatcher{matches: scoredMatches(tc.collaborative)}, Weight: 0.3},
+			)
+
+			matches, err := m.FindMatches(context.Background(), UserProfile{}, DiscoverGroupsRequest{Limit: 10})
+			require.NoError(t, err)
+
+			got := make(map[string]float64, len(matches))
+			for i, match := range matches {
+				got[match.Group.ID] = match.SimilarityScore
+				if i > 0 {
+					assert.GreaterOrEqual(t, matches[i-1].SimilarityScore, match.SimilarityScore, "sorted best first")
+				}
+			}
+			require.Len(t, got, len(tc.want))
+			for id, score := range tc.want {
+				assert.InDelta(t, score, got[id], 1e-9, id)
+			}
+		})
+	}
+
+	t.Run("no results", func(t *testing.T) {
+		m := NewBlendedMatcher(WeightedMatcher{Matcher: &stubMatcher{}, Weight: 1})
+		matches, err := m.FindMatches(context.Background(), UserProfile{}, DiscoverGroupsRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+}
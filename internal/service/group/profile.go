@@ -0,0 +1,187 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bmatch/pkg/availability"
+	"bmatch/pkg/logger"
+)
+
+// BuildGroupProfile aggregates members' tags, skill levels and schedules.
+// Members without a schedule are left out of the shared schedule.
+// Schedules are kept in the members' time zones and only intersected when
+// used, so the shared time follows daylight saving changes.
+func BuildGroupProfile(groupID string, members []*UserProfile, now time.Time) *GroupProfile {
+	profile := &GroupProfile{
+		GroupID:     groupID,
+		MemberCount: len(members),
+		TagCounts:   make(map[string]int),
+		SkillLevels: make(map[string]int),
+		UpdatedAt:   now,
+	}
+
+	profile.MemberSchedules = make([]availability.Schedule, 0, len(members))
+	for _, m := range members {
+		for _, tag := range union(m.Tags, nil) {
+			profile.TagCounts[tag]++
+		}
+		if m.SkillLevel != "" {
+			profile.SkillLevels[m.SkillLevel]++
+		}
+		if !m.Schedule.IsZero() {
+			profile.MemberSchedules = append(profile.MemberSchedules, m.Schedule)
+		}
+	}
+	profile.SharedSchedule = profile.sharedSchedule(now)
+
+	return profile
+}
+
+// sharedSchedule intersects the members' schedules in the week containing now
+func (p *GroupProfile) sharedSchedule(now time.Time) availability.Schedule {
+	return availability.Intersect(now, p.MemberSchedules...)
+}
+
+// Fit scores a user against the group's members in [0, 1]: how common the
+// user's tags are among members, how close members' skill levels are to the
+// user's and how much of the members' shared time the user can attend.
+// Signals missing on either side are left out of the mean.
+func (p *GroupProfile) Fit(user UserProfile, now time.Time) float64 {
+	if p.MemberCount == 0 {
+		return 0
+	}
+
+	total, signals := 0.0, 0
+
+	if len(user.Tags) > 0 {
+		tags := union(user.Tags, nil)
+		share := 0.0
+		for _, tag := range tags {
+			share += float64(p.TagCounts[tag]) / float64(p.MemberCount)
+		}
+		total += share / float64(len(tags))
+		signals++
+	}
+
+	if user.SkillLevel != "" && len(p.SkillLevels) > 0 {
+		closeness, counted := 0.0, 0
+		for level, n := range p.SkillLevels {
+			diff := skillRank(level) - skillRank(user.SkillLevel)
+			closeness += float64(n) * (1 - float64(max(diff, -diff))/2)
+			counted += n
+		}
+		total += closeness / float64(counted)
+		signals++
+	}
+
+	if shared := p.sharedSchedule(now); !user.Schedule.IsZero() && !shared.IsZero() {
+		total += availability.Coverage(user.Schedule, shared, now)
+		signals++
+	}
+
+	if signals == 0 {
+		return 0
+	}
+	return total / float64(signals)
+}
+
+// refreshGroupProfile recomputes a group's profile from its current members
+func refreshGroupProfile(ctx context.Context, repo Repository, groupID string) (*GroupProfile, error) {
+	members, err := repo.GetMemberProfiles(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("get member profiles: %w", err)
+	}
+
+	profile := BuildGroupProfile(groupID, members, time.Now())
+	if err := repo.SaveGroupProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// tryRefreshGroupProfile refreshes a group's profile once new members
+// are committed. Failures are logged and leave the previous profile in place.
+func tryRefreshGroupProfile(ctx context.Context, repo Repository, log logger.Logger, groupID string) {
+	if _, err := refreshGroupProfile(ctx, repo, groupID); err != nil {
+		log.Warn(ctx, "failed to refresh group profile",
+			logger.Field{Key: "group_id", Value: groupID},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+// GetGroupProfile returns the composite profile of an owner's group,
+// computing it for groups whose profile was never built
+func (s *Service) GetGroupProfile(ctx context.Context, groupID, ownerID string) (*GroupProfile, error) {
+	group, err := s.repo.GetGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.OwnerID != ownerID {
+		return nil, ErrNotGroupOwner
+	}
+
+	profile, err := s.repo.GetGroupProfile(ctx, groupID)
+	if errors.Is(err, ErrGroupProfileNotFound) {
+		profile, err = refreshGroupProfile(ctx, s.repo, groupID)
+	}
+	if err != nil {
+		s.logger.Error(ctx, "failed to get group profile",
+			logger.Field{Key: "group_id", Value: groupID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+	profile.SharedSchedule = profile.sharedSchedule(time.Now())
+
+	return profile, nil
+}
+
+// MemberProfileMatcher implements GroupMatcher by blending how well the user
+// fits each group's current members into another matcher's scores. Groups
+// without a computed profile keep their score.
+type MemberProfileMatcher struct {
+	matcher GroupMatcher
+	repo    Repository
+	weight  float64
+}
+
+func NewMemberProfileMatcher(matcher GroupMatcher, repo Repository, weight float64) *MemberProfileMatcher {
+	return &MemberProfileMatcher{
+		matcher: matcher,
+		repo:    repo,
+		weight:  weight,
+	}
+}
+
+func (m *MemberProfileMatcher) FindMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	matches, err := m.matcher.FindMatches(ctx, userProfile, filters)
+	if err != nil || len(matches) == 0 {
+		return matches, err
+	}
+
+	groupIDs := make([]string, len(matches))
+	for i, match := range matches {
+		groupIDs[i] = match.Group.ID
+	}
+
+	profiles, err := m.repo.GetGroupProfiles(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, match := range matches {
+		if p, ok := profiles[match.Group.ID]; ok {
+			matches[i].SimilarityScore = (1-m.weight)*match.SimilarityScore + m.weight*p.Fit(userProfile, now)
+		}
+	}
+	sortByScoreStable(matches)
+
+	return matches, nil
+}
@@ -0,0 +1,68 @@
+package group
+
+import (
+	"testing"
+
+	"bmatch/pkg/availability"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupProfile_Fit(t *testing.T) {
+	members := []*UserProfile{
+		{UserID: "a", Tags: []string{"go"}, SkillLevel: SkillLevelBeginner, Schedule: evenings("Europe/Berlin")},
+		{UserID: "b", Tags: []string{"go", "rust"}, SkillLevel: SkillLevelBeginner, Schedule: evenings("Europe/Berlin")},
+		{UserID: "c", Tags: []string{"go", "go"}},
+	}
+	profile := BuildGroupProfile("g", members, winter)
+
+	cases := map[string]struct {
+		profile *GroupProfile
+		user    UserProfile
+		want    float64
+	}{
+		"no members":       {profile: BuildGroupProfile("g", nil, winter), user: UserProfile{Tags: []string{"go"}}, want: 0},
+		"no signals":       {profile: profile, user: UserProfile{}, want: 0},
+		"shared tag":       {profile: profile, user: UserProfile{Tags: []string{"go"}}, want: 1},
+		"partly held tags": {profile: profile, user: UserProfile{Tags: []string{"rust", "python"}}, want: 1.0 / 6},
+		"same skill":       {profile: profile, user: UserProfile{SkillLevel: SkillLevelBeginner}, want: 1},
+		"distant skill":    {profile: profile, user: UserProfile{SkillLevel: SkillLevelAdvanced}, want: 0},
+		"shared schedule":  {profile: profile, user: UserProfile{Schedule: evenings("Europe/Berlin")}, want: 1},
+		"mean of signals": {
+			profile: profile,
+			user:    UserProfile{Tags: []string{"go"}, SkillLevel: SkillLevelIntermediate},
+			want:    0.75,
+		},
+		"schedule in another zone": {
+			profile: profile,
+			// 18:00-21:00 in London is 19:00-22:00 in Berlin, two of the three shared hours
+			user: UserProfile{Schedule: evenings("Europe/London")},
+			want: 2.0 / 3,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, tc.profile.Fit(tc.user, winter), 1e-9)
+		})
+	}
+}
+
+func TestGroupProfile_SharedScheduleFollowsDST(t *testing.T) {
+	members := []*UserProfile{
+		{UserID: "a", Schedule: evenings("Europe/Berlin")},
+		{UserID: "b", Schedule: evenings("Europe/Berlin")},
+	}
+	user := UserProfile{Schedule: evenings("Europe/Berlin")}
+
+	// Built in winter, used in summer: the members' evenings moved an hour
+	// earlier in UTC and the shared time must move with them
+	profile := BuildGroupProfile("g", members, winter)
+
+	assert.Equal(t, "17:00", profile.SharedSchedule.Slots[0].Start)
+	assert.Equal(t, "16:00", profile.sharedSchedule(summer).Slots[0].Start)
+	assert.InDelta(t, 1, profile.Fit(user, summer), 1e-9)
+
+	assert.Equal(t, availability.Schedule{TimeZone: "UTC", Slots: []availability.Slot{}},
+		BuildGroupProfile("g", []*UserProfile{{UserID: "a"}}, winter).sharedSchedule(summer))
+}
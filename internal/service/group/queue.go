@@ -0,0 +1,649 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/logger"
+	"bmatch/pkg/taxonomy"
+
+	"github.com/google/uuid"
+)
+
+// Matchmaker runs the real-time matchmaking queue. Waiting users live in a
+// Redis sorted set ordered by arrival; a matcher loop proposes groups of
+// compatible users, and a group is only created once every user accepts.
+type Matchmaker struct {
+	repo     Repository
+	taxonomy TaxonomyProvider
+	tags     TagNormalizer
+	cache    cache.Cache
+	config   cfg.GroupConfig
+	logger   logger.Logger
+}
+
+func NewMatchmaker(repo Repository, taxonomy TaxonomyProvider, tags TagNormalizer, cache cache.Cache, config cfg.GroupConfig, logger logger.Logger) *Matchmaker {
+	return &Matchmaker{
+		repo:     repo,
+		taxonomy: taxonomy,
+		tags:     tags,
+		cache:    cache,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Enter puts a user in the queue. Tags and intent default to the user's profile.
+func (m *Matchmaker) Enter(ctx context.Context, userID string, req EnterQueueRequest) (*QueueStatus, error) {
+	profile, err := m.repo.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Tags) > 0 {
+		profile.Tags = req.Tags
+	}
+	profile.Tags, err = m.tags.Normalize(ctx, profile.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("normalize tags: %w", err)
+	}
+	if len(profile.Tags) == 0 {
+		return nil, ErrInvalidTags
+	}
+	if req.Intent != "" {
+		profile.Intent = req.Intent
+	}
+
+	entry := &QueueEntry{
+		UserProfile: *profile,
+		GroupSize:   req.GroupSize,
+		EnqueuedAt:  time.Now(),
+	}
+
+	err = m.withLock(ctx, func(ctx context.Context) error {
+		queued, err := m.isQueued(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if queued {
+			return ErrAlreadyQueued
+		}
+
+		proposal, err := m.userProposal(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if proposal != nil && proposal.Status == ProposalStatusPending {
+			return ErrAlreadyQueued
+		}
+
+		if err := m.saveEntry(ctx, entry); err != nil {
+			return err
+		}
+		return m.cache.ZAdd(ctx, matchQueueKey, userID, queueScore(entry.EnqueuedAt))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info(ctx, "user entered matchmaking queue",
+		logger.Field{Key: "user_id", Value: userID},
+		logger.Field{Key: "group_size", Value: req.GroupSize},
+	)
+
+	return m.Status(ctx, userID)
+}
+
+// Leave removes a user from the queue; leaving with a pending proposal declines it
+func (m *Matchmaker) Leave(ctx context.Context, userID string) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		proposal, err := m.userProposal(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if proposal != nil && proposal.Status == ProposalStatusPending {
+			return m.closeProposal(ctx, proposal, ProposalStatusDeclined)
+		}
+
+		queued, err := m.isQueued(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !queued {
+			return ErrNotQueued
+		}
+
+		if err := m.cache.ZRem(ctx, matchQueueKey, userID); err != nil {
+			return fmt.Errorf("remove from queue: %w", err)
+		}
+		return m.cache.Del(ctx, matchEntryKeyPrefix+userID)
+	})
+}
+
+// Status reports the user's queue position and latest match proposal.
+// Polling it keeps the user's queue entry alive, see entryTTL.
+func (m *Matchmaker) Status(ctx context.Context, userID string) (*QueueStatus, error) {
+	status := &QueueStatus{}
+
+	rank, err := m.cache.ZRank(ctx, matchQueueKey, userID)
+	switch {
+	case err == nil:
+		status.Queued = true
+		status.Position = int(rank) + 1
+	case !errors.Is(err, cache.ErrCacheMiss):
+		return nil, fmt.Errorf("queue rank: %w", err)
+	}
+
+	entry, err := m.entry(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Entry = entry
+	if entry != nil {
+		m.touchEntry(ctx, userID)
+	}
+
+	status.Proposal, err = m.userProposal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Respond records a user's answer to a proposal. When the last user accepts
+// the group is created; a decline re-queues everyone who already accepted.
+func (m *Matchmaker) Respond(ctx context.Context, userID, proposalID string, accept bool) (*MatchProposal, error) {
+	var proposal *MatchProposal
+
+	err := m.withLock(ctx, func(ctx context.Context) error {
+		var err error
+		proposal, err = m.proposal(ctx, proposalID)
+		if err != nil {
+			return err
+		}
+		if proposal == nil || proposal.Status != ProposalStatusPending || !contains(proposal.UserIDs, userID) {
+			return ErrProposalNotFound
+		}
+
+		if time.Now().After(proposal.ExpiresAt) {
+			if err := m.closeProposal(ctx, proposal, ProposalStatusExpired); err != nil {
+				return err
+			}
+			return ErrProposalNotFound
+		}
+
+		if !accept {
+			proposal.Accepted = remove(proposal.Accepted, userID)
+			return m.closeProposal(ctx, proposal, ProposalStatusDeclined)
+		}
+
+		if !contains(proposal.Accepted, userID) {
+			proposal.Accepted = append(proposal.Accepted, userID)
+		}
+		if len(proposal.Accepted) < len(proposal.UserIDs) {
+			return m.saveProposal(ctx, proposal)
+		}
+
+		return m.formGroup(ctx, proposal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+// Start scans the queue every MatchInterval until ctx is done
+func (m *Matchmaker) Start(ctx context.Context) {
+	if m.config.MatchInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(m.config.MatchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := m.withLock(ctx, m.matchOnce)
+				if err != nil && !errors.Is(err, ErrQueueBusy) && ctx.Err() == nil {
+					m.logger.Error(ctx, "matchmaking scan failed", logger.Field{Key: "error", Value: err})
+				}
+			}
+		}
+	}()
+}
+
+// matchOnce expires overdue proposals, then proposes groups to compatible
+// queued users. Users who waited longest are matched first.
+func (m *Matchmaker) matchOnce(ctx context.Context) error {
+	now := time.Now()
+
+	overdue, err := m.cache.ZRangeByScore(ctx, matchProposalsKey, math.Inf(-1), queueScore(now))
+	if err != nil {
+		return fmt.Errorf("list overdue proposals: %w", err)
+	}
+	for _, id := range overdue {
+		proposal, err := m.proposal(ctx, id)
+		if err != nil {
+			return err
+		}
+		if proposal == nil || proposal.Status != ProposalStatusPending {
+			m.cache.ZRem(ctx, matchProposalsKey, id)
+			continue
+		}
+		if err := m.closeProposal(ctx, proposal, ProposalStatusExpired); err != nil {
+			return err
+		}
+	}
+
+	userIDs, err := m.cache.ZRangeByScore(ctx, matchQueueKey, math.Inf(-1), math.Inf(1))
+	if err != nil {
+		return fmt.Errorf("list queue: %w", err)
+	}
+
+	entries := make([]*QueueEntry, 0, len(userIDs))
+	for _, id := range userIDs {
+		entry, err := m.entry(ctx, id)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			m.cache.ZRem(ctx, matchQueueKey, id)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	tax, err := m.taxonomy.Taxonomy(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, members := range matchQueue(entries, tax, now) {
+		if err := m.propose(ctx, members, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchQueue groups queued users wanting the same group size and intent.
+// Each group is anchored on the longest-waiting unmatched user and filled
+// with the most similar users whose tags fit every member already picked.
+// Schedules are compared in the week of now.
+func matchQueue(entries []*QueueEntry, tax *taxonomy.Taxonomy, now time.Time) [][]*QueueEntry {
+	matched := make(map[string]bool)
+	groups := make([][]*QueueEntry, 0)
+
+	for i, anchor := range entries {
+		if matched[anchor.UserID] {
+			continue
+		}
+
+		type scored struct {
+			entry *QueueEntry
+			score float64
+		}
+		candidates := make([]scored, 0)
+		for _, e := range entries[i+1:] {
+			if matched[e.UserID] || e.GroupSize != anchor.GroupSize || e.Intent != anchor.Intent {
+				continue
+			}
+			candidates = append(candidates, scored{e, userSimilarity(&anchor.UserProfile, &e.UserProfile, tax, now)})
+		}
+		if len(candidates) < anchor.GroupSize-1 {
+			continue
+		}
+		sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+
+		members := []*QueueEntry{anchor}
+		for _, c := range candidates {
+			if len(members) == anchor.GroupSize {
+				break
+			}
+			if fitsAll(c.entry, members, tax) {
+				members = append(members, c.entry)
+			}
+		}
+		if len(members) < anchor.GroupSize {
+			continue
+		}
+
+		for _, e := range members {
+			matched[e.UserID] = true
+		}
+		groups = append(groups, members)
+	}
+
+	return groups
+}
+
+// fitsAll reports whether e's tags are compatible with every member's
+func fitsAll(e *QueueEntry, members []*QueueEntry, tax *taxonomy.Taxonomy) bool {
+	for _, member := range members {
+		if CalculateTaxonomyScore(e.Tags, member.Tags, tax) < matchMinTagScore {
+			return false
+		}
+	}
+	return true
+}
+
+// propose takes matched users out of the queue and offers them a group
+func (m *Matchmaker) propose(ctx context.Context, members []*QueueEntry, now time.Time) error {
+	profiles := make([]*UserProfile, len(members))
+	userIDs := make([]string, len(members))
+	for i, e := range members {
+		profiles[i] = &e.UserProfile
+		userIDs[i] = e.UserID
+	}
+
+	proposal := &MatchProposal{
+		ID:        uuid.New().String(),
+		UserIDs:   userIDs,
+		Accepted:  []string{},
+		Tags:      topTags(profiles, autoFormMaxTags),
+		Intent:    members[0].Intent,
+		GroupSize: members[0].GroupSize,
+		Status:    ProposalStatusPending,
+		ExpiresAt: now.Add(m.config.MatchAcceptWindow),
+		CreatedAt: now,
+	}
+
+	if err := m.cache.ZRem(ctx, matchQueueKey, userIDs...); err != nil {
+		return fmt.Errorf("remove matched users from queue: %w", err)
+	}
+	if err := m.saveProposal(ctx, proposal); err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		if err := m.cache.Set(ctx, matchUserProposalKeyPrefix+id, proposal.ID, m.proposalTTL()); err != nil {
+			return fmt.Errorf("link proposal: %w", err)
+		}
+		m.touchEntry(ctx, id)
+	}
+	if err := m.cache.ZAdd(ctx, matchProposalsKey, proposal.ID, queueScore(proposal.ExpiresAt)); err != nil {
+		return fmt.Errorf("track proposal: %w", err)
+	}
+
+	m.logger.Info(ctx, "match proposed",
+		logger.Field{Key: "proposal_id", Value: proposal.ID},
+		logger.Field{Key: "users", Value: len(userIDs)},
+	)
+
+	return nil
+}
+
+// formGroup creates the group for a fully accepted proposal
+func (m *Matchmaker) formGroup(ctx context.Context, proposal *MatchProposal) error {
+	tax, err := m.taxonomy.Taxonomy(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles := make([]*UserProfile, 0, len(proposal.UserIDs))
+	for _, id := range proposal.UserIDs {
+		entry, err := m.entry(ctx, id)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return fmt.Errorf("queue entry of %s missing", id)
+		}
+		profiles = append(profiles, &entry.UserProfile)
+	}
+
+	leader, _ := electLeader(profiles, tax, proposal.CreatedAt)
+	group := newMatchedGroup(profiles, leader, proposal.GroupSize)
+	group.ID = uuid.New().String()
+
+	if err := createMatchedGroup(ctx, m.repo, group, proposal.UserIDs); err != nil {
+		m.logger.Error(ctx, "failed to create matched group",
+			logger.Field{Key: "proposal_id", Value: proposal.ID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return err
+	}
+
+	tryRefreshGroupProfile(ctx, m.repo, m.logger, group.ID)
+
+	proposal.Status = ProposalStatusAccepted
+	proposal.GroupID = group.ID
+	entryKeys := make([]string, len(proposal.UserIDs))
+	for i, id := range proposal.UserIDs {
+		entryKeys[i] = matchEntryKeyPrefix + id
+	}
+	m.cache.Pipelined(ctx, func(p cache.Pipe) {
+		p.Del(entryKeys...)
+		p.ZRem(matchProposalsKey, proposal.ID)
+	})
+
+	m.logger.Info(ctx, "matched group created",
+		logger.Field{Key: "proposal_id", Value: proposal.ID},
+		logger.Field{Key: "group_id", Value: group.ID},
+	)
+
+	return m.saveProposal(ctx, proposal)
+}
+
+// closeProposal ends a proposal without a group. Users who accepted go back
+// into the queue at their original position; everyone else is dropped.
+func (m *Matchmaker) closeProposal(ctx context.Context, proposal *MatchProposal, status string) error {
+	for _, id := range proposal.UserIDs {
+		if !contains(proposal.Accepted, id) {
+			m.cache.Del(ctx, matchEntryKeyPrefix+id)
+			continue
+		}
+
+		entry, err := m.entry(ctx, id)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			continue
+		}
+		if err := m.cache.ZAdd(ctx, matchQueueKey, id, queueScore(entry.EnqueuedAt)); err != nil {
+			return fmt.Errorf("re-queue user: %w", err)
+		}
+		m.touchEntry(ctx, id)
+	}
+
+	proposal.Status = status
+	m.cache.ZRem(ctx, matchProposalsKey, proposal.ID)
+
+	m.logger.Info(ctx, "match proposal closed",
+		logger.Field{Key: "proposal_id", Value: proposal.ID},
+		logger.Field{Key: "status", Value: status},
+		logger.Field{Key: "requeued", Value: len(proposal.Accepted)},
+	)
+
+	return m.saveProposal(ctx, proposal)
+}
+
+// withLock serializes queue mutations across instances. The lease is
+// extended while fn runs; if it is lost anyway, the ctx passed to fn is
+// cancelled so fn stops before another instance works on the same users.
+func (m *Matchmaker) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	deadline := time.Now().Add(2 * time.Second)
+	var lock *cache.Lock
+	for {
+		var err error
+		lock, err = cache.TryLock(ctx, m.cache, matchQueueLockKey, matchQueueLockTTL)
+		if err != nil {
+			return fmt.Errorf("acquire queue lock: %w", err)
+		}
+		if lock != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return ErrQueueBusy
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	defer func() {
+		if err := lock.Release(ctx); err != nil {
+			m.logger.Warn(ctx, "failed to release queue lock", logger.Field{Key: "error", Value: err})
+		}
+	}()
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go m.keepLock(lockCtx, cancel, lock, matchQueueLockRenew)
+
+	if err := fn(lockCtx); err != nil {
+		if ctx.Err() == nil && lockCtx.Err() != nil {
+			return fmt.Errorf("%w: %w", ErrQueueBusy, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// keepLock extends the queue lock every interval until ctx is done and
+// cancels ctx when the lease cannot be extended
+func (m *Matchmaker) keepLock(ctx context.Context, cancel context.CancelFunc, lock *cache.Lock, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			extended, err := lock.Extend(ctx, matchQueueLockTTL)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil || !extended {
+				m.logger.Warn(ctx, "lost queue lock",
+					logger.Field{Key: "extended", Value: extended},
+					logger.Field{Key: "error", Value: err},
+				)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (m *Matchmaker) isQueued(ctx context.Context, userID string) (bool, error) {
+	_, err := m.cache.ZRank(ctx, matchQueueKey, userID)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("queue rank: %w", err)
+	}
+	return true, nil
+}
+
+// entry loads a queue entry, returning nil if there is none
+func (m *Matchmaker) entry(ctx context.Context, userID string) (*QueueEntry, error) {
+	return loadQueued[QueueEntry](ctx, m.cache, matchEntryKeyPrefix+userID)
+}
+
+func (m *Matchmaker) saveEntry(ctx context.Context, entry *QueueEntry) error {
+	return cache.SetJSON(ctx, m.cache, matchEntryKeyPrefix+entry.UserID, entry, m.entryTTL())
+}
+
+// touchEntry resets the TTL of a user's queue entry
+func (m *Matchmaker) touchEntry(ctx context.Context, userID string) {
+	ttl := m.entryTTL()
+	if ttl <= 0 {
+		return
+	}
+	if _, err := m.cache.Expire(ctx, matchEntryKeyPrefix+userID, ttl); err != nil {
+		m.logger.Warn(ctx, "failed to refresh queue entry",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+// entryTTL drops users who stopped polling their queue status. The accept
+// window on top keeps entries of users in a proposal until it closes, so
+// accepted users can be re-queued or grouped.
+func (m *Matchmaker) entryTTL() time.Duration {
+	if m.config.MatchEntryTTL <= 0 {
+		return 0
+	}
+	return m.config.MatchEntryTTL + m.config.MatchAcceptWindow
+}
+
+// proposal loads a proposal, returning nil if it does not exist or has expired
+func (m *Matchmaker) proposal(ctx context.Context, proposalID string) (*MatchProposal, error) {
+	return loadQueued[MatchProposal](ctx, m.cache, matchProposalKeyPrefix+proposalID)
+}
+
+// userProposal loads the latest proposal offered to a user
+func (m *Matchmaker) userProposal(ctx context.Context, userID string) (*MatchProposal, error) {
+	proposalID, err := m.cache.Get(ctx, matchUserProposalKeyPrefix+userID)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user proposal: %w", err)
+	}
+	return m.proposal(ctx, proposalID)
+}
+
+func (m *Matchmaker) saveProposal(ctx context.Context, proposal *MatchProposal) error {
+	return cache.SetJSON(ctx, m.cache, matchProposalKeyPrefix+proposal.ID, proposal, m.proposalTTL())
+}
+
+// proposalTTL keeps decided proposals around long enough for users to see the outcome
+func (m *Matchmaker) proposalTTL() time.Duration {
+	return 2 * m.config.MatchAcceptWindow
+}
+
+// loadQueued loads a JSON value of the queue, returning nil if there is none
+func loadQueued[T any](ctx context.Context, c cache.Cache, key string) (*T, error) {
+	value, err := cache.GetJSON[T](ctx, c, key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	return &value, nil
+}
+
+// queueScore orders sorted set members by time
+func queueScore(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(values []string, v string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != v {
+			out = append(out, value)
+		}
+	}
+	return out
+}
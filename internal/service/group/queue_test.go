@@ -0,0 +1,208 @@
+package group
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/logger"
+	"bmatch/pkg/taxonomy"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntry(id string, size int, intent string, tags ...string) *QueueEntry {
+	return &QueueEntry{UserProfile: *testProfile(id, intent, tags...), GroupSize: size}
+}
+
+func entryIDs(groups [][]*QueueEntry) [][]string {
+	ids := make([][]string, len(groups))
+	for i, g := range groups {
+		ids[i] = make([]string, 0, len(g))
+		for _, e := range g {
+			ids[i] = append(ids[i], e.UserID)
+		}
+	}
+	return ids
+}
+
+func TestMatchQueue(t *testing.T) {
+	tax := taxonomy.New(map[string]string{"go": "backend", "rust": "backend", "react": "frontend"})
+
+	cases := []struct {
+		name    string
+		entries []*QueueEntry
+		want    [][]string
+	}{
+		{
+			name: "pairs in queue order",
+			entries: []*QueueEntry{
+				testEntry("a", 2, IntentCasual, "go"),
+				testEntry("b", 2, IntentCasual, "go"),
+				testEntry("c", 2, IntentCasual, "go"),
+				testEntry("d", 2, IntentCasual, "go"),
+			},
+			want: [][]string{{"a", "b"}, {"c", "d"}},
+		},
+		{
+			name: "group size must match",
+			entries: []*QueueEntry{
+				testEntry("a", 2, IntentCasual, "go"),
+				testEntry("b", 3, IntentCasual, "go"),
+				testEntry("c", 3, IntentCasual, "go"),
+			},
+			want: [][]string{},
+		},
+		{
+			name: "intent must match",
+			entries: []*QueueEntry{
+				testEntry("a", 2, IntentCasual, "go"),
+				testEntry("b", 2, IntentSerious, "go"),
+				testEntry("c", 2, IntentSerious, "go"),
+			},
+			want: [][]string{{"b", "c"}},
+		},
+		{
+			name: "incompatible tags are not matched",
+			entries: []*QueueEntry{
+				testEntry("a", 2, IntentCasual, "go"),
+				testEntry("b", 2, IntentCasual, "react"),
+			},
+			want: [][]string{},
+		},
+		{
+			name: "anchor takes the most similar users",
+			entries: []*QueueEntry{
+				testEntry("a", 2, IntentCasual, "go"),
+				testEntry("b", 2, IntentCasual, "rust"),
+				testEntry("c", 2, IntentCasual, "go"),
+			},
+			want: [][]string{{"a", "c"}},
+		},
+		{
+			name: "every member must fit the others",
+			entries: []*QueueEntry{
+				testEntry("a", 3, IntentCasual, "go", "react"),
+				testEntry("b", 3, IntentCasual, "go"),
+				testEntry("c", 3, IntentCasual, "react"),
+				testEntry("d", 3, IntentCasual, "go", "react"),
+			},
+			want: [][]string{{"a", "d", "b"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, entryIDs(matchQueue(tc.entries, tax, winter)))
+		})
+	}
+}
+
+func TestMatchmaker_WithLock(t *testing.T) {
+	store := cache.NewMemoryCache(0)
+	m := NewMatchmaker(nil, nil, nil, store, cfg.GroupConfig{}, logger.NewLogger("test"))
+	ctx := context.Background()
+
+	t.Run("releases the lock", func(t *testing.T) {
+		require.NoError(t, m.withLock(ctx, func(context.Context) error { return nil }))
+
+		n, err := store.Exists(ctx, matchQueueLockKey)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("keeps a lock taken over by another instance", func(t *testing.T) {
+		err := m.withLock(ctx, func(ctx context.Context) error {
+			// The lock expired mid-run and another instance acquired it
+			return store.Set(ctx, matchQueueLockKey, "other", matchQueueLockTTL)
+		})
+		require.NoError(t, err)
+
+		val, err := store.Get(ctx, matchQueueLockKey)
+		require.NoError(t, err)
+		assert.Equal(t, "other", val)
+	})
+}
+
+func TestMatchmaker_KeepLock(t *testing.T) {
+	store := cache.NewMemoryCache(0)
+	m := NewMatchmaker(nil, nil, nil, store, cfg.GroupConfig{}, logger.NewLogger("test"))
+
+	t.Run("extends the lease", func(t *testing.T) {
+		lock, err := cache.TryLock(context.Background(), store, "lock:keep", 50*time.Millisecond)
+		require.NoError(t, err)
+		require.NotNil(t, lock)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go m.keepLock(ctx, cancel, lock, 10*time.Millisecond)
+
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, ctx.Err(), "lease kept")
+		extended, err := lock.Extend(context.Background(), time.Minute)
+		require.NoError(t, err)
+		assert.True(t, extended, "lease still held past its first TTL")
+		cancel()
+	})
+
+	t.Run("cancels when the lease is lost", func(t *testing.T) {
+		lock, err := cache.TryLock(context.Background(), store, "lock:lost", time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, lock)
+
+		// Another instance took the lock after the lease expired
+		require.NoError(t, store.Set(context.Background(), "lock:lost", "other", time.Minute))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go m.keepLock(ctx, cancel, lock, 10*time.Millisecond)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("ctx not cancelled after losing the lock")
+		}
+	})
+}
+
+func TestMatchmaker_EntryTTL(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	store := cache.NewRedisCache(mr.Addr())
+	config := cfg.GroupConfig{MatchEntryTTL: 10 * time.Minute, MatchAcceptWindow: 2 * time.Minute}
+	m := NewMatchmaker(nil, nil, nil, store, config, logger.NewLogger("test"))
+	ctx := context.Background()
+
+	require.NoError(t, m.saveEntry(ctx, testEntry("a", 2, IntentCasual, "go")))
+	assert.Equal(t, 12*time.Minute, mr.TTL(matchEntryKeyPrefix+"a"))
+
+	t.Run("status polls keep the entry alive", func(t *testing.T) {
+		mr.FastForward(11 * time.Minute)
+
+		status, err := m.Status(ctx, "a")
+		require.NoError(t, err)
+		require.NotNil(t, status.Entry)
+		assert.Equal(t, 12*time.Minute, mr.TTL(matchEntryKeyPrefix+"a"))
+	})
+
+	t.Run("abandoned entries expire", func(t *testing.T) {
+		mr.FastForward(13 * time.Minute)
+
+		status, err := m.Status(ctx, "a")
+		require.NoError(t, err)
+		assert.Nil(t, status.Entry)
+	})
+
+	t.Run("0 keeps entries", func(t *testing.T) {
+		m := NewMatchmaker(nil, nil, nil, store, cfg.GroupConfig{}, logger.NewLogger("test"))
+		require.NoError(t, m.saveEntry(ctx, testEntry("b", 2, IntentCasual, "go")))
+		m.touchEntry(ctx, "b")
+		assert.Zero(t, mr.TTL(matchEntryKeyPrefix+"b"))
+		assert.True(t, mr.Exists(matchEntryKeyPrefix+"b"))
+	})
+}
@@ -0,0 +1,175 @@
+package group
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"bmatch/cfg"
+)
+
+// RankingStage reorders or rescores scored matches
+type RankingStage interface {
+	Rank(matches []GroupMatch, now time.Time) []GroupMatch
+}
+
+// RankedMatcher implements GroupMatcher by running ranking stages, in order,
+// over the scored and sorted results of another matcher
+type RankedMatcher struct {
+	matcher GroupMatcher
+	stages  []RankingStage
+}
+
+func NewRankedMatcher(matcher GroupMatcher, stages ...RankingStage) *RankedMatcher {
+	return &RankedMatcher{
+		matcher: matcher,
+		stages:  stages,
+	}
+}
+
+func (m *RankedMatcher) FindMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	matches, err := m.matcher.FindMatches(ctx, userProfile, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, stage := range m.stages {
+		matches = stage.Rank(matches, now)
+	}
+
+	return matches, nil
+}
+
+// NewRankingStages builds the configured stages: boosts first, then
+// diversity re-ranking so it sees the boosted scores
+func NewRankingStages(config cfg.RankingConfig) []RankingStage {
+	stages := make([]RankingStage, 0)
+	if config.FreshnessWeight > 0 {
+		stages = append(stages, FreshnessBoost{Weight: config.FreshnessWeight, HalfLife: config.FreshnessHalfLife})
+	}
+	if config.PopularityWeight > 0 {
+		stages = append(stages, PopularityBoost{Weight: config.PopularityWeight, HalfLife: config.PopularityHalfLife})
+	}
+	if config.BookmarkWeight > 0 {
+		stages = append(stages, BookmarkBoost{Weight: config.BookmarkWeight})
+	}
+	if config.DiversityLambda < 1 {
+		stages = append(stages, MMRReranker{Lambda: config.DiversityLambda})
+	}
+	return stages
+}
+
+// FreshnessBoost adds Weight to brand-new groups, halving every HalfLife of age
+type FreshnessBoost struct {
+	Weight   float64
+	HalfLife time.Duration
+}
+
+func (b FreshnessBoost) Rank(matches []GroupMatch, now time.Time) []GroupMatch {
+	for i := range matches {
+		age := now.Sub(matches[i].Group.CreatedAt)
+		matches[i].SimilarityScore += b.Weight * decay(age, b.HalfLife)
+	}
+	sortByScoreStable(matches)
+	return matches
+}
+
+// PopularityBoost adds up to Weight for how full a group is, halving every
+// HalfLife since the group last changed so stale groups stop benefiting
+type PopularityBoost struct {
+	Weight   float64
+	HalfLife time.Duration
+}
+
+func (b PopularityBoost) Rank(matches []GroupMatch, now time.Time) []GroupMatch {
+	for i := range matches {
+		g := matches[i].Group
+		if g.Capacity == 0 {
+			continue
+		}
+		fill := float64(g.CurrentCount) / float64(g.Capacity)
+		matches[i].SimilarityScore += b.Weight * fill * decay(now.Sub(g.UpdatedAt), b.HalfLife)
+	}
+	sortByScoreStable(matches)
+	return matches
+}
+
+// BookmarkBoost adds up to Weight for how many users bookmarked a group,
+// reaching half of Weight at bookmarkHalfBoostCount bookmarks
+type BookmarkBoost struct {
+	Weight float64
+}
+
+func (b BookmarkBoost) Rank(matches []GroupMatch, _ time.Time) []GroupMatch {
+	for i := range matches {
+		count := float64(matches[i].Group.BookmarkCount)
+		matches[i].SimilarityScore += b.Weight * count / (count + bookmarkHalfBoostCount)
+	}
+	sortByScoreStable(matches)
+	return matches
+}
+
+// MMRReranker applies maximal marginal relevance: each next result maximises
+// Lambda*score - (1-Lambda)*(similarity to the closest result already picked).
+// Lambda 1 keeps the relevance order, lower values favour diversity of tags
+// and owners. Scores are left untouched; only the order changes.
+type MMRReranker struct {
+	Lambda float64
+}
+
+func (r MMRReranker) Rank(matches []GroupMatch, _ time.Time) []GroupMatch {
+	remaining := append([]GroupMatch(nil), matches...)
+	ranked := make([]GroupMatch, 0, len(matches))
+
+	// Highest similarity of each remaining match to anything already ranked
+	closest := make([]float64, len(remaining))
+
+	for len(remaining) > 0 {
+		best, bestValue := 0, math.Inf(-1)
+		for i, m := range remaining {
+			value := r.Lambda*m.SimilarityScore - (1-r.Lambda)*closest[i]
+			if value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+
+		picked := remaining[best]
+		ranked = append(ranked, picked)
+		remaining = append(remaining[:best], remaining[best+1:]...)
+		closest = append(closest[:best], closest[best+1:]...)
+
+		for i, m := range remaining {
+			closest[i] = math.Max(closest[i], groupSimilarity(picked.Group, m.Group))
+		}
+	}
+
+	return ranked
+}
+
+// groupSimilarity measures how redundant two results are to a user
+func groupSimilarity(a, b *Group) float64 {
+	sim := mmrTagWeight * CalculateJaccardScore(a.Tags, b.Tags)
+	if a.OwnerID == b.OwnerID {
+		sim += mmrOwnerWeight
+	}
+	return sim
+}
+
+// decay halves every halfLife; a non-positive halfLife disables decay
+func decay(age, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		return 1
+	}
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+func sortByScoreStable(matches []GroupMatch) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].SimilarityScore > matches[j].SimilarityScore
+	})
+}
@@ -0,0 +1,128 @@
+package group
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scoredMatch(id, owner string, score float64, tags ...string) GroupMatch {
+	return GroupMatch{Group: &Group{ID: id, OwnerID: owner, Tags: tags}, SimilarityScore: score}
+}
+
+func matchIDs(matches []GroupMatch) []string {
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.Group.ID
+	}
+	return ids
+}
+
+func TestPopularityBoost(t *testing.T) {
+	now := winter
+	boost := PopularityBoost{Weight: 1, HalfLife: 24 * time.Hour}
+
+	cases := map[string]struct {
+		boost     PopularityBoost
+		count     int
+		capacity  int
+		updatedAt time.Time
+		want      float64
+	}{
+		"active group":          {boost: boost, count: 2, capacity: 4, updatedAt: now, want: 0.5},
+		"one half-life stale":   {boost: boost, count: 2, capacity: 4, updatedAt: now.Add(-24 * time.Hour), want: 0.25},
+		"two half-lives stale":  {boost: boost, count: 4, capacity: 4, updatedAt: now.Add(-48 * time.Hour), want: 0.25},
+		"updated in the future": {boost: boost, count: 2, capacity: 4, updatedAt: now.Add(time.Hour), want: 0.5},
+		"no decay":              {boost: PopularityBoost{Weight: 1}, count: 2, capacity: 4, updatedAt: now.AddDate(-1, 0, 0), want: 0.5},
+		"no capacity":           {boost: boost, count: 2, capacity: 0, updatedAt: now, want: 0},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g := &Group{ID: "g", CurrentCount: tc.count, Capacity: tc.capacity, UpdatedAt: tc.updatedAt}
+			ranked := tc.boost.Rank([]GroupMatch{{Group: g}}, now)
+			assert.InDelta(t, tc.want, ranked[0].SimilarityScore, 1e-9)
+		})
+	}
+
+	t.Run("stale full group falls behind active one", func(t *testing.T) {
+		stale := GroupMatch{Group: &Group{ID: "stale", CurrentCount: 4, Capacity: 4, UpdatedAt: now.AddDate(0, 0, -30)}}
+		active := GroupMatch{Group: &Group{ID: "active", CurrentCount: 2, Capacity: 4, UpdatedAt: now}}
+
+		ranked := boost.Rank([]GroupMatch{stale, active}, now)
+		assert.Equal(t, []string{"active", "stale"}, matchIDs(ranked))
+	})
+}
+
+func TestMMRReranker(t *testing.T) {
+	cases := []struct {
+		name    string
+		lambda  float64
+		matches []GroupMatch
+		want    []string
+	}{
+		{
+			name:   "lambda one keeps relevance order",
+			lambda: 1,
+			matches: []GroupMatch{
+				scoredMatch("a", "o1", 0.9, "go"),
+				scoredMatch("b", "o1", 0.85, "go"),
+				scoredMatch("c", "o2", 0.6, "react"),
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:   "near duplicate is demoted",
+			lambda: 0.5,
+			matches: []GroupMatch{
+				scoredMatch("a", "o1", 0.9, "go"),
+				scoredMatch("b", "o1", 0.85, "go"),
+				scoredMatch("c", "o2", 0.6, "react"),
+			},
+			want: []string{"a", "c", "b"},
+		},
+		{
+			name:   "same owner counts as similar",
+			lambda: 0.5,
+			matches: []GroupMatch{
+				scoredMatch("a", "o1", 0.9, "go"),
+				scoredMatch("b", "o1", 0.8, "rust"),
+				scoredMatch("c", "o2", 0.75, "vue"),
+			},
+			want: []string{"a", "c", "b"},
+		},
+		{
+			name:   "distinct results keep relevance order",
+			lambda: 0.5,
+			matches: []GroupMatch{
+				scoredMatch("a", "o1", 0.9, "go"),
+				scoredMatch("b", "o2", 0.8, "rust"),
+				scoredMatch("c", "o3", 0.7, "vue"),
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:    "no matches",
+			lambda:  0.5,
+			matches: []GroupMatch{},
+			want:    []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scores := make(map[string]float64, len(tc.matches))
+			for _, m := range tc.matches {
+				scores[m.Group.ID] = m.SimilarityScore
+			}
+
+			ranked := MMRReranker{Lambda: tc.lambda}.Rank(tc.matches, winter)
+
+			assert.Equal(t, tc.want, matchIDs(ranked))
+			for _, m := range ranked {
+				assert.Equal(t, scores[m.Group.ID], m.SimilarityScore, "scores are left untouched")
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/logger"
+)
+
+// Recommender periodically rebuilds item-based collaborative-filtering
+// recommendations: "people who joined groups like yours also joined".
+type Recommender struct {
+	repo     Repository
+	discover *DiscoverCache
+	config   cfg.GroupConfig
+	logger   logger.Logger
+}
+
+func NewRecommender(repo Repository, discover *DiscoverCache, config cfg.GroupConfig, logger logger.Logger) *Recommender {
+	return &Recommender{
+		repo:     repo,
+		discover: discover,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Refresh recomputes recommendations for every user with membership history
+func (r *Recommender) Refresh(ctx context.Context) error {
+	groups, err := r.repo.GetRecommendableGroups(ctx)
+	if err != nil {
+		return err
+	}
+
+	memberships, err := r.repo.GetAllMemberships(ctx)
+	if err != nil {
+		return err
+	}
+
+	recs := BuildRecommendations(groups, memberships, cfMaxPerUser)
+
+	err = r.repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
+		return r.repo.ReplaceRecommendations(ctx, tx, recs)
+	})
+	if err != nil {
+		r.logger.Error(ctx, "failed to store recommendations", logger.Field{Key: "error", Value: err})
+		return err
+	}
+
+	// Discover pages of signed-in users include recommended groups
+	r.discover.InvalidateAll(ctx)
+
+	r.logger.Info(ctx, "recommendations refreshed",
+		logger.Field{Key: "groups", Value: len(groups)},
+		logger.Field{Key: "recommendations", Value: len(recs)},
+	)
+
+	return nil
+}
+
+// Start refreshes recommendations now and every RecommendInterval until ctx is done
+func (r *Recommender) Start(ctx context.Context) {
+	if r.config.RecommendInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(r.config.RecommendInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Warn(ctx, "recommendation refresh failed", logger.Field{Key: "error", Value: err})
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// BuildRecommendations scores each joinable group for each user by its mean
+// similarity to the groups the user joined. Group similarity blends member
+// co-occurrence (cosine) with tag overlap (Jaccard). Every pair of a user's
+// groups and every joinable group is compared, which is fine at current scale.
+func BuildRecommendations(groups []*Group, memberships []*GroupMember, perUser int) []GroupRecommendation {
+	byID := make(map[string]*Group, len(groups))
+	joinable := make([]*Group, 0)
+	for _, g := range groups {
+		byID[g.ID] = g
+		if g.Status == StatusOpen && g.CurrentCount < g.Capacity {
+			joinable = append(joinable, g)
+		}
+	}
+
+	userGroups := make(map[string][]string)
+	memberCount := make(map[string]int)
+	for _, m := range memberships {
+		if _, ok := byID[m.GroupID]; !ok {
+			continue
+		}
+		userGroups[m.UserID] = append(userGroups[m.UserID], m.GroupID)
+		memberCount[m.GroupID]++
+	}
+
+	// Number of users who joined both groups of a pair
+	type pair struct{ a, b string }
+	cooccurrence := make(map[pair]int)
+	for _, joined := range userGroups {
+		for i := range joined {
+			for j := range joined {
+				if i != j {
+					cooccurrence[pair{joined[i], joined[j]}]++
+				}
+			}
+		}
+	}
+
+	similarity := func(a, b *Group) float64 {
+		cosine := 0.0
+		if n := cooccurrence[pair{a.ID, b.ID}]; n > 0 {
+			cosine = float64(n) / math.Sqrt(float64(memberCount[a.ID]*memberCount[b.ID]))
+		}
+		return cfMembershipWeight*cosine + cfTagWeight*CalculateJaccardScore(a.Tags, b.Tags)
+	}
+
+	userIDs := make([]string, 0, len(userGroups))
+	for userID := range userGroups {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	recs := make([]GroupRecommendation, 0)
+	for _, userID := range userIDs {
+		joined := make(map[string]bool)
+		for _, id := range userGroups[userID] {
+			joined[id] = true
+		}
+
+		scored := make([]GroupRecommendation, 0)
+		for _, candidate := range joinable {
+			if joined[candidate.ID] {
+				continue
+			}
+
+			total := 0.0
+			for id := range joined {
+				total += similarity(byID[id], candidate)
+			}
+
+			if score := total / float64(len(joined)); score > 0 {
+				scored = append(scored, GroupRecommendation{UserID: userID, GroupID: candidate.ID, Score: score})
+			}
+		}
+
+		sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+		if len(scored) > perUser {
+			scored = scored[:perUser]
+		}
+		recs = append(recs, scored...)
+	}
+
+	return recs
+}
+
+// CollaborativeMatcher implements GroupMatcher from precomputed
+// recommendations. Anonymous users and text searches get no matches, since
+// recommendations know nothing about the query. Searched tags filter the
+// recommendations like PostgresMatcher's candidates, widened to related tags
+// for expand searches.
+type CollaborativeMatcher struct {
+	repo     Repository
+	taxonomy TaxonomyProvider
+}
+
+func NewCollaborativeMatcher(repo Repository, taxonomy TaxonomyProvider) *CollaborativeMatcher {
+	return &CollaborativeMatcher{
+		repo:     repo,
+		taxonomy: taxonomy,
+	}
+}
+
+func (m *CollaborativeMatcher) FindMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	if userProfile.UserID == "" || strings.TrimSpace(filters.Query) != "" {
+		return []GroupMatch{}, nil
+	}
+
+	tags := userProfile.Tags
+	if filters.Expand && len(tags) > 0 {
+		tax, err := m.taxonomy.Taxonomy(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tags = expandTags(tags, tax)
+	}
+
+	return m.repo.GetRecommendedGroups(ctx, userProfile.UserID, tags, filters)
+}
@@ -0,0 +1,158 @@
+package group
+
+import (
+	"context"
+	"math"
+	"sort"
+	"testing"
+
+	"bmatch/pkg/taxonomy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGroup(id, status string, count int, tags ...string) *Group {
+	return &Group{ID: id, Status: status, Capacity: 5, CurrentCount: count, Tags: tags}
+}
+
+func member(userID, groupID string) *GroupMember {
+	return &GroupMember{UserID: userID, GroupID: groupID}
+}
+
+func TestBuildRecommendations(t *testing.T) {
+	cases := []struct {
+		name        string
+		groups      []*Group
+		memberships []*GroupMember
+		perUser     int
+		want        []string // user:group, best first per user
+	}{
+		{
+			name: "co-members and shared tags",
+			groups: []*Group{
+				testGroup("g1", StatusOpen, 2, "go"),
+				testGroup("g2", StatusOpen, 1, "go"),
+				testGroup("g3", StatusOpen, 1, "react"),
+			},
+			memberships: []*GroupMember{member("u1", "g1"), member("u2", "g1"), member("u2", "g2"), member("u3", "g3")},
+			perUser:     5,
+			want:        []string{"u1:g2"},
+		},
+		{
+			name: "full and closed groups are not recommended",
+			groups: []*Group{
+				testGroup("g1", StatusOpen, 1, "go"),
+				testGroup("full", StatusOpen, 5, "go"),
+				testGroup("closed", StatusClosed, 1, "go"),
+			},
+			memberships: []*GroupMember{member("u1", "g1")},
+			perUser:     5,
+			want:        []string{},
+		},
+		{
+			name: "ranked and limited per user",
+			groups: []*Group{
+				testGroup("g1", StatusOpen, 1, "go"),
+				testGroup("g2", StatusOpen, 1, "go", "react"),
+				testGroup("g3", StatusOpen, 1, "go"),
+				testGroup("g4", StatusOpen, 1, "go", "react", "vue"),
+			},
+			memberships: []*GroupMember{member("u1", "g1"), member("u2", "g4")},
+			perUser:     2,
+			want:        []string{"u1:g3", "u1:g2", "u2:g2", "u2:g1"},
+		},
+		{
+			name:        "memberships of unknown groups are ignored",
+			groups:      []*Group{testGroup("g1", StatusOpen, 1, "go")},
+			memberships: []*GroupMember{member("u1", "deleted")},
+			perUser:     5,
+			want:        []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recs := BuildRecommendations(tc.groups, tc.memberships, tc.perUser)
+
+			got := make([]string, 0, len(recs))
+			for _, r := range recs {
+				got = append(got, r.UserID+":"+r.GroupID)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("score blends cosine and jaccard", func(t *testing.T) {
+		groups := []*Group{testGroup("g1", StatusOpen, 2, "go"), testGroup("g2", StatusOpen, 1, "go", "react")}
+		memberships := []*GroupMember{member("u1", "g1"), member("u2", "g1"), member("u2", "g2")}
+
+		recs := BuildRecommendations(groups, memberships, 5)
+
+		// One co-member out of 2 and 1 members; one shared tag out of two
+		want := cfMembershipWeight*1/math.Sqrt(2) + cfTagWeight*0.5
+		if assert.Len(t, recs, 1) {
+			assert.InDelta(t, want, recs[0].Score, 1e-9)
+		}
+	})
+}
+
+// fixedTaxonomy serves one taxonomy
+type fixedTaxonomy struct {
+	tax *taxonomy.Taxonomy
+}
+
+func (f fixedTaxonomy) Taxonomy(context.Context) (*taxonomy.Taxonomy, error) {
+	return f.tax, nil
+}
+
+// recommendedRepo records the tags recommendations were filtered by
+type recommendedRepo struct {
+	Repository
+	tags  []string
+	calls int
+}
+
+func (r *recommendedRepo) GetRecommendedGroups(_ context.Context, _ string, tags []string, _ DiscoverGroupsRequest) ([]GroupMatch, error) {
+	r.calls++
+	r.tags = append([]string(nil), tags...)
+	sort.Strings(r.tags)
+	return []GroupMatch{}, nil
+}
+
+func TestCollaborativeMatcher_FindMatches(t *testing.T) {
+	tax := fixedTaxonomy{taxonomy.New(map[string]string{"go": "backend", "rust": "backend", "react": "frontend"})}
+
+	cases := []struct {
+		name    string
+		profile UserProfile
+		filters DiscoverGroupsRequest
+		calls   int
+		tags    []string
+	}{
+		{name: "filters by searched tags", profile: *testProfile("u1", IntentCasual, "go"), calls: 1, tags: []string{"go"}},
+		{
+			name:    "expand widens to related tags",
+			profile: *testProfile("u1", IntentCasual, "go"),
+			filters: DiscoverGroupsRequest{Expand: true},
+			calls:   1,
+			tags:    []string{"backend", "go", "rust"},
+		},
+		{name: "no tags recommends anything", profile: *testProfile("u1", IntentCasual), calls: 1},
+		{name: "anonymous users", profile: *testProfile("", IntentCasual, "go")},
+		{name: "text searches", profile: *testProfile("u1", IntentCasual, "go"), filters: DiscoverGroupsRequest{Query: "golang"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &recommendedRepo{}
+			matches, err := NewCollaborativeMatcher(repo, tax).FindMatches(context.Background(), tc.profile, tc.filters)
+			require.NoError(t, err)
+			assert.Empty(t, matches)
+			assert.Equal(t, tc.calls, repo.calls)
+			if tc.calls > 0 {
+				assert.Equal(t, tc.tags, repo.tags)
+			}
+		})
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode"
 
 	"bmatch/pkg/db"
+	"bmatch/pkg/geo"
 	"bmatch/pkg/logger"
 
 	"github.com/lib/pq"
@@ -16,16 +21,70 @@ type Repository interface {
 	// Group operations
 	CreateGroup(ctx context.Context, tx *sql.Tx, group *Group) error
 	GetGroupByID(ctx context.Context, groupID string) (*Group, error)
+	GetOpenSeats(ctx context.Context, groupIDs []string) (map[string]*GroupSeats, error)
 	GetGroupWithLock(ctx context.Context, tx *sql.Tx, groupID string) (*Group, error)
 	UpdateGroup(ctx context.Context, tx *sql.Tx, group *Group) error
-	FindGroupsByTags(ctx context.Context, tags []string, filters DiscoverGroupsRequest) ([]*Group, error)
+	FindGroupsByTags(ctx context.Context, userID string, tags []string, filters DiscoverGroupsRequest) ([]*GroupCandidate, error)
 	GetUserGroups(ctx context.Context, userID string) ([]*Group, error)
 
 	// Member operations
 	AddMember(ctx context.Context, tx *sql.Tx, member *GroupMember) error
+	RemoveMember(ctx context.Context, tx *sql.Tx, groupID, userID string) (*GroupMember, error)
 	IsMember(ctx context.Context, groupID, userID string) (bool, error)
 	GetGroupMembers(ctx context.Context, groupID string) ([]*GroupMember, error)
 	GetMemberCount(ctx context.Context, groupID string) (int, error)
+	GetMemberProfiles(ctx context.Context, groupID string) ([]*UserProfile, error)
+	GetMemberGroupIDs(ctx context.Context, userIDs []string) ([]string, error)
+	GetUserProfile(ctx context.Context, userID string) (*UserProfile, error)
+	GetUserProfiles(ctx context.Context, userIDs []string) ([]*UserProfile, error)
+
+	// Candidate and invite operations
+	FindCandidateUsers(ctx context.Context, group *Group, limit int) ([]*UserProfile, error)
+	IsInvitable(ctx context.Context, ownerID, userID string) (bool, error)
+	CreateInvite(ctx context.Context, invite *GroupInvite) error
+	GetInviteWithLock(ctx context.Context, tx *sql.Tx, groupID, userID string) (*GroupInvite, error)
+	UpdateInvite(ctx context.Context, tx *sql.Tx, invite *GroupInvite) error
+	GetUserInvites(ctx context.Context, userID string) ([]*GroupInvite, error)
+
+	// Buddy operations
+	FindBuddyCandidates(ctx context.Context, userID string, passedSince time.Time, limit int) ([]*UserProfile, error)
+	IsBuddyAvailable(ctx context.Context, userID, targetID string) (bool, error)
+	SaveBuddyReaction(ctx context.Context, tx *sql.Tx, userID, targetID, reaction string) error
+	HasBuddyInterest(ctx context.Context, tx *sql.Tx, userID, targetID string) (bool, error)
+	CreateBuddyMatch(ctx context.Context, tx *sql.Tx, groupID, userID, buddyID string) error
+	GetBuddyMatches(ctx context.Context, userID string) ([]*BuddyMatch, error)
+
+	// Recommendation operations
+	GetAllMemberships(ctx context.Context) ([]*GroupMember, error)
+	GetRecommendableGroups(ctx context.Context) ([]*Group, error)
+	ReplaceRecommendations(ctx context.Context, tx *sql.Tx, recs []GroupRecommendation) error
+	GetRecommendedGroups(ctx context.Context, userID string, tags []string, filters DiscoverGroupsRequest) ([]GroupMatch, error)
+
+	// Auto-formation operations
+	FindAutoMatchUsers(ctx context.Context) ([]*UserProfile, error)
+
+	// Group profile operations
+	SaveGroupProfile(ctx context.Context, profile *GroupProfile) error
+	GetGroupProfile(ctx context.Context, groupID string) (*GroupProfile, error)
+	GetGroupProfiles(ctx context.Context, groupIDs []string) (map[string]*GroupProfile, error)
+
+	// Saved search operations
+	CreateSavedSearch(ctx context.Context, search *SavedSearch) error
+	CountSavedSearches(ctx context.Context, userID string) (int, error)
+	GetSavedSearch(ctx context.Context, searchID, userID string) (*SavedSearch, error)
+	GetUserSavedSearches(ctx context.Context, userID string) ([]*SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, search *SavedSearch) error
+	DeleteSavedSearch(ctx context.Context, searchID, userID string) error
+	FindSavedSearchesForGroup(ctx context.Context, group *Group) ([]*SavedSearch, error)
+	MarkSavedSearchesMatched(ctx context.Context, searchIDs []string, at time.Time) error
+	GroupTextRank(ctx context.Context, groupID, q string) (float64, bool, error)
+
+	// Bookmark operations
+	AddBookmark(ctx context.Context, tx *sql.Tx, userID, groupID string) (bool, error)
+	RemoveBookmark(ctx context.Context, tx *sql.Tx, userID, groupID string) (bool, error)
+	AdjustBookmarkCount(ctx context.Context, tx *sql.Tx, groupID string, delta int) error
+	GetUserBookmarks(ctx context.Context, userID string) ([]*Bookmark, error)
+	GetBookmarkerIDs(ctx context.Context, groupID string) ([]string, error)
 
 	// Transaction helper
 	WithTransaction(ctx context.Context, isolation sql.IsolationLevel, fn db.TxFunc) error
@@ -55,9 +114,22 @@ func (r *repository) CreateGroup(ctx context.Context, tx *sql.Tx, group *Group)
 		return fmt.Errorf("marshal applications: %w", err)
 	}
 
+	scheduleJSON, err := json.Marshal(group.Schedule)
+	if err != nil {
+		return fmt.Errorf("marshal schedule: %w", err)
+	}
+
+	slotsJSON, err := json.Marshal(group.RoleSlots)
+	if err != nil {
+		return fmt.Errorf("marshal role slots: %w", err)
+	}
+
+	lat, lon := pointArgs(group.Location)
+
 	query := `
-		INSERT INTO groups (id, owner_id, title, description, proposal, tags, capacity, current_count, join_type, status, applications)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO groups (id, owner_id, title, description, proposal, tags, capacity, current_count, join_type, status, applications, schedule,
+		                    meeting_mode, latitude, longitude, city, time_zone, language, required_proficiency, role_slots)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		RETURNING created_at, updated_at
 	`
 
@@ -73,6 +145,15 @@ func (r *repository) CreateGroup(ctx context.Context, tx *sql.Tx, group *Group)
 		group.JoinType,
 		group.Status,
 		appsJSON,
+		scheduleJSON,
+		group.MeetingMode,
+		lat,
+		lon,
+		group.City,
+		group.TimeZone,
+		group.Language,
+		group.RequiredProficiency,
+		slotsJSON,
 	).Scan(&group.CreatedAt, &group.UpdatedAt)
 
 	if err != nil {
@@ -86,13 +167,16 @@ func (r *repository) CreateGroup(ctx context.Context, tx *sql.Tx, group *Group)
 func (r *repository) GetGroupByID(ctx context.Context, groupID string) (*Group, error) {
 	query := `
 		SELECT id, owner_id, title, description, proposal, tags, capacity, current_count, 
-		       join_type, status, applications, created_at, updated_at
+		       join_type, status, applications, schedule, meeting_mode, latitude, longitude, city, time_zone,
+		       language, required_proficiency, role_slots, bookmark_count,
+		       created_at, updated_at
 		FROM groups
 		WHERE id = $1
 	`
 
 	var group Group
-	var tagsJSON, appsJSON []byte
+	var tagsJSON, appsJSON, scheduleJSON, slotsJSON []byte
+	var lat, lon sql.NullFloat64
 
 	err := r.db.QueryRowContext(ctx, query, groupID).Scan(
 		&group.ID,
@@ -106,6 +190,16 @@ func (r *repository) GetGroupByID(ctx context.Context, groupID string) (*Group,
 		&group.JoinType,
 		&group.Status,
 		&appsJSON,
+		&scheduleJSON,
+		&group.MeetingMode,
+		&lat,
+		&lon,
+		&group.City,
+		&group.TimeZone,
+		&group.Language,
+		&group.RequiredProficiency,
+		&slotsJSON,
+		&group.BookmarkCount,
 		&group.CreatedAt,
 		&group.UpdatedAt,
 	)
@@ -125,21 +219,75 @@ func (r *repository) GetGroupByID(ctx context.Context, groupID string) (*Group,
 		return nil, fmt.Errorf("unmarshal applications: %w", err)
 	}
 
+	if err := json.Unmarshal(scheduleJSON, &group.Schedule); err != nil {
+		return nil, fmt.Errorf("unmarshal schedule: %w", err)
+	}
+
+	if err := json.Unmarshal(slotsJSON, &group.RoleSlots); err != nil {
+		return nil, fmt.Errorf("unmarshal role slots: %w", err)
+	}
+
+	group.Location = scanPoint(lat, lon)
+
 	return &group, nil
 }
 
+// GetOpenSeats returns the current count and role slots of the given groups
+// that are still OPEN with free seats; other groups are left out
+func (r *repository) GetOpenSeats(ctx context.Context, groupIDs []string) (map[string]*GroupSeats, error) {
+	seats := make(map[string]*GroupSeats, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return seats, nil
+	}
+
+	query := `
+		SELECT id, current_count, role_slots
+		FROM groups
+		WHERE id = ANY($1::uuid[])
+		  AND status = 'OPEN'
+		  AND current_count < capacity
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(groupIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query open seats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var s GroupSeats
+		var slotsJSON []byte
+
+		if err := rows.Scan(&id, &s.CurrentCount, &slotsJSON); err != nil {
+			return nil, fmt.Errorf("scan open seats: %w", err)
+		}
+
+		if err := json.Unmarshal(slotsJSON, &s.RoleSlots); err != nil {
+			return nil, fmt.Errorf("unmarshal role slots: %w", err)
+		}
+
+		seats[id] = &s
+	}
+
+	return seats, rows.Err()
+}
+
 // GetGroupWithLock retrieves a group with row-level lock for updates
 func (r *repository) GetGroupWithLock(ctx context.Context, tx *sql.Tx, groupID string) (*Group, error) {
 	query := `
 		SELECT id, owner_id, title, description, proposal, tags, capacity, current_count, 
-		       join_type, status, applications, created_at, updated_at
+		       join_type, status, applications, schedule, meeting_mode, latitude, longitude, city, time_zone,
+		       language, required_proficiency, role_slots, bookmark_count,
+		       created_at, updated_at
 		FROM groups
 		WHERE id = $1
 		FOR UPDATE
 	`
 
 	var group Group
-	var tagsJSON, appsJSON []byte
+	var tagsJSON, appsJSON, scheduleJSON, slotsJSON []byte
+	var lat, lon sql.NullFloat64
 
 	err := tx.QueryRowContext(ctx, query, groupID).Scan(
 		&group.ID,
@@ -153,6 +301,16 @@ func (r *repository) GetGroupWithLock(ctx context.Context, tx *sql.Tx, groupID s
 		&group.JoinType,
 		&group.Status,
 		&appsJSON,
+		&scheduleJSON,
+		&group.MeetingMode,
+		&lat,
+		&lon,
+		&group.City,
+		&group.TimeZone,
+		&group.Language,
+		&group.RequiredProficiency,
+		&slotsJSON,
+		&group.BookmarkCount,
 		&group.CreatedAt,
 		&group.UpdatedAt,
 	)
@@ -172,10 +330,21 @@ func (r *repository) GetGroupWithLock(ctx context.Context, tx *sql.Tx, groupID s
 		return nil, fmt.Errorf("unmarshal applications: %w", err)
 	}
 
+	if err := json.Unmarshal(scheduleJSON, &group.Schedule); err != nil {
+		return nil, fmt.Errorf("unmarshal schedule: %w", err)
+	}
+
+	if err := json.Unmarshal(slotsJSON, &group.RoleSlots); err != nil {
+		return nil, fmt.Errorf("unmarshal role slots: %w", err)
+	}
+
+	group.Location = scanPoint(lat, lon)
+
 	return &group, nil
 }
 
-// UpdateGroup updates a group
+// UpdateGroup updates a group and bumps updated_at, which the popularity
+// boost treats as the last activity
 func (r *repository) UpdateGroup(ctx context.Context, tx *sql.Tx, group *Group) error {
 	tagsJSON, err := json.Marshal(group.Tags)
 	if err != nil {
@@ -187,14 +356,29 @@ func (r *repository) UpdateGroup(ctx context.Context, tx *sql.Tx, group *Group)
 		return fmt.Errorf("marshal applications: %w", err)
 	}
 
+	scheduleJSON, err := json.Marshal(group.Schedule)
+	if err != nil {
+		return fmt.Errorf("marshal schedule: %w", err)
+	}
+
+	slotsJSON, err := json.Marshal(group.RoleSlots)
+	if err != nil {
+		return fmt.Errorf("marshal role slots: %w", err)
+	}
+
+	lat, lon := pointArgs(group.Location)
+
 	query := `
 		UPDATE groups
 		SET title = $2, description = $3, proposal = $4, tags = $5, 
-		    capacity = $6, current_count = $7, join_type = $8, status = $9, applications = $10
+		    capacity = $6, current_count = $7, join_type = $8, status = $9, applications = $10, schedule = $11,
+		    meeting_mode = $12, latitude = $13, longitude = $14, city = $15, time_zone = $16,
+		    language = $17, required_proficiency = $18, role_slots = $19, updated_at = NOW()
 		WHERE id = $1
+		RETURNING updated_at
 	`
 
-	result, err := tx.ExecContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		group.ID,
 		group.Title,
 		group.Description,
@@ -205,40 +389,59 @@ func (r *repository) UpdateGroup(ctx context.Context, tx *sql.Tx, group *Group)
 		group.JoinType,
 		group.Status,
 		appsJSON,
-	)
-
-	if err != nil {
-		return fmt.Errorf("update group: %w", err)
+		scheduleJSON,
+		group.MeetingMode,
+		lat,
+		lon,
+		group.City,
+		group.TimeZone,
+		group.Language,
+		group.RequiredProficiency,
+		slotsJSON,
+	).Scan(&group.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrGroupNotFound
 	}
-
-	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("get rows affected: %w", err)
-	}
-
-	if rows == 0 {
-		return ErrGroupNotFound
+		return fmt.Errorf("update group: %w", err)
 	}
 
 	return nil
 }
 
-// FindGroupsByTags finds groups using GIN index on tags
-func (r *repository) FindGroupsByTags(ctx context.Context, tags []string, filters DiscoverGroupsRequest) ([]*Group, error) {
-	fmt.Printf("DEBUG: Received tags: %+v (length: %d)\n", tags, len(tags))
-	query := `
+// FindGroupsByTags finds groups using GIN indexes on tags and, when a text
+// query is given, on the full-text search vector. When userID is set the
+// caller's own groups are excluded, as are groups they joined or applied to
+// unless the filters bring them back.
+func (r *repository) FindGroupsByTags(ctx context.Context, userID string, tags []string, filters DiscoverGroupsRequest) ([]*GroupCandidate, error) {
+	args := []interface{}{}
+	argIdx := 1
+
+	rankExpr := "0::float8"
+	tsQueryExpr := ""
+	if tsQuery, prefix := buildTSQuery(filters.Query); tsQuery != "" {
+		tsQueryExpr = tsQueryCall(prefix, argIdx)
+		// Normalization flag 32 scales rank into [0, 1)
+		rankExpr = fmt.Sprintf("ts_rank(search_vector, %s, 32)", tsQueryExpr)
+		args = append(args, tsQuery)
+		argIdx++
+	}
+
+	query := fmt.Sprintf(`
         SELECT id, owner_id, title, description, proposal, tags, capacity, current_count, 
-               join_type, status, applications, created_at, updated_at
+               join_type, status, applications, schedule, meeting_mode, latitude, longitude, city, time_zone,
+		       language, required_proficiency, role_slots, bookmark_count,
+		       created_at, updated_at, %s AS text_rank
         FROM groups
         WHERE status = 'OPEN'
           AND current_count < capacity
-    `
+    `, rankExpr)
 
-	args := []interface{}{}
-	argIdx := 1
+	if tsQueryExpr != "" {
+		query += fmt.Sprintf(" AND search_vector @@ %s", tsQueryExpr)
+	}
 
 	if len(tags) > 0 {
-		fmt.Printf("DEBUG: pq.Array(tags): %+v\n", pq.Array(tags))
 		query += fmt.Sprintf(" AND tags ?| $%d", argIdx)
 		args = append(args, pq.Array(tags)) // Use pq.Array instead of json.Marshal
 		argIdx++
@@ -250,7 +453,50 @@ func (r *repository) FindGroupsByTags(ctx context.Context, tags []string, filter
 		argIdx++
 	}
 
-	query += " ORDER BY created_at DESC"
+	if filters.MeetingMode != "" {
+		query += fmt.Sprintf(" AND meeting_mode = $%d", argIdx)
+		args = append(args, filters.MeetingMode)
+		argIdx++
+	}
+
+	if filters.LanguageMode == LanguageModeFilter {
+		query += fmt.Sprintf(" AND language = ANY($%d)", argIdx)
+		args = append(args, pq.Array(filters.Languages))
+		argIdx++
+	}
+
+	locConds, locArgs, argIdx := locationConditions(filters, "", argIdx)
+	query += locConds
+	args = append(args, locArgs...)
+
+	if userID != "" {
+		query += fmt.Sprintf(" AND owner_id <> $%d::uuid", argIdx)
+		if !filters.IncludeJoined {
+			query += fmt.Sprintf(`
+          AND NOT EXISTS (
+              SELECT 1 FROM group_members gm
+              WHERE gm.group_id = groups.id AND gm.user_id = $%d::uuid
+          )`, argIdx)
+		}
+		if !filters.IncludeApplied {
+			query += fmt.Sprintf(`
+          AND NOT applications @> jsonb_build_array(jsonb_build_object('user_id', $%d::uuid::text, 'status', '%s'))`,
+				argIdx, ApplicationStatusPending)
+		}
+		if !filters.IncludeRejected {
+			query += fmt.Sprintf(`
+          AND NOT applications @> jsonb_build_array(jsonb_build_object('user_id', $%d::uuid::text, 'status', '%s'))`,
+				argIdx, ApplicationStatusRejected)
+		}
+		args = append(args, userID)
+		argIdx++
+	}
+
+	if tsQueryExpr != "" {
+		query += " ORDER BY text_rank DESC, created_at DESC"
+	} else {
+		query += " ORDER BY created_at DESC"
+	}
 
 	limit := filters.Limit
 	if limit == 0 {
@@ -266,10 +512,12 @@ func (r *repository) FindGroupsByTags(ctx context.Context, tags []string, filter
 	}
 	defer rows.Close()
 
-	groups := make([]*Group, 0)
+	candidates := make([]*GroupCandidate, 0)
 	for rows.Next() {
 		var group Group
-		var tagsJSON, appsJSON []byte
+		var tagsJSON, appsJSON, scheduleJSON, slotsJSON []byte
+		var lat, lon sql.NullFloat64
+		var textRank float64
 
 		err := rows.Scan(
 			&group.ID,
@@ -283,8 +531,19 @@ func (r *repository) FindGroupsByTags(ctx context.Context, tags []string, filter
 			&group.JoinType,
 			&group.Status,
 			&appsJSON,
+			&scheduleJSON,
+			&group.MeetingMode,
+			&lat,
+			&lon,
+			&group.City,
+			&group.TimeZone,
+			&group.Language,
+			&group.RequiredProficiency,
+			&slotsJSON,
+			&group.BookmarkCount,
 			&group.CreatedAt,
 			&group.UpdatedAt,
+			&textRank,
 		)
 
 		if err != nil {
@@ -299,17 +558,31 @@ func (r *repository) FindGroupsByTags(ctx context.Context, tags []string, filter
 			return nil, fmt.Errorf("unmarshal applications: %w", err)
 		}
 
-		groups = append(groups, &group)
+		if err := json.Unmarshal(scheduleJSON, &group.Schedule); err != nil {
+			return nil, fmt.Errorf("unmarshal schedule: %w", err)
+		}
+
+		if err := json.Unmarshal(slotsJSON, &group.RoleSlots); err != nil {
+			return nil, fmt.Errorf("unmarshal role slots: %w", err)
+		}
+
+		group.Location = scanPoint(lat, lon)
+
+		candidates = append(candidates, &GroupCandidate{
+			Group:    &group,
+			TextRank: textRank,
+		})
 	}
 
-	return groups, nil
+	return candidates, nil
 }
 
 // GetUserGroups retrieves all groups a user is a member of
 func (r *repository) GetUserGroups(ctx context.Context, userID string) ([]*Group, error) {
 	query := `
 		SELECT g.id, g.owner_id, g.title, g.description, g.proposal, g.tags, g.capacity, 
-		       g.current_count, g.join_type, g.status, g.applications, g.created_at, g.updated_at
+		       g.current_count, g.join_type, g.status, g.applications, g.schedule, g.meeting_mode,
+		       g.latitude, g.longitude, g.city, g.time_zone, g.language, g.required_proficiency, g.role_slots, g.bookmark_count, g.created_at, g.updated_at
 		FROM groups g
 		INNER JOIN group_members gm ON g.id = gm.group_id
 		WHERE gm.user_id = $1
@@ -325,7 +598,8 @@ func (r *repository) GetUserGroups(ctx context.Context, userID string) ([]*Group
 	groups := make([]*Group, 0)
 	for rows.Next() {
 		var group Group
-		var tagsJSON, appsJSON []byte
+		var tagsJSON, appsJSON, scheduleJSON, slotsJSON []byte
+		var lat, lon sql.NullFloat64
 
 		err := rows.Scan(
 			&group.ID,
@@ -339,6 +613,16 @@ func (r *repository) GetUserGroups(ctx context.Context, userID string) ([]*Group
 			&group.JoinType,
 			&group.Status,
 			&appsJSON,
+			&scheduleJSON,
+			&group.MeetingMode,
+			&lat,
+			&lon,
+			&group.City,
+			&group.TimeZone,
+			&group.Language,
+			&group.RequiredProficiency,
+			&slotsJSON,
+			&group.BookmarkCount,
 			&group.CreatedAt,
 			&group.UpdatedAt,
 		)
@@ -355,6 +639,16 @@ func (r *repository) GetUserGroups(ctx context.Context, userID string) ([]*Group
 			return nil, fmt.Errorf("unmarshal applications: %w", err)
 		}
 
+		if err := json.Unmarshal(scheduleJSON, &group.Schedule); err != nil {
+			return nil, fmt.Errorf("unmarshal schedule: %w", err)
+		}
+
+		if err := json.Unmarshal(slotsJSON, &group.RoleSlots); err != nil {
+			return nil, fmt.Errorf("unmarshal role slots: %w", err)
+		}
+
+		group.Location = scanPoint(lat, lon)
+
 		groups = append(groups, &group)
 	}
 
@@ -364,12 +658,12 @@ func (r *repository) GetUserGroups(ctx context.Context, userID string) ([]*Group
 // AddMember adds a member to a group
 func (r *repository) AddMember(ctx context.Context, tx *sql.Tx, member *GroupMember) error {
 	query := `
-		INSERT INTO group_members (group_id, user_id, role)
-		VALUES ($1, $2, $3)
+		INSERT INTO group_members (group_id, user_id, role, slot)
+		VALUES ($1, $2, $3, $4)
 		RETURNING joined_at
 	`
 
-	err := tx.QueryRowContext(ctx, query, member.GroupID, member.UserID, member.Role).Scan(&member.JoinedAt)
+	err := tx.QueryRowContext(ctx, query, member.GroupID, member.UserID, member.Role, member.Slot).Scan(&member.JoinedAt)
 	if err != nil {
 		return fmt.Errorf("insert member: %w", err)
 	}
@@ -377,6 +671,32 @@ func (r *repository) AddMember(ctx context.Context, tx *sql.Tx, member *GroupMem
 	return nil
 }
 
+// RemoveMember deletes a membership and returns it
+func (r *repository) RemoveMember(ctx context.Context, tx *sql.Tx, groupID, userID string) (*GroupMember, error) {
+	query := `
+		DELETE FROM group_members
+		WHERE group_id = $1 AND user_id = $2
+		RETURNING group_id, user_id, role, slot, joined_at
+	`
+
+	var member GroupMember
+	err := tx.QueryRowContext(ctx, query, groupID, userID).Scan(
+		&member.GroupID,
+		&member.UserID,
+		&member.Role,
+		&member.Slot,
+		&member.JoinedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("delete member: %w", err)
+	}
+
+	return &member, nil
+}
+
 // IsMember checks if a user is a member of a group
 func (r *repository) IsMember(ctx context.Context, groupID, userID string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM group_members WHERE group_id = $1 AND user_id = $2)`
@@ -393,7 +713,7 @@ func (r *repository) IsMember(ctx context.Context, groupID, userID string) (bool
 // GetGroupMembers retrieves all members of a group
 func (r *repository) GetGroupMembers(ctx context.Context, groupID string) ([]*GroupMember, error) {
 	query := `
-		SELECT group_id, user_id, role, joined_at
+		SELECT group_id, user_id, role, slot, joined_at
 		FROM group_members
 		WHERE group_id = $1
 		ORDER BY joined_at ASC
@@ -408,7 +728,7 @@ func (r *repository) GetGroupMembers(ctx context.Context, groupID string) ([]*Gr
 	members := make([]*GroupMember, 0)
 	for rows.Next() {
 		var member GroupMember
-		err := rows.Scan(&member.GroupID, &member.UserID, &member.Role, &member.JoinedAt)
+		err := rows.Scan(&member.GroupID, &member.UserID, &member.Role, &member.Slot, &member.JoinedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scan member: %w", err)
 		}
@@ -431,7 +751,1351 @@ func (r *repository) GetMemberCount(ctx context.Context, groupID string) (int, e
 	return count, nil
 }
 
-// WithTransaction executes a function within a database transaction
-func (r *repository) WithTransaction(ctx context.Context, isolation sql.IsolationLevel, fn db.TxFunc) error {
-	return r.db.WithTransaction(ctx, isolation, fn)
+// GetMemberGroupIDs retrieves the IDs of every group any of the users belongs to
+func (r *repository) GetMemberGroupIDs(ctx context.Context, userIDs []string) ([]string, error) {
+	groupIDs := make([]string, 0)
+	if len(userIDs) == 0 {
+		return groupIDs, nil
+	}
+
+	query := `
+		SELECT DISTINCT group_id
+		FROM group_members
+		WHERE user_id = ANY($1::uuid[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query member group ids: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan member group id: %w", err)
+		}
+		groupIDs = append(groupIDs, id)
+	}
+
+	return groupIDs, rows.Err()
+}
+
+// GetMemberProfiles retrieves the matching profiles of all members of a group
+func (r *repository) GetMemberProfiles(ctx context.Context, groupID string) ([]*UserProfile, error) {
+	query := `
+		SELECT u.id, COALESCE(u.full_name, ''), u.tags, u.skill_level, u.availability, u.schedule, u.intent,
+		       u.latitude, u.longitude, u.city, u.time_zone, u.languages
+		FROM users u
+		INNER JOIN group_members gm ON gm.user_id = u.id
+		WHERE gm.group_id = $1
+		ORDER BY gm.joined_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("query member profiles: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserProfiles(rows)
+}
+
+// GetUserProfile retrieves the matching profile of a single user
+func (r *repository) GetUserProfile(ctx context.Context, userID string) (*UserProfile, error) {
+	query := `
+		SELECT u.id, COALESCE(u.full_name, ''), u.tags, u.skill_level, u.availability, u.schedule, u.intent,
+		       u.latitude, u.longitude, u.city, u.time_zone, u.languages
+		FROM users u
+		WHERE u.id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query user profile: %w", err)
+	}
+	defer rows.Close()
+
+	profiles, err := scanUserProfiles(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return profiles[0], nil
+}
+
+// GetUserProfiles retrieves the matching profiles of several users, leaving
+// out users that do not exist
+func (r *repository) GetUserProfiles(ctx context.Context, userIDs []string) ([]*UserProfile, error) {
+	if len(userIDs) == 0 {
+		return []*UserProfile{}, nil
+	}
+
+	query := `
+		SELECT u.id, COALESCE(u.full_name, ''), u.tags, u.skill_level, u.availability, u.schedule, u.intent,
+		       u.latitude, u.longitude, u.city, u.time_zone, u.languages
+		FROM users u
+		WHERE u.id = ANY($1::uuid[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query user profiles: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserProfiles(rows)
+}
+
+// FindCandidateUsers finds users open to invites who share at least one tag
+// with the group. Members, users already invited and users with a block in
+// either direction with the owner are excluded.
+func (r *repository) FindCandidateUsers(ctx context.Context, group *Group, limit int) ([]*UserProfile, error) {
+	query := `
+		SELECT u.id, COALESCE(u.full_name, ''), u.tags, u.skill_level, u.availability, u.schedule, u.intent,
+		       u.latitude, u.longitude, u.city, u.time_zone, u.languages
+		FROM users u
+		WHERE u.open_to_invites
+		  AND u.tags ?| $2
+		  AND NOT EXISTS (SELECT 1 FROM group_members gm WHERE gm.group_id = $1 AND gm.user_id = u.id)
+		  AND NOT EXISTS (SELECT 1 FROM group_invites gi WHERE gi.group_id = $1 AND gi.user_id = u.id)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM user_blocks b
+		      WHERE (b.blocker_id = $3 AND b.blocked_id = u.id)
+		         OR (b.blocker_id = u.id AND b.blocked_id = $3)
+		  )
+		ORDER BY u.updated_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, group.ID, pq.Array(group.Tags), group.OwnerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query candidate users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserProfiles(rows)
+}
+
+// IsInvitable checks that a user is open to invites and has no block with the owner
+func (r *repository) IsInvitable(ctx context.Context, ownerID, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM users u
+			WHERE u.id = $2
+			  AND u.open_to_invites
+			  AND NOT EXISTS (
+			      SELECT 1 FROM user_blocks b
+			      WHERE (b.blocker_id = $1 AND b.blocked_id = $2)
+			         OR (b.blocker_id = $2 AND b.blocked_id = $1)
+			  )
+		)
+	`
+
+	var invitable bool
+	if err := r.db.QueryRowContext(ctx, query, ownerID, userID).Scan(&invitable); err != nil {
+		return false, fmt.Errorf("check invitable: %w", err)
+	}
+
+	return invitable, nil
+}
+
+// CreateInvite creates a pending invite
+func (r *repository) CreateInvite(ctx context.Context, invite *GroupInvite) error {
+	query := `
+		INSERT INTO group_invites (group_id, user_id, invited_by, status)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, invite.GroupID, invite.UserID, invite.InvitedBy, invite.Status).Scan(&invite.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrInviteExists
+	}
+	if err != nil {
+		return fmt.Errorf("insert invite: %w", err)
+	}
+
+	return nil
+}
+
+// GetInviteWithLock retrieves an invite with row-level lock for updates
+func (r *repository) GetInviteWithLock(ctx context.Context, tx *sql.Tx, groupID, userID string) (*GroupInvite, error) {
+	query := `
+		SELECT group_id, user_id, invited_by, status, created_at, decided_at
+		FROM group_invites
+		WHERE group_id = $1 AND user_id = $2
+		FOR UPDATE
+	`
+
+	var invite GroupInvite
+	err := tx.QueryRowContext(ctx, query, groupID, userID).Scan(
+		&invite.GroupID,
+		&invite.UserID,
+		&invite.InvitedBy,
+		&invite.Status,
+		&invite.CreatedAt,
+		&invite.DecidedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query invite with lock: %w", err)
+	}
+
+	return &invite, nil
+}
+
+// UpdateInvite updates the status of an invite
+func (r *repository) UpdateInvite(ctx context.Context, tx *sql.Tx, invite *GroupInvite) error {
+	query := `UPDATE group_invites SET status = $3, decided_at = $4 WHERE group_id = $1 AND user_id = $2`
+
+	result, err := tx.ExecContext(ctx, query, invite.GroupID, invite.UserID, invite.Status, invite.DecidedAt)
+	if err != nil {
+		return fmt.Errorf("update invite: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrInviteNotFound
+	}
+
+	return nil
+}
+
+// GetUserInvites retrieves pending invites for a user
+func (r *repository) GetUserInvites(ctx context.Context, userID string) ([]*GroupInvite, error) {
+	query := `
+		SELECT group_id, user_id, invited_by, status, created_at, decided_at
+		FROM group_invites
+		WHERE user_id = $1 AND status = $2
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, InviteStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("query user invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := make([]*GroupInvite, 0)
+	for rows.Next() {
+		var invite GroupInvite
+		err := rows.Scan(
+			&invite.GroupID,
+			&invite.UserID,
+			&invite.InvitedBy,
+			&invite.Status,
+			&invite.CreatedAt,
+			&invite.DecidedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan invite: %w", err)
+		}
+		invites = append(invites, &invite)
+	}
+
+	return invites, nil
+}
+
+// FindBuddyCandidates finds users in buddy mode the user has not reacted to,
+// is not paired with and has no block with. A pass older than passedSince
+// no longer hides the user.
+func (r *repository) FindBuddyCandidates(ctx context.Context, userID string, passedSince time.Time, limit int) ([]*UserProfile, error) {
+	query := `
+		SELECT u.id, COALESCE(u.full_name, ''), u.tags, u.skill_level, u.availability, u.schedule, u.intent,
+		       u.latitude, u.longitude, u.city, u.time_zone, u.languages
+		FROM users u
+		WHERE u.buddy_mode
+		  AND u.id <> $1
+		  AND NOT EXISTS (
+		      SELECT 1 FROM buddy_reactions br
+		      WHERE br.user_id = $1 AND br.target_id = u.id
+		        AND (br.reaction = $2 OR br.created_at > $3)
+		  )
+		  AND NOT EXISTS (
+		      SELECT 1 FROM buddy_matches bm
+		      WHERE (bm.user_a = $1 AND bm.user_b = u.id)
+		         OR (bm.user_a = u.id AND bm.user_b = $1)
+		  )
+		  AND NOT EXISTS (
+		      SELECT 1 FROM user_blocks b
+		      WHERE (b.blocker_id = $1 AND b.blocked_id = u.id)
+		         OR (b.blocker_id = u.id AND b.blocked_id = $1)
+		  )
+		ORDER BY u.updated_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, BuddyReactionInterest, passedSince, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query buddy candidates: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserProfiles(rows)
+}
+
+// IsBuddyAvailable reports whether target is in buddy mode with no block
+// in either direction with the user
+func (r *repository) IsBuddyAvailable(ctx context.Context, userID, targetID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+		    SELECT 1 FROM users u
+		    WHERE u.id = $2
+		      AND u.buddy_mode
+		      AND NOT EXISTS (
+		          SELECT 1 FROM user_blocks b
+		          WHERE (b.blocker_id = $1 AND b.blocked_id = u.id)
+		             OR (b.blocker_id = u.id AND b.blocked_id = $1)
+		      )
+		)
+	`
+
+	var available bool
+	if err := r.db.QueryRowContext(ctx, query, userID, targetID).Scan(&available); err != nil {
+		return false, fmt.Errorf("check buddy availability: %w", err)
+	}
+
+	return available, nil
+}
+
+// SaveBuddyReaction records the user's latest reaction to target
+func (r *repository) SaveBuddyReaction(ctx context.Context, tx *sql.Tx, userID, targetID, reaction string) error {
+	query := `
+		INSERT INTO buddy_reactions (user_id, target_id, reaction, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, target_id)
+		DO UPDATE SET reaction = EXCLUDED.reaction, created_at = EXCLUDED.created_at
+	`
+
+	if _, err := tx.ExecContext(ctx, query, userID, targetID, reaction); err != nil {
+		return fmt.Errorf("save buddy reaction: %w", err)
+	}
+
+	return nil
+}
+
+// HasBuddyInterest reports whether userID expressed interest in targetID
+func (r *repository) HasBuddyInterest(ctx context.Context, tx *sql.Tx, userID, targetID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+		    SELECT 1 FROM buddy_reactions
+		    WHERE user_id = $1 AND target_id = $2 AND reaction = $3
+		)
+	`
+
+	var interested bool
+	if err := tx.QueryRowContext(ctx, query, userID, targetID, BuddyReactionInterest).Scan(&interested); err != nil {
+		return false, fmt.Errorf("check buddy interest: %w", err)
+	}
+
+	return interested, nil
+}
+
+// CreateBuddyMatch links a pair group to its two users
+func (r *repository) CreateBuddyMatch(ctx context.Context, tx *sql.Tx, groupID, userID, buddyID string) error {
+	userA, userB := userID, buddyID
+	if userB < userA {
+		userA, userB = userB, userA
+	}
+
+	query := `
+		INSERT INTO buddy_matches (group_id, user_a, user_b)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_a, user_b) DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query, groupID, userA, userB)
+	if err != nil {
+		return fmt.Errorf("insert buddy match: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return ErrAlreadyBuddies
+	}
+
+	return nil
+}
+
+// GetBuddyMatches retrieves the user's buddies, newest first
+func (r *repository) GetBuddyMatches(ctx context.Context, userID string) ([]*BuddyMatch, error) {
+	query := `
+		SELECT bm.group_id, bm.created_at,
+		       u.id, COALESCE(u.full_name, ''), u.tags, u.skill_level, u.availability, u.schedule, u.intent,
+		       u.latitude, u.longitude, u.city, u.time_zone, u.languages
+		FROM buddy_matches bm
+		INNER JOIN users u ON u.id = CASE WHEN bm.user_a = $1 THEN bm.user_b ELSE bm.user_a END
+		WHERE bm.user_a = $1 OR bm.user_b = $1
+		ORDER BY bm.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query buddy matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]*BuddyMatch, 0)
+	for rows.Next() {
+		var m BuddyMatch
+		var p UserProfile
+		var tagsJSON, availabilityJSON, scheduleJSON, languagesJSON []byte
+		var lat, lon sql.NullFloat64
+
+		if err := rows.Scan(&m.GroupID, &m.CreatedAt,
+			&p.UserID, &p.FullName, &tagsJSON, &p.SkillLevel, &availabilityJSON, &scheduleJSON, &p.Intent,
+			&lat, &lon, &p.City, &p.TimeZone, &languagesJSON); err != nil {
+			return nil, fmt.Errorf("scan buddy match: %w", err)
+		}
+
+		if err := json.Unmarshal(tagsJSON, &p.Tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+
+		if err := json.Unmarshal(availabilityJSON, &p.Availability); err != nil {
+			return nil, fmt.Errorf("unmarshal availability: %w", err)
+		}
+
+		if err := json.Unmarshal(scheduleJSON, &p.Schedule); err != nil {
+			return nil, fmt.Errorf("unmarshal schedule: %w", err)
+		}
+
+		p.Location = scanPoint(lat, lon)
+
+		if err := json.Unmarshal(languagesJSON, &p.Languages); err != nil {
+			return nil, fmt.Errorf("unmarshal languages: %w", err)
+		}
+
+		m.Buddy = &p
+		matches = append(matches, &m)
+	}
+
+	return matches, nil
+}
+
+// GetAllMemberships retrieves every group membership, used to build
+// collaborative-filtering recommendations
+func (r *repository) GetAllMemberships(ctx context.Context) ([]*GroupMember, error) {
+	query := `
+		SELECT group_id, user_id, role, joined_at
+		FROM group_members
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query memberships: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*GroupMember, 0)
+	for rows.Next() {
+		var m GroupMember
+		if err := rows.Scan(&m.GroupID, &m.UserID, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("scan membership: %w", err)
+		}
+		members = append(members, &m)
+	}
+
+	return members, nil
+}
+
+// GetRecommendableGroups retrieves the id, tags and fill state of every
+// group; only open groups with room are recommended, but all of them
+// contribute membership history
+func (r *repository) GetRecommendableGroups(ctx context.Context) ([]*Group, error) {
+	query := `
+		SELECT id, tags, capacity, current_count, status
+		FROM groups
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make([]*Group, 0)
+	for rows.Next() {
+		var group Group
+		var tagsJSON []byte
+
+		if err := rows.Scan(&group.ID, &tagsJSON, &group.Capacity, &group.CurrentCount, &group.Status); err != nil {
+			return nil, fmt.Errorf("scan group: %w", err)
+		}
+
+		if err := json.Unmarshal(tagsJSON, &group.Tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+
+		groups = append(groups, &group)
+	}
+
+	return groups, nil
+}
+
+// ReplaceRecommendations swaps the whole recommendations table for recs
+func (r *repository) ReplaceRecommendations(ctx context.Context, tx *sql.Tx, recs []GroupRecommendation) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM group_recommendations`); err != nil {
+		return fmt.Errorf("clear recommendations: %w", err)
+	}
+
+	if len(recs) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, len(recs))
+	groupIDs := make([]string, len(recs))
+	scores := make([]float64, len(recs))
+	for i, rec := range recs {
+		userIDs[i] = rec.UserID
+		groupIDs[i] = rec.GroupID
+		scores[i] = rec.Score
+	}
+
+	query := `
+		INSERT INTO group_recommendations (user_id, group_id, score)
+		SELECT * FROM unnest($1::uuid[], $2::uuid[], $3::float8[])
+	`
+
+	if _, err := tx.ExecContext(ctx, query, pq.Array(userIDs), pq.Array(groupIDs), pq.Array(scores)); err != nil {
+		return fmt.Errorf("insert recommendations: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecommendedGroups retrieves the user's recommended groups that are still
+// joinable and share one of tags, applying the same filters and exclusions
+// as FindGroupsByTags
+func (r *repository) GetRecommendedGroups(ctx context.Context, userID string, tags []string, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	query := `
+		SELECT g.id, g.owner_id, g.title, g.description, g.proposal, g.tags, g.capacity,
+		       g.current_count, g.join_type, g.status, g.applications, g.schedule, g.meeting_mode,
+		       g.latitude, g.longitude, g.city, g.time_zone, g.language, g.required_proficiency, g.role_slots, g.bookmark_count, g.created_at, g.updated_at, gr.score
+		FROM group_recommendations gr
+		INNER JOIN groups g ON g.id = gr.group_id
+		WHERE gr.user_id = $1
+		  AND g.status = 'OPEN'
+		  AND g.current_count < g.capacity
+		  AND g.owner_id <> $1
+	`
+	args := []interface{}{userID}
+	argIdx := 2
+
+	if len(tags) > 0 {
+		query += fmt.Sprintf(" AND g.tags ?| $%d", argIdx)
+		args = append(args, pq.Array(tags))
+		argIdx++
+	}
+
+	if filters.JoinType != "" {
+		query += fmt.Sprintf(" AND g.join_type = $%d", argIdx)
+		args = append(args, filters.JoinType)
+		argIdx++
+	}
+
+	if filters.MeetingMode != "" {
+		query += fmt.Sprintf(" AND g.meeting_mode = $%d", argIdx)
+		args = append(args, filters.MeetingMode)
+		argIdx++
+	}
+
+	if filters.LanguageMode == LanguageModeFilter {
+		query += fmt.Sprintf(" AND g.language = ANY($%d)", argIdx)
+		args = append(args, pq.Array(filters.Languages))
+		argIdx++
+	}
+
+	locConds, locArgs, argIdx := locationConditions(filters, "g.", argIdx)
+	query += locConds
+	args = append(args, locArgs...)
+
+	if !filters.IncludeJoined {
+		query += `
+		  AND NOT EXISTS (
+		      SELECT 1 FROM group_members gm
+		      WHERE gm.group_id = g.id AND gm.user_id = $1
+		  )`
+	}
+	if !filters.IncludeApplied {
+		query += fmt.Sprintf(`
+		  AND NOT g.applications @> jsonb_build_array(jsonb_build_object('user_id', $1::uuid::text, 'status', '%s'))`,
+			ApplicationStatusPending)
+	}
+	if !filters.IncludeRejected {
+		query += fmt.Sprintf(`
+		  AND NOT g.applications @> jsonb_build_array(jsonb_build_object('user_id', $1::uuid::text, 'status', '%s'))`,
+			ApplicationStatusRejected)
+	}
+
+	limit := filters.Limit
+	if limit == 0 {
+		limit = 50
+	}
+	query += fmt.Sprintf(" ORDER BY gr.score DESC LIMIT $%d", argIdx)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query recommended groups: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]GroupMatch, 0)
+	for rows.Next() {
+		var group Group
+		var tagsJSON, appsJSON, scheduleJSON, slotsJSON []byte
+		var lat, lon sql.NullFloat64
+		var score float64
+
+		err := rows.Scan(
+			&group.ID,
+			&group.OwnerID,
+			&group.Title,
+			&group.Description,
+			&group.Proposal,
+			&tagsJSON,
+			&group.Capacity,
+			&group.CurrentCount,
+			&group.JoinType,
+			&group.Status,
+			&appsJSON,
+			&scheduleJSON,
+			&group.MeetingMode,
+			&lat,
+			&lon,
+			&group.City,
+			&group.TimeZone,
+			&group.Language,
+			&group.RequiredProficiency,
+			&slotsJSON,
+			&group.BookmarkCount,
+			&group.CreatedAt,
+			&group.UpdatedAt,
+			&score,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan group: %w", err)
+		}
+
+		if err := json.Unmarshal(tagsJSON, &group.Tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+
+		if err := json.Unmarshal(appsJSON, &group.Applications); err != nil {
+			return nil, fmt.Errorf("unmarshal applications: %w", err)
+		}
+
+		if err := json.Unmarshal(scheduleJSON, &group.Schedule); err != nil {
+			return nil, fmt.Errorf("unmarshal schedule: %w", err)
+		}
+
+		if err := json.Unmarshal(slotsJSON, &group.RoleSlots); err != nil {
+			return nil, fmt.Errorf("unmarshal role slots: %w", err)
+		}
+
+		group.Location = scanPoint(lat, lon)
+
+		matches = append(matches, GroupMatch{Group: &group, SimilarityScore: score})
+	}
+
+	return matches, nil
+}
+
+// FindAutoMatchUsers finds users who opted into auto-matching and are not
+// a member of any group that is still active
+func (r *repository) FindAutoMatchUsers(ctx context.Context) ([]*UserProfile, error) {
+	query := `
+		SELECT u.id, COALESCE(u.full_name, ''), u.tags, u.skill_level, u.availability, u.schedule, u.intent,
+		       u.latitude, u.longitude, u.city, u.time_zone, u.languages
+		FROM users u
+		WHERE u.auto_match
+		  AND NOT EXISTS (
+		      SELECT 1 FROM group_members gm
+		      INNER JOIN groups g ON g.id = gm.group_id
+		      WHERE gm.user_id = u.id AND g.status <> $1
+		  )
+		ORDER BY u.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, StatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("query auto-match users: %w", err)
+	}
+	defer rows.Close()
+
+	return scanUserProfiles(rows)
+}
+
+// SaveGroupProfile inserts or replaces a group's composite profile
+func (r *repository) SaveGroupProfile(ctx context.Context, profile *GroupProfile) error {
+	tagsJSON, err := json.Marshal(profile.TagCounts)
+	if err != nil {
+		return fmt.Errorf("marshal tag counts: %w", err)
+	}
+
+	skillsJSON, err := json.Marshal(profile.SkillLevels)
+	if err != nil {
+		return fmt.Errorf("marshal skill levels: %w", err)
+	}
+
+	schedulesJSON, err := json.Marshal(profile.MemberSchedules)
+	if err != nil {
+		return fmt.Errorf("marshal member schedules: %w", err)
+	}
+
+	query := `
+		INSERT INTO group_profiles (group_id, member_count, tag_counts, skill_levels, member_schedules, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (group_id) DO UPDATE
+		SET member_count = EXCLUDED.member_count, tag_counts = EXCLUDED.tag_counts,
+		    skill_levels = EXCLUDED.skill_levels, member_schedules = EXCLUDED.member_schedules,
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		profile.GroupID,
+		profile.MemberCount,
+		tagsJSON,
+		skillsJSON,
+		schedulesJSON,
+		profile.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save group profile: %w", err)
+	}
+
+	return nil
+}
+
+// GetGroupProfile retrieves a group's composite profile
+func (r *repository) GetGroupProfile(ctx context.Context, groupID string) (*GroupProfile, error) {
+	profiles, err := r.GetGroupProfiles(ctx, []string{groupID})
+	if err != nil {
+		return nil, err
+	}
+
+	profile, ok := profiles[groupID]
+	if !ok {
+		return nil, ErrGroupProfileNotFound
+	}
+
+	return profile, nil
+}
+
+// GetGroupProfiles retrieves the composite profiles of the given groups,
+// keyed by group ID. Groups without a computed profile are left out.
+func (r *repository) GetGroupProfiles(ctx context.Context, groupIDs []string) (map[string]*GroupProfile, error) {
+	profiles := make(map[string]*GroupProfile, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return profiles, nil
+	}
+
+	query := `
+		SELECT group_id, member_count, tag_counts, skill_levels, member_schedules, updated_at
+		FROM group_profiles
+		WHERE group_id = ANY($1::uuid[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(groupIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query group profiles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p GroupProfile
+		var tagsJSON, skillsJSON, schedulesJSON []byte
+
+		err := rows.Scan(&p.GroupID, &p.MemberCount, &tagsJSON, &skillsJSON, &schedulesJSON, &p.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan group profile: %w", err)
+		}
+
+		if err := json.Unmarshal(tagsJSON, &p.TagCounts); err != nil {
+			return nil, fmt.Errorf("unmarshal tag counts: %w", err)
+		}
+
+		if err := json.Unmarshal(skillsJSON, &p.SkillLevels); err != nil {
+			return nil, fmt.Errorf("unmarshal skill levels: %w", err)
+		}
+
+		if err := json.Unmarshal(schedulesJSON, &p.MemberSchedules); err != nil {
+			return nil, fmt.Errorf("unmarshal member schedules: %w", err)
+		}
+
+		profiles[p.GroupID] = &p
+	}
+
+	return profiles, rows.Err()
+}
+
+// CreateSavedSearch stores a new saved search
+func (r *repository) CreateSavedSearch(ctx context.Context, search *SavedSearch) error {
+	filtersJSON, err := json.Marshal(search.Filters)
+	if err != nil {
+		return fmt.Errorf("marshal filters: %w", err)
+	}
+
+	query := `
+		INSERT INTO saved_searches (id, user_id, name, filters, min_score, paused, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		search.ID,
+		search.UserID,
+		search.Name,
+		filtersJSON,
+		search.MinScore,
+		search.Paused,
+	).Scan(&search.CreatedAt, &search.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("insert saved search: %w", err)
+	}
+
+	return nil
+}
+
+// CountSavedSearches counts a user's saved searches
+func (r *repository) CountSavedSearches(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM saved_searches WHERE user_id = $1`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count saved searches: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetSavedSearch retrieves one of a user's saved searches
+func (r *repository) GetSavedSearch(ctx context.Context, searchID, userID string) (*SavedSearch, error) {
+	query := `
+		SELECT id, user_id, name, filters, min_score, paused, last_matched_at, created_at, updated_at
+		FROM saved_searches
+		WHERE id = $1 AND user_id = $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, searchID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query saved search: %w", err)
+	}
+	defer rows.Close()
+
+	searches, err := scanSavedSearches(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(searches) == 0 {
+		return nil, ErrSavedSearchNotFound
+	}
+
+	return searches[0], nil
+}
+
+// GetUserSavedSearches retrieves a user's saved searches, newest first
+func (r *repository) GetUserSavedSearches(ctx context.Context, userID string) ([]*SavedSearch, error) {
+	query := `
+		SELECT id, user_id, name, filters, min_score, paused, last_matched_at, created_at, updated_at
+		FROM saved_searches
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSavedSearches(rows)
+}
+
+// UpdateSavedSearch updates a saved search's name, minimum score and paused flag
+func (r *repository) UpdateSavedSearch(ctx context.Context, search *SavedSearch) error {
+	query := `
+		UPDATE saved_searches
+		SET name = $1, min_score = $2, paused = $3, updated_at = NOW()
+		WHERE id = $4 AND user_id = $5
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		search.Name,
+		search.MinScore,
+		search.Paused,
+		search.ID,
+		search.UserID,
+	).Scan(&search.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("update saved search: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSavedSearch deletes one of a user's saved searches
+func (r *repository) DeleteSavedSearch(ctx context.Context, searchID, userID string) error {
+	query := `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, searchID, userID)
+	if err != nil {
+		return fmt.Errorf("delete saved search: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrSavedSearchNotFound
+	}
+
+	return nil
+}
+
+// FindSavedSearchesForGroup retrieves the active saved searches a new group
+// may match: searches without tags, sharing a tag with the group or widened
+// to related tags, whose join type and meeting mode filters admit the group.
+// The group's owner is never alerted about their own group.
+func (r *repository) FindSavedSearchesForGroup(ctx context.Context, group *Group) ([]*SavedSearch, error) {
+	query := `
+		SELECT id, user_id, name, filters, min_score, paused, last_matched_at, created_at, updated_at
+		FROM saved_searches
+		WHERE NOT paused
+		  AND user_id <> $1
+		  AND (jsonb_array_length(COALESCE(filters->'tags', '[]'::jsonb)) = 0
+		       OR filters->'tags' ?| $2
+		       OR COALESCE((filters->>'expand')::boolean, FALSE))
+		  AND COALESCE(filters->>'join_type', '') IN ('', $3)
+		  AND COALESCE(filters->>'meeting_mode', '') IN ('', $4)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, group.OwnerID, pq.Array(group.Tags), group.JoinType, group.MeetingMode)
+	if err != nil {
+		return nil, fmt.Errorf("query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSavedSearches(rows)
+}
+
+// MarkSavedSearchesMatched records when saved searches last alerted their owners
+func (r *repository) MarkSavedSearchesMatched(ctx context.Context, searchIDs []string, at time.Time) error {
+	if len(searchIDs) == 0 {
+		return nil
+	}
+
+	query := `UPDATE saved_searches SET last_matched_at = $1 WHERE id = ANY($2::uuid[])`
+
+	if _, err := r.db.ExecContext(ctx, query, at, pq.Array(searchIDs)); err != nil {
+		return fmt.Errorf("mark saved searches matched: %w", err)
+	}
+
+	return nil
+}
+
+// GroupTextRank computes the full-text relevance of a group to a text query
+// the same way discover does, and whether the group matches the query at all
+func (r *repository) GroupTextRank(ctx context.Context, groupID, q string) (float64, bool, error) {
+	tsQuery, prefix := buildTSQuery(q)
+	if tsQuery == "" {
+		return 0, true, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT ts_rank(search_vector, %[1]s, 32)
+		FROM groups
+		WHERE id = $2 AND search_vector @@ %[1]s
+	`, tsQueryCall(prefix, 1))
+
+	var rank float64
+	err := r.db.QueryRowContext(ctx, query, tsQuery, groupID).Scan(&rank)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("rank group text: %w", err)
+	}
+
+	return rank, true, nil
+}
+
+// AddBookmark bookmarks a group for a user; it reports false when the
+// group was already bookmarked
+func (r *repository) AddBookmark(ctx context.Context, tx *sql.Tx, userID, groupID string) (bool, error) {
+	query := `
+		INSERT INTO group_bookmarks (user_id, group_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, group_id) DO NOTHING
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID, groupID)
+	if err != nil {
+		return false, fmt.Errorf("insert bookmark: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// RemoveBookmark removes a user's bookmark; it reports false when the group
+// was not bookmarked
+func (r *repository) RemoveBookmark(ctx context.Context, tx *sql.Tx, userID, groupID string) (bool, error) {
+	query := `DELETE FROM group_bookmarks WHERE user_id = $1 AND group_id = $2`
+
+	result, err := tx.ExecContext(ctx, query, userID, groupID)
+	if err != nil {
+		return false, fmt.Errorf("delete bookmark: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// AdjustBookmarkCount changes a group's bookmark count by delta. Unlike
+// UpdateGroup it leaves updated_at alone, so bookmarks do not count as
+// activity for the popularity boost.
+func (r *repository) AdjustBookmarkCount(ctx context.Context, tx *sql.Tx, groupID string, delta int) error {
+	query := `UPDATE groups SET bookmark_count = GREATEST(bookmark_count + $1, 0) WHERE id = $2`
+
+	if _, err := tx.ExecContext(ctx, query, delta, groupID); err != nil {
+		return fmt.Errorf("update bookmark count: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserBookmarks retrieves a user's bookmarked groups, most recent first
+func (r *repository) GetUserBookmarks(ctx context.Context, userID string) ([]*Bookmark, error) {
+	query := `
+		SELECT g.id, g.owner_id, g.title, g.description, g.proposal, g.tags, g.capacity,
+		       g.current_count, g.join_type, g.status, g.applications, g.schedule, g.meeting_mode,
+		       g.latitude, g.longitude, g.city, g.time_zone, g.language, g.required_proficiency, g.role_slots, g.bookmark_count, g.created_at, g.updated_at,
+		       b.created_at
+		FROM groups g
+		INNER JOIN group_bookmarks b ON g.id = b.group_id
+		WHERE b.user_id = $1
+		ORDER BY b.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	bookmarks := make([]*Bookmark, 0)
+	for rows.Next() {
+		var group Group
+		var bookmark Bookmark
+		var tagsJSON, appsJSON, scheduleJSON, slotsJSON []byte
+		var lat, lon sql.NullFloat64
+
+		err := rows.Scan(
+			&group.ID,
+			&group.OwnerID,
+			&group.Title,
+			&group.Description,
+			&group.Proposal,
+			&tagsJSON,
+			&group.Capacity,
+			&group.CurrentCount,
+			&group.JoinType,
+			&group.Status,
+			&appsJSON,
+			&scheduleJSON,
+			&group.MeetingMode,
+			&lat,
+			&lon,
+			&group.City,
+			&group.TimeZone,
+			&group.Language,
+			&group.RequiredProficiency,
+			&slotsJSON,
+			&group.BookmarkCount,
+			&group.CreatedAt,
+			&group.UpdatedAt,
+			&bookmark.BookmarkedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("scan bookmark: %w", err)
+		}
+
+		if err := json.Unmarshal(tagsJSON, &group.Tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+
+		if err := json.Unmarshal(appsJSON, &group.Applications); err != nil {
+			return nil, fmt.Errorf("unmarshal applications: %w", err)
+		}
+
+		if err := json.Unmarshal(scheduleJSON, &group.Schedule); err != nil {
+			return nil, fmt.Errorf("unmarshal schedule: %w", err)
+		}
+
+		if err := json.Unmarshal(slotsJSON, &group.RoleSlots); err != nil {
+			return nil, fmt.Errorf("unmarshal role slots: %w", err)
+		}
+
+		group.Location = scanPoint(lat, lon)
+		bookmark.Group = &group
+
+		bookmarks = append(bookmarks, &bookmark)
+	}
+
+	return bookmarks, nil
+}
+
+// GetBookmarkerIDs retrieves the users who bookmarked a group and are not
+// its members
+func (r *repository) GetBookmarkerIDs(ctx context.Context, groupID string) ([]string, error) {
+	query := `
+		SELECT b.user_id
+		FROM group_bookmarks b
+		WHERE b.group_id = $1
+		  AND NOT EXISTS (
+		      SELECT 1 FROM group_members gm
+		      WHERE gm.group_id = b.group_id AND gm.user_id = b.user_id
+		  )
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("query bookmarkers: %w", err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]string, 0)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan bookmarker: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// WithTransaction executes a function within a database transaction
+func (r *repository) WithTransaction(ctx context.Context, isolation sql.IsolationLevel, fn db.TxFunc) error {
+	return r.db.WithTransaction(ctx, isolation, fn)
+}
+
+// scanUserProfiles scans rows of id, full_name, tags, skill_level, availability, schedule,
+// intent, latitude, longitude, city, time_zone, languages
+func scanUserProfiles(rows *sql.Rows) ([]*UserProfile, error) {
+	profiles := make([]*UserProfile, 0)
+	for rows.Next() {
+		var p UserProfile
+		var tagsJSON, availabilityJSON, scheduleJSON, languagesJSON []byte
+		var lat, lon sql.NullFloat64
+
+		if err := rows.Scan(&p.UserID, &p.FullName, &tagsJSON, &p.SkillLevel, &availabilityJSON, &scheduleJSON, &p.Intent,
+			&lat, &lon, &p.City, &p.TimeZone, &languagesJSON); err != nil {
+			return nil, fmt.Errorf("scan user profile: %w", err)
+		}
+
+		if err := json.Unmarshal(tagsJSON, &p.Tags); err != nil {
+			return nil, fmt.Errorf("unmarshal tags: %w", err)
+		}
+
+		if err := json.Unmarshal(availabilityJSON, &p.Availability); err != nil {
+			return nil, fmt.Errorf("unmarshal availability: %w", err)
+		}
+
+		if err := json.Unmarshal(scheduleJSON, &p.Schedule); err != nil {
+			return nil, fmt.Errorf("unmarshal schedule: %w", err)
+		}
+
+		p.Location = scanPoint(lat, lon)
+
+		if err := json.Unmarshal(languagesJSON, &p.Languages); err != nil {
+			return nil, fmt.Errorf("unmarshal languages: %w", err)
+		}
+
+		profiles = append(profiles, &p)
+	}
+
+	return profiles, nil
+}
+
+// buildTSQuery turns free text into a tsquery string. Queries of up to
+// prefixQueryMaxTerms words become an AND of prefix terms for to_tsquery;
+// longer queries are passed through untouched for websearch_to_tsquery.
+func buildTSQuery(q string) (string, bool) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return "", false
+	}
+
+	words := strings.Fields(q)
+	if len(words) > prefixQueryMaxTerms {
+		return q, false
+	}
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		// Keep letters and digits only so user input can't inject tsquery operators
+		term := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, w)
+		if term != "" {
+			terms = append(terms, term+":*")
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", false
+	}
+
+	return strings.Join(terms, " & "), true
+}
+
+// scanSavedSearches scans rows of id, user_id, name, filters, min_score,
+// paused, last_matched_at, created_at, updated_at
+func scanSavedSearches(rows *sql.Rows) ([]*SavedSearch, error) {
+	searches := make([]*SavedSearch, 0)
+	for rows.Next() {
+		var search SavedSearch
+		var filtersJSON []byte
+
+		err := rows.Scan(
+			&search.ID,
+			&search.UserID,
+			&search.Name,
+			&filtersJSON,
+			&search.MinScore,
+			&search.Paused,
+			&search.LastMatchedAt,
+			&search.CreatedAt,
+			&search.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan saved search: %w", err)
+		}
+
+		if err := json.Unmarshal(filtersJSON, &search.Filters); err != nil {
+			return nil, fmt.Errorf("unmarshal filters: %w", err)
+		}
+
+		searches = append(searches, &search)
+	}
+
+	return searches, rows.Err()
+}
+
+// tsQueryCall is the SQL call parsing the tsquery bound to parameter argIdx.
+// Short queries are rewritten into prefix terms so "kub" finds "kubernetes".
+func tsQueryCall(prefix bool, argIdx int) string {
+	if prefix {
+		return fmt.Sprintf("to_tsquery('english', $%d)", argIdx)
+	}
+	return fmt.Sprintf("websearch_to_tsquery('english', $%d)", argIdx)
+}
+
+// locationConditions are the discover location and time zone filters as SQL,
+// so they narrow the result before LIMIT. They match locationFilter.apply:
+// radius and city only drop in-person groups, and groups without a time zone
+// count as UTC. filters must be resolved by newLocationFilter first. alias
+// prefixes the groups columns, e.g. "g.".
+func locationConditions(filters DiscoverGroupsRequest, alias string, argIdx int) (string, []interface{}, int) {
+	var conds strings.Builder
+	args := []interface{}{}
+
+	if filters.RadiusKm > 0 && filters.Latitude != nil && filters.Longitude != nil {
+		origin := geo.Point{Lat: *filters.Latitude, Lon: *filters.Longitude}
+		sw, ne := geo.BoundingBox(origin, filters.RadiusKm)
+
+		// The bounding box can use an index; the haversine distance is exact
+		fmt.Fprintf(&conds, `
+          AND (%[1]smeeting_mode <> '%[2]s' OR (
+              %[1]slatitude BETWEEN $%[3]d AND $%[4]d AND %[1]slongitude BETWEEN $%[5]d AND $%[6]d
+              AND 2 * %[7]g * asin(sqrt(least(1,
+                  power(sin(radians(%[1]slatitude - $%[8]d) / 2), 2)
+                  + cos(radians($%[8]d)) * cos(radians(%[1]slatitude)) * power(sin(radians(%[1]slongitude - $%[9]d) / 2), 2)
+              ))) <= $%[10]d))`,
+			alias, MeetingModeInPerson, argIdx, argIdx+1, argIdx+2, argIdx+3, geo.EarthRadiusKm, argIdx+4, argIdx+5, argIdx+6)
+		args = append(args, sw.Lat, ne.Lat, sw.Lon, ne.Lon, origin.Lat, origin.Lon, filters.RadiusKm)
+		argIdx += 7
+	}
+
+	if city := strings.TrimSpace(filters.City); city != "" {
+		fmt.Fprintf(&conds, `
+          AND (%[1]smeeting_mode <> '%[2]s' OR lower(%[1]scity) = lower($%[3]d))`,
+			alias, MeetingModeInPerson, argIdx)
+		args = append(args, city)
+		argIdx++
+	}
+
+	if filters.MaxTZOffsetHours != nil {
+		// Local wall clocks differ by exactly the difference of UTC offsets
+		fmt.Fprintf(&conds, `
+          AND abs(extract(epoch FROM
+              (NOW() AT TIME ZONE COALESCE(NULLIF(%[1]stime_zone, ''), 'UTC'))
+              - (NOW() AT TIME ZONE COALESCE(NULLIF($%[2]d, ''), 'UTC'))
+          )) <= $%[3]d`,
+			alias, argIdx, argIdx+1)
+		args = append(args, filters.TimeZone, *filters.MaxTZOffsetHours*3600)
+		argIdx += 2
+	}
+
+	return conds.String(), args, argIdx
+}
+
+// isSerializationFailure reports whether a serializable transaction lost a
+// race with a concurrent one and may succeed if retried
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "40001"
+}
+
+// scanPoint builds a location from nullable coordinate columns
+func scanPoint(lat, lon sql.NullFloat64) *geo.Point {
+	if !lat.Valid || !lon.Valid {
+		return nil
+	}
+	return &geo.Point{Lat: lat.Float64, Lon: lon.Float64}
+}
+
+// pointArgs returns nullable coordinate arguments for a location
+func pointArgs(p *geo.Point) (sql.NullFloat64, sql.NullFloat64) {
+	if p == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: p.Lat, Valid: true}, sql.NullFloat64{Float64: p.Lon, Valid: true}
 }
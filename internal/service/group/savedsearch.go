@@ -0,0 +1,327 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"bmatch/pkg/availability"
+	"bmatch/pkg/logger"
+	"bmatch/pkg/taxonomy"
+
+	"github.com/google/uuid"
+)
+
+// Notifier delivers in-app notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, userID, notificationType, groupID string, data any)
+}
+
+// savedSearchAlert is the data of a saved search match notification
+type savedSearchAlert struct {
+	SavedSearchID   string  `json:"saved_search_id"`
+	SavedSearchName string  `json:"saved_search_name"`
+	GroupTitle      string  `json:"group_title"`
+	SimilarityScore float64 `json:"similarity_score"`
+}
+
+// CreateSavedSearch saves a discover search for alerts. Tags, languages and
+// the time zone are normalized once here so matching new groups is cheap.
+func (s *Service) CreateSavedSearch(ctx context.Context, userID string, req CreateSavedSearchRequest) (*SavedSearch, error) {
+	filters := req.Filters
+	filters.Query = strings.TrimSpace(filters.Query)
+
+	tags, err := s.tags.Normalize(ctx, filters.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("normalize tags: %w", err)
+	}
+	filters.Tags = tags
+	if len(filters.Tags) == 0 && filters.Query == "" {
+		return nil, fmt.Errorf("%w: tags or a text query are required", ErrInvalidSavedSearch)
+	}
+
+	// Languages are kept only when given; otherwise the profile's apply at match time
+	if len(filters.Languages) > 0 {
+		var resolved UserProfile
+		if err := resolveLanguages(&resolved, &filters); err != nil {
+			return nil, err
+		}
+	}
+	if filters.TimeZone != "" {
+		if err := availability.ValidateTimeZone(filters.TimeZone); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTimeZone, err)
+		}
+	}
+
+	// Alerts are about new groups, so there is nothing to include or page through
+	filters.Limit = 0
+	filters.IncludeJoined, filters.IncludeApplied, filters.IncludeRejected = false, false, false
+
+	count, err := s.repo.CountSavedSearches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= savedSearchMaxPerUser {
+		return nil, ErrTooManySavedSearches
+	}
+
+	minScore := savedSearchDefaultMinScore
+	if req.MinScore != nil {
+		minScore = *req.MinScore
+	}
+
+	search := &SavedSearch{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		Name:     strings.TrimSpace(req.Name),
+		Filters:  filters,
+		MinScore: minScore,
+	}
+
+	if err := s.repo.CreateSavedSearch(ctx, search); err != nil {
+		s.logger.Error(ctx, "failed to create saved search",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "saved search created",
+		logger.Field{Key: "user_id", Value: userID},
+		logger.Field{Key: "saved_search_id", Value: search.ID},
+	)
+	return search, nil
+}
+
+// GetSavedSearches lists a user's saved searches
+func (s *Service) GetSavedSearches(ctx context.Context, userID string) ([]*SavedSearch, error) {
+	searches, err := s.repo.GetUserSavedSearches(ctx, userID)
+	if err != nil {
+		s.logger.Error(ctx, "failed to get saved searches",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+// UpdateSavedSearch renames, pauses, resumes or changes the minimum score of
+// one of the user's saved searches
+func (s *Service) UpdateSavedSearch(ctx context.Context, searchID, userID string, req UpdateSavedSearchRequest) (*SavedSearch, error) {
+	search, err := s.repo.GetSavedSearch(ctx, searchID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, fmt.Errorf("%w: name is empty", ErrInvalidSavedSearch)
+		}
+		search.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.MinScore != nil {
+		search.MinScore = *req.MinScore
+	}
+	if req.Paused != nil {
+		search.Paused = *req.Paused
+	}
+
+	if err := s.repo.UpdateSavedSearch(ctx, search); err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
+
+// DeleteSavedSearch deletes one of the user's saved searches
+func (s *Service) DeleteSavedSearch(ctx context.Context, searchID, userID string) error {
+	return s.repo.DeleteSavedSearch(ctx, searchID, userID)
+}
+
+// SavedSearchMatcher alerts users whose saved searches match a new group.
+// Each search is scored like discover would score the group for its owner.
+type SavedSearchMatcher struct {
+	repo     Repository
+	taxonomy TaxonomyProvider
+	notifier Notifier
+	logger   logger.Logger
+}
+
+func NewSavedSearchMatcher(repo Repository, taxonomy TaxonomyProvider, notifier Notifier, logger logger.Logger) *SavedSearchMatcher {
+	return &SavedSearchMatcher{
+		repo:     repo,
+		taxonomy: taxonomy,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// MatchAsync runs Match in the background, so alerts do not slow down
+// creating the group. It outlives the request that created the group.
+func (m *SavedSearchMatcher) MatchAsync(ctx context.Context, group *Group) {
+	g := *group
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), savedSearchMatchTimeout)
+
+	go func() {
+		defer cancel()
+		if err := m.Match(ctx, &g); err != nil {
+			m.logger.Warn(ctx, "failed to match saved searches",
+				logger.Field{Key: "group_id", Value: g.ID},
+				logger.Field{Key: "error", Value: err},
+			)
+		}
+	}()
+}
+
+// Match evaluates a newly created group against active saved searches and
+// notifies each matching user once, for their best scoring search
+func (m *SavedSearchMatcher) Match(ctx context.Context, group *Group) error {
+	tax, err := m.taxonomy.Taxonomy(ctx)
+	if err != nil {
+		return err
+	}
+
+	searches, err := m.repo.FindSavedSearchesForGroup(ctx, group)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := m.ownerProfiles(ctx, searches)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	ranks := make(map[string]textRank)
+	best := make(map[string]*savedSearchAlert)
+	for _, search := range searches {
+		profile, ok := profiles[search.UserID]
+		if !ok {
+			continue
+		}
+
+		score, ok, err := m.score(ctx, search, profile, group, tax, ranks, now)
+		if err != nil {
+			return err
+		}
+		if !ok || score < search.MinScore {
+			continue
+		}
+
+		if prev := best[search.UserID]; prev == nil || score > prev.SimilarityScore {
+			best[search.UserID] = &savedSearchAlert{
+				SavedSearchID:   search.ID,
+				SavedSearchName: search.Name,
+				GroupTitle:      group.Title,
+				SimilarityScore: score,
+			}
+		}
+	}
+
+	matched := make([]string, 0, len(best))
+	for userID, alert := range best {
+		m.notifier.Notify(ctx, userID, NotificationSavedSearchMatch, group.ID, alert)
+		matched = append(matched, alert.SavedSearchID)
+	}
+
+	if err := m.repo.MarkSavedSearchesMatched(ctx, matched, now); err != nil {
+		return err
+	}
+
+	m.logger.Info(ctx, "saved searches matched",
+		logger.Field{Key: "group_id", Value: group.ID},
+		logger.Field{Key: "searches", Value: len(searches)},
+		logger.Field{Key: "notified", Value: len(matched)},
+	)
+	return nil
+}
+
+// ownerProfiles loads the profiles of the searches' owners in one query.
+// Owners that no longer exist are left out.
+func (m *SavedSearchMatcher) ownerProfiles(ctx context.Context, searches []*SavedSearch) (map[string]*UserProfile, error) {
+	userIDs := make([]string, 0, len(searches))
+	for _, search := range searches {
+		if !slices.Contains(userIDs, search.UserID) {
+			userIDs = append(userIDs, search.UserID)
+		}
+	}
+
+	loaded, err := m.repo.GetUserProfiles(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get user profiles: %w", err)
+	}
+
+	profiles := make(map[string]*UserProfile, len(loaded))
+	for _, p := range loaded {
+		profiles[p.UserID] = p
+	}
+	return profiles, nil
+}
+
+// textRank is the text relevance of the new group for one query
+type textRank struct {
+	rank float64
+	ok   bool
+}
+
+// score applies a saved search's filters to the group and scores it for the
+// search's owner. Filters that no longer resolve, e.g. because the owner
+// removed their languages from their profile, make the search not match.
+// Text ranks are shared through ranks by searches with the same query.
+func (m *SavedSearchMatcher) score(ctx context.Context, search *SavedSearch, profile *UserProfile, group *Group, tax *taxonomy.Taxonomy, ranks map[string]textRank, now time.Time) (float64, bool, error) {
+	filters := search.Filters
+	userProfile := *profile
+	userProfile.Tags = filters.Tags
+
+	// Widened searches still need a related tag in common
+	if len(filters.Tags) > 0 && len(intersect(expandTags(filters.Tags, tax), group.Tags)) == 0 {
+		return 0, false, nil
+	}
+
+	if err := resolveLanguages(&userProfile, &filters); err != nil {
+		return 0, false, nil
+	}
+	if filters.LanguageMode == LanguageModeFilter && !slices.Contains(filters.Languages, group.Language) {
+		return 0, false, nil
+	}
+
+	candidate := &GroupCandidate{Group: group}
+	if filters.Query != "" {
+		r, cached := ranks[filters.Query]
+		if !cached {
+			var err error
+			r.rank, r.ok, err = m.repo.GroupTextRank(ctx, group.ID, filters.Query)
+			if err != nil {
+				return 0, false, err
+			}
+			ranks[filters.Query] = r
+		}
+		if !r.ok {
+			return 0, false, nil
+		}
+		candidate.TextRank = r.rank
+	}
+
+	matches := []GroupMatch{{Group: group}}
+	if filters.MinOverlapHours > 0 {
+		if userProfile.Schedule.IsZero() {
+			return 0, false, nil
+		}
+		minOverlap := time.Duration(filters.MinOverlapHours * float64(time.Hour))
+		matches = filterByOverlap(matches, userProfile.Schedule, minOverlap, now)
+	}
+
+	locFilter, err := newLocationFilter(userProfile, &filters)
+	if err != nil {
+		return 0, false, nil
+	}
+	if len(locFilter.apply(matches, now)) == 0 {
+		return 0, false, nil
+	}
+
+	return scoreCandidate(userProfile, candidate, filters.Query != "", tax, now), true, nil
+}
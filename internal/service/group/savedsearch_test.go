@@ -0,0 +1,246 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"bmatch/pkg/logger"
+	"bmatch/pkg/taxonomy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textRankRepo ranks groups by a fixed table; groups missing from it do not match
+type textRankRepo struct {
+	Repository
+	ranks map[string]float64
+	err   error
+}
+
+func (r *textRankRepo) GroupTextRank(_ context.Context, groupID, _ string) (float64, bool, error) {
+	rank, ok := r.ranks[groupID]
+	return rank, ok, r.err
+}
+
+func TestSavedSearchMatcher_Score(t *testing.T) {
+	tax := taxonomy.New(map[string]string{"go": "backend", "rust": "backend", "react": "frontend"})
+	repo := &textRankRepo{ranks: map[string]float64{"ranked": 0.4}}
+	m := &SavedSearchMatcher{repo: repo}
+
+	owner := &UserProfile{UserID: "owner", Location: &berlin, TimeZone: "Europe/Berlin"}
+	scheduled := &UserProfile{UserID: "owner", Schedule: evenings("Europe/Berlin")}
+
+	cases := map[string]struct {
+		filters DiscoverGroupsRequest
+		profile *UserProfile
+		group   *Group
+		ok      bool
+		score   float64
+	}{
+		"shared tag": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}},
+			group:   &Group{ID: "g", Tags: []string{"go"}},
+			ok:      true,
+			score:   1,
+		},
+		"related tag": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}},
+			group:   &Group{ID: "g", Tags: []string{"rust"}},
+			ok:      true,
+			score:   CalculateTaxonomyScore([]string{"go"}, []string{"rust"}, tax),
+		},
+		"unrelated tag": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}},
+			group:   &Group{ID: "g", Tags: []string{"react"}},
+		},
+		"language filter": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}, Languages: []string{"de"}, LanguageMode: LanguageModeFilter},
+			group:   &Group{ID: "g", Tags: []string{"go"}, Language: "en"},
+		},
+		"language filter without languages": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}, LanguageMode: LanguageModeFilter},
+			group:   &Group{ID: "g", Tags: []string{"go"}, Language: "en"},
+		},
+		"text query": {
+			filters: DiscoverGroupsRequest{Query: "kubernetes"},
+			group:   &Group{ID: "ranked"},
+			ok:      true,
+			score:   0.4,
+		},
+		"text query without hit": {
+			filters: DiscoverGroupsRequest{Query: "kubernetes"},
+			group:   &Group{ID: "unranked"},
+		},
+		"overlap needs a schedule": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}, MinOverlapHours: 2},
+			group:   &Group{ID: "g", Tags: []string{"go"}, Schedule: evenings("Europe/Berlin")},
+		},
+		"overlap met": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}, MinOverlapHours: 2},
+			profile: scheduled,
+			group:   &Group{ID: "g", Tags: []string{"go"}, Schedule: evenings("Europe/Berlin")},
+			ok:      true,
+			score:   1,
+		},
+		"outside radius": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}, RadiusKm: 50},
+			group:   &Group{ID: "g", Tags: []string{"go"}, MeetingMode: MeetingModeInPerson, Location: &paris},
+		},
+		"radius without origin": {
+			filters: DiscoverGroupsRequest{Tags: []string{"go"}, RadiusKm: 50},
+			profile: scheduled,
+			group:   &Group{ID: "g", Tags: []string{"go"}, MeetingMode: MeetingModeInPerson, Location: &berlin},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			profile := tc.profile
+			if profile == nil {
+				profile = owner
+			}
+			search := &SavedSearch{ID: "s", UserID: "owner", Filters: tc.filters}
+
+			score, ok, err := m.score(context.Background(), search, profile, tc.group, tax, map[string]textRank{}, winter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ok, ok)
+			assert.InDelta(t, tc.score, score, 1e-9)
+
+			// Resolving the filters must not change the stored search
+			assert.Equal(t, tc.filters, search.Filters)
+		})
+	}
+
+	t.Run("text rank errors are returned", func(t *testing.T) {
+		failing := &SavedSearchMatcher{repo: &textRankRepo{err: errors.New("db down")}}
+		search := &SavedSearch{Filters: DiscoverGroupsRequest{Query: "go"}}
+
+		_, ok, err := failing.score(context.Background(), search, owner, &Group{ID: "g"}, tax, map[string]textRank{}, winter)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
+
+// notification is a Notify call
+type notification struct {
+	UserID  string
+	Type    string
+	GroupID string
+	Data    any
+}
+
+// recordingNotifier records notifications instead of delivering them
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, userID, notificationType, groupID string, data any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification{UserID: userID, Type: notificationType, GroupID: groupID, Data: data})
+}
+
+func (n *recordingNotifier) notified() []notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sent := append([]notification(nil), n.sent...)
+	sort.Slice(sent, func(i, j int) bool { return sent[i].UserID < sent[j].UserID })
+	return sent
+}
+
+// savedSearchRepo serves saved searches and owner profiles, counting loads
+type savedSearchRepo struct {
+	textRankRepo
+	searches     []*SavedSearch
+	profiles     map[string]*UserProfile
+	profileLoads int
+	rankLoads    int
+	matched      []string
+}
+
+func (r *savedSearchRepo) FindSavedSearchesForGroup(context.Context, *Group) ([]*SavedSearch, error) {
+	return r.searches, nil
+}
+
+func (r *savedSearchRepo) GetUserProfiles(_ context.Context, userIDs []string) ([]*UserProfile, error) {
+	r.profileLoads++
+	profiles := make([]*UserProfile, 0, len(userIDs))
+	for _, id := range userIDs {
+		if p, ok := r.profiles[id]; ok {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles, nil
+}
+
+func (r *savedSearchRepo) GroupTextRank(ctx context.Context, groupID, query string) (float64, bool, error) {
+	r.rankLoads++
+	return r.textRankRepo.GroupTextRank(ctx, groupID, query)
+}
+
+func (r *savedSearchRepo) MarkSavedSearchesMatched(_ context.Context, searchIDs []string, _ time.Time) error {
+	r.matched = append(r.matched, searchIDs...)
+	return nil
+}
+
+func TestSavedSearchMatcher_Match(t *testing.T) {
+	tax := fixedTaxonomy{taxonomy.New(map[string]string{"go": "backend", "rust": "backend"})}
+	group := &Group{ID: "g", Title: "Go study", Tags: []string{"go"}}
+
+	newRepo := func() *savedSearchRepo {
+		return &savedSearchRepo{
+			textRankRepo: textRankRepo{ranks: map[string]float64{"g": 0.5}},
+			searches: []*SavedSearch{
+				{ID: "related", UserID: "u1", Name: "rust", Filters: DiscoverGroupsRequest{Tags: []string{"rust"}}},
+				{ID: "exact", UserID: "u1", Name: "go", Filters: DiscoverGroupsRequest{Tags: []string{"go"}}},
+				{ID: "query", UserID: "u2", Name: "golang", Filters: DiscoverGroupsRequest{Query: "golang"}},
+				{ID: "query-again", UserID: "u3", Name: "golang", Filters: DiscoverGroupsRequest{Query: "golang"}, MinScore: 0.9},
+				{ID: "deleted", UserID: "gone", Name: "go", Filters: DiscoverGroupsRequest{Tags: []string{"go"}}},
+			},
+			profiles: map[string]*UserProfile{
+				"u1": {UserID: "u1"},
+				"u2": {UserID: "u2"},
+				"u3": {UserID: "u3"},
+			},
+		}
+	}
+
+	t.Run("notifies each user once for the best search", func(t *testing.T) {
+		repo := newRepo()
+		notifier := &recordingNotifier{}
+		m := NewSavedSearchMatcher(repo, tax, notifier, logger.NewLogger("test"))
+
+		require.NoError(t, m.Match(context.Background(), group))
+
+		assert.Equal(t, []notification{
+			{UserID: "u1", Type: NotificationSavedSearchMatch, GroupID: "g", Data: &savedSearchAlert{
+				SavedSearchID: "exact", SavedSearchName: "go", GroupTitle: "Go study", SimilarityScore: 1,
+			}},
+			{UserID: "u2", Type: NotificationSavedSearchMatch, GroupID: "g", Data: &savedSearchAlert{
+				SavedSearchID: "query", SavedSearchName: "golang", GroupTitle: "Go study", SimilarityScore: 0.5,
+			}},
+		}, notifier.notified())
+		assert.ElementsMatch(t, []string{"exact", "query"}, repo.matched)
+
+		assert.Equal(t, 1, repo.profileLoads, "owner profiles are loaded in one batch")
+		assert.Equal(t, 1, repo.rankLoads, "searches with the same query share the text rank")
+	})
+
+	t.Run("async matching outlives the request", func(t *testing.T) {
+		repo := newRepo()
+		notifier := &recordingNotifier{}
+		m := NewSavedSearchMatcher(repo, tax, notifier, logger.NewLogger("test"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		m.MatchAsync(ctx, group)
+		cancel()
+
+		assert.Eventually(t, func() bool { return len(notifier.notified()) == 2 }, time.Second, 5*time.Millisecond)
+	})
+}
@@ -3,28 +3,47 @@ package group
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
-	"bmatch/pkg/cache"
+	"bmatch/pkg/availability"
+	"bmatch/pkg/experiment"
 	"bmatch/pkg/logger"
 
 	"github.com/google/uuid"
 )
 
+// TagNormalizer resolves free-form tags to their canonical form
+type TagNormalizer interface {
+	Normalize(ctx context.Context, tags []string) ([]string, error)
+}
+
 type Service struct {
-	repo    Repository
-	matcher GroupMatcher
-	cache   cache.Cache
-	logger  logger.Logger
+	repo        Repository
+	matcher     GroupMatcher
+	candidates  CandidateMatcher
+	tags        TagNormalizer
+	experiments ExperimentTracker
+	searches    *SavedSearchMatcher
+	notifier    Notifier
+	groupCache  *GroupCache
+	discover    *DiscoverCache
+	logger      logger.Logger
 }
 
-func NewService(repo Repository, matcher GroupMatcher, cache cache.Cache, logger logger.Logger) *Service {
+func NewService(repo Repository, matcher GroupMatcher, candidates CandidateMatcher, tags TagNormalizer, experiments ExperimentTracker, searches *SavedSearchMatcher, notifier Notifier, groupCache *GroupCache, discover *DiscoverCache, logger logger.Logger) *Service {
 	return &Service{
-		repo:    repo,
-		matcher: matcher,
-		cache:   cache,
-		logger:  logger,
+		repo:        repo,
+		matcher:     matcher,
+		candidates:  candidates,
+		tags:        tags,
+		experiments: experiments,
+		searches:    searches,
+		notifier:    notifier,
+		groupCache:  groupCache,
+		discover:    discover,
+		logger:      logger,
 	}
 }
 
@@ -40,22 +59,62 @@ func (s *Service) CreateGroup(ctx context.Context, ownerID string, req CreateGro
 		req.Capacity = 5
 	}
 
+	tags, err := s.tags.Normalize(ctx, req.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("normalize tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil, ErrInvalidTags
+	}
+
+	var schedule availability.Schedule
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		schedule = *req.Schedule
+	}
+
+	loc, err := resolveGroupLocation(req, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	lang, requiredProficiency, err := resolveGroupLanguage(req)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.resolveRoleSlots(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	group := &Group{
 		ID:           uuid.New().String(),
 		OwnerID:      ownerID,
 		Title:        req.Title,
 		Description:  req.Description,
 		Proposal:     req.Proposal,
-		Tags:         req.Tags,
+		Tags:         tags,
 		Capacity:     req.Capacity,
 		CurrentCount: 1, // Owner is auto-member
 		JoinType:     req.JoinType,
 		Status:       StatusOpen,
 		Applications: []Application{},
+		Schedule:     schedule,
+		MeetingMode:  loc.meetingMode,
+		Location:     loc.location,
+		City:         loc.city,
+		TimeZone:     loc.timeZone,
+
+		Language:            lang,
+		RequiredProficiency: requiredProficiency,
+		RoleSlots:           slots,
 	}
 
 	// Create group and add owner as member in transaction
-	err := s.repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
+	err = s.repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
 		if err := s.repo.CreateGroup(ctx, tx, group); err != nil {
 			return fmt.Errorf("create group: %w", err)
 		}
@@ -79,12 +138,20 @@ func (s *Service) CreateGroup(ctx context.Context, ownerID string, req CreateGro
 		return nil, err
 	}
 
+	s.discover.InvalidateGroup(ctx, group)
+	tryRefreshGroupProfile(ctx, s.repo, s.logger, group.ID)
+
+	s.searches.MatchAsync(ctx, group)
+
 	s.logger.Info(ctx, "group created", logger.Field{Key: "group_id", Value: group.ID})
 	return group, nil
 }
 
-// JoinGroup allows a user to join an OPEN group with ACID guarantees
-func (s *Service) JoinGroup(ctx context.Context, groupID, userID string) error {
+// JoinGroup allows a user to join an OPEN group with ACID guarantees, taking
+// a seat in the named role slot or an unreserved seat when slot is empty
+func (s *Service) JoinGroup(ctx context.Context, groupID, userID, slot string) error {
+	var joined *Group
+
 	// Execute join operation within serializable transaction
 	err := s.repo.WithTransaction(ctx, sql.LevelSerializable, func(ctx context.Context, tx *sql.Tx) error {
 		// 1. Lock the group row and get current state
@@ -102,6 +169,10 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID string) error {
 			return ErrCannotJoinApplicationGroup
 		}
 
+		if err := s.checkLanguageRequirement(ctx, group, userID); err != nil {
+			return err
+		}
+
 		// 3. Check if already a member
 		isMember, err := s.repo.IsMember(ctx, groupID, userID)
 		if err != nil {
@@ -111,24 +182,31 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID string) error {
 			return ErrAlreadyMember
 		}
 
-		// 4. Check capacity
+		// 4. Check capacity, in total and for the slot
 		if group.CurrentCount >= group.Capacity {
 			return ErrGroupFull
 		}
 
+		slotName, err := s.checkSlot(ctx, group, userID, slot)
+		if err != nil {
+			return err
+		}
+
 		// 5. Add member
 		member := &GroupMember{
 			GroupID: groupID,
 			UserID:  userID,
 			Role:    RoleMember,
+			Slot:    slotName,
 		}
 
 		if err := s.repo.AddMember(ctx, tx, member); err != nil {
 			return fmt.Errorf("add member: %w", err)
 		}
 
-		// 6. Increment counter
+		// 6. Increment counters
 		group.CurrentCount++
+		group.fillSlot(slotName)
 		if err := s.repo.UpdateGroup(ctx, tx, group); err != nil {
 			return fmt.Errorf("update group count: %w", err)
 		}
@@ -141,6 +219,7 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID string) error {
 			}
 		}
 
+		joined = group
 		return nil
 	})
 
@@ -154,7 +233,15 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID string) error {
 	}
 
 	// Invalidate cache
-	s.cache.Del(ctx, fmt.Sprintf("group:%s", groupID))
+	s.groupCache.InvalidateGroup(ctx, groupID)
+	s.groupCache.InvalidateMembers(ctx, groupID)
+	s.discover.InvalidateGroup(ctx, joined)
+	s.discover.InvalidateUser(ctx, userID)
+
+	tryRefreshGroupProfile(ctx, s.repo, s.logger, groupID)
+	s.notifyIfAlmostFull(ctx, joined)
+
+	s.experiments.Track(ctx, userID, experiment.EventJoin, groupID)
 
 	s.logger.Info(ctx, "user joined group",
 		logger.Field{Key: "group_id", Value: groupID},
@@ -164,8 +251,9 @@ func (s *Service) JoinGroup(ctx context.Context, groupID, userID string) error {
 	return nil
 }
 
-// ApplyToGroup submits an application to join an APPLICATION-type group
-func (s *Service) ApplyToGroup(ctx context.Context, groupID, userID, pitch string) error {
+// ApplyToGroup submits an application to join an APPLICATION-type group,
+// optionally for one of its role slots
+func (s *Service) ApplyToGroup(ctx context.Context, groupID, userID, pitch, slot string) error {
 	err := s.repo.WithTransaction(ctx, sql.LevelSerializable, func(ctx context.Context, tx *sql.Tx) error {
 		// 1. Lock and get group
 		group, err := s.repo.GetGroupWithLock(ctx, tx, groupID)
@@ -182,6 +270,10 @@ func (s *Service) ApplyToGroup(ctx context.Context, groupID, userID, pitch strin
 			return ErrCannotApplyToOpenGroup
 		}
 
+		if err := s.checkLanguageRequirement(ctx, group, userID); err != nil {
+			return err
+		}
+
 		// 3. Check if already a member
 		isMember, err := s.repo.IsMember(ctx, groupID, userID)
 		if err != nil {
@@ -203,10 +295,16 @@ func (s *Service) ApplyToGroup(ctx context.Context, groupID, userID, pitch strin
 			return ErrGroupFull
 		}
 
+		slotName, err := s.checkSlot(ctx, group, userID, slot)
+		if err != nil {
+			return err
+		}
+
 		// 6. Add application
 		application := Application{
 			UserID:    userID,
 			Pitch:     pitch,
+			Slot:      slotName,
 			Status:    ApplicationStatusPending,
 			AppliedAt: time.Now(),
 		}
@@ -229,6 +327,12 @@ func (s *Service) ApplyToGroup(ctx context.Context, groupID, userID, pitch strin
 		return err
 	}
 
+	// Invalidate cache
+	s.groupCache.InvalidateGroup(ctx, groupID)
+	s.discover.InvalidateUser(ctx, userID)
+
+	s.experiments.Track(ctx, userID, experiment.EventApply, groupID)
+
 	s.logger.Info(ctx, "application submitted",
 		logger.Field{Key: "group_id", Value: groupID},
 		logger.Field{Key: "user_id", Value: userID},
@@ -239,6 +343,8 @@ func (s *Service) ApplyToGroup(ctx context.Context, groupID, userID, pitch strin
 
 // ApproveApplication approves or rejects an application
 func (s *Service) ApproveApplication(ctx context.Context, groupID, applicantUserID, ownerID string, approve bool) error {
+	var decided *Group
+
 	err := s.repo.WithTransaction(ctx, sql.LevelSerializable, func(ctx context.Context, tx *sql.Tx) error {
 		// 1. Lock and get group
 		group, err := s.repo.GetGroupWithLock(ctx, tx, groupID)
@@ -270,24 +376,35 @@ func (s *Service) ApproveApplication(ctx context.Context, groupID, applicantUser
 			group.Applications[applicationIndex].Status = ApplicationStatusApproved
 			group.Applications[applicationIndex].DecidedAt = &now
 
-			// 5. Check capacity before adding
+			// 5. Check capacity before adding; the slot may have filled
+			// since the application was submitted
 			if group.CurrentCount >= group.Capacity {
 				return ErrGroupFull
 			}
 
+			slot := group.Applications[applicationIndex].Slot
+			if slot == "" && group.unreservedSeats() <= 0 {
+				return ErrSeatsReserved
+			}
+			if rs := group.findSlot(slot); slot != "" && (rs == nil || rs.Open() == 0) {
+				return ErrSlotFull
+			}
+
 			// 6. Add member
 			member := &GroupMember{
 				GroupID: groupID,
 				UserID:  applicantUserID,
 				Role:    RoleMember,
+				Slot:    slot,
 			}
 
 			if err := s.repo.AddMember(ctx, tx, member); err != nil {
 				return fmt.Errorf("add approved member: %w", err)
 			}
 
-			// 7. Increment counter
+			// 7. Increment counters
 			group.CurrentCount++
+			group.fillSlot(slot)
 
 			// 8. Close group if full
 			if group.CurrentCount >= group.Capacity {
@@ -303,6 +420,7 @@ func (s *Service) ApproveApplication(ctx context.Context, groupID, applicantUser
 			return fmt.Errorf("update group: %w", err)
 		}
 
+		decided = group
 		return nil
 	})
 
@@ -318,7 +436,18 @@ func (s *Service) ApproveApplication(ctx context.Context, groupID, applicantUser
 	}
 
 	// Invalidate cache
-	s.cache.Del(ctx, fmt.Sprintf("group:%s", groupID))
+	s.groupCache.InvalidateGroup(ctx, groupID)
+	s.discover.InvalidateUser(ctx, applicantUserID)
+
+	if approve {
+		s.groupCache.InvalidateMembers(ctx, groupID)
+		s.discover.InvalidateGroup(ctx, decided)
+
+		tryRefreshGroupProfile(ctx, s.repo, s.logger, groupID)
+		s.notifyIfAlmostFull(ctx, decided)
+
+		s.experiments.Track(ctx, applicantUserID, experiment.EventJoin, groupID)
+	}
 
 	s.logger.Info(ctx, "application processed",
 		logger.Field{Key: "group_id", Value: groupID},
@@ -329,28 +458,185 @@ func (s *Service) ApproveApplication(ctx context.Context, groupID, applicantUser
 	return nil
 }
 
-// DiscoverGroups finds matching groups for a user
-func (s *Service) DiscoverGroups(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
-	matches, err := s.matcher.FindMatches(ctx, userProfile, filters)
+// LeaveGroup removes a member from a group, freeing their seat and slot.
+// A group closed because it was full reopens and its bookmarkers are told.
+func (s *Service) LeaveGroup(ctx context.Context, groupID, userID string) error {
+	var left *Group
+	reopened := false
+
+	err := s.repo.WithTransaction(ctx, sql.LevelSerializable, func(ctx context.Context, tx *sql.Tx) error {
+		// 1. Lock the group row and get current state
+		group, err := s.repo.GetGroupWithLock(ctx, tx, groupID)
+		if err != nil {
+			return err
+		}
+
+		if group.OwnerID == userID {
+			return ErrCannotLeaveAsOwner
+		}
+
+		// 2. Remove the membership
+		member, err := s.repo.RemoveMember(ctx, tx, groupID, userID)
+		if err != nil {
+			return err
+		}
+
+		// 3. Decrement counters and reopen the group if it was full
+		group.CurrentCount--
+		group.releaseSlot(member.Slot)
+		if group.Status == StatusClosed && group.CurrentCount < group.Capacity {
+			group.Status = StatusOpen
+			reopened = true
+		}
+
+		if err := s.repo.UpdateGroup(ctx, tx, group); err != nil {
+			return fmt.Errorf("update group: %w", err)
+		}
+
+		left = group
+		return nil
+	})
+
 	if err != nil {
-		s.logger.Error(ctx, "failed to discover groups",
-			logger.Field{Key: "user_id", Value: userProfile.UserID},
+		s.logger.Error(ctx, "failed to leave group",
+			logger.Field{Key: "group_id", Value: groupID},
+			logger.Field{Key: "user_id", Value: userID},
 			logger.Field{Key: "error", Value: err},
 		)
+		return err
+	}
+
+	// Invalidate cache
+	s.groupCache.InvalidateGroup(ctx, groupID)
+	s.groupCache.InvalidateMembers(ctx, groupID)
+	s.discover.InvalidateGroup(ctx, left)
+	s.discover.InvalidateUser(ctx, userID)
+
+	tryRefreshGroupProfile(ctx, s.repo, s.logger, groupID)
+
+	if reopened {
+		s.notifyBookmarkers(ctx, left, NotificationGroupReopened)
+	}
+
+	s.logger.Info(ctx, "user left group",
+		logger.Field{Key: "group_id", Value: groupID},
+		logger.Field{Key: "user_id", Value: userID},
+		logger.Field{Key: "reopened", Value: reopened},
+	)
+
+	return nil
+}
+
+// DiscoverGroups finds matching groups for a user
+func (s *Service) DiscoverGroups(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	// Stored tags are canonical, so query tags must be too
+	tags, err := s.tags.Normalize(ctx, userProfile.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("normalize tags: %w", err)
+	}
+	userProfile.Tags = tags
+
+	// Discover requests carry only query tags; skill level, schedule,
+	// location and time zone come from the profile
+	if userProfile.UserID != "" {
+		profile, err := s.repo.GetUserProfile(ctx, userProfile.UserID)
+		if err != nil && !errors.Is(err, ErrUserNotFound) {
+			return nil, fmt.Errorf("get user profile: %w", err)
+		}
+		if profile != nil {
+			userProfile.SkillLevel = profile.SkillLevel
+			userProfile.Schedule = profile.Schedule
+			userProfile.Location = profile.Location
+			userProfile.City = profile.City
+			userProfile.TimeZone = profile.TimeZone
+			userProfile.Languages = profile.Languages
+		}
+	}
+	if err := resolveLanguages(&userProfile, &filters); err != nil {
+		return nil, err
+	}
+	if filters.MinOverlapHours > 0 && userProfile.Schedule.IsZero() {
+		return nil, ErrScheduleRequired
+	}
+
+	locFilter, err := newLocationFilter(userProfile, &filters)
+	if err != nil {
 		return nil, err
 	}
 
+	key, cacheable := s.discover.Key(ctx, userProfile, filters)
+
+	var matches []GroupMatch
+	cached := false
+	if cacheable {
+		matches, cached = s.cachedMatches(ctx, key, userProfile.Tags)
+	}
+	if !cached {
+		matches, err = s.findMatches(ctx, userProfile, filters, locFilter)
+		if err != nil {
+			s.logger.Error(ctx, "failed to discover groups",
+				logger.Field{Key: "user_id", Value: userProfile.UserID},
+				logger.Field{Key: "error", Value: err},
+			)
+			return nil, err
+		}
+
+		if cacheable {
+			s.discover.Set(ctx, key, userProfile.UserID, matches)
+		}
+	}
+
+	if len(matches) > 0 {
+		groupIDs := make([]string, len(matches))
+		for i, m := range matches {
+			groupIDs[i] = m.Group.ID
+		}
+		s.experiments.Track(ctx, userProfile.UserID, experiment.EventImpression, groupIDs...)
+	}
+
 	s.logger.Info(ctx, "groups discovered",
 		logger.Field{Key: "user_id", Value: userProfile.UserID},
 		logger.Field{Key: "count", Value: len(matches)},
+		logger.Field{Key: "cached", Value: cached},
 	)
 
 	return matches, nil
 }
 
+// findMatches runs the discover pipeline and the filters applied after
+// scoring, then cuts the results to the requested limit
+func (s *Service) findMatches(ctx context.Context, userProfile UserProfile, filters DiscoverGroupsRequest, locFilter locationFilter) ([]GroupMatch, error) {
+	limit := filters.Limit
+	if limit == 0 {
+		limit = 50
+	}
+	if filters.MinOverlapHours > 0 {
+		filters.Limit = overlapPoolSize
+	}
+
+	matches, err := s.matcher.FindMatches(ctx, userProfile, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if filters.MinOverlapHours > 0 {
+		minOverlap := time.Duration(filters.MinOverlapHours * float64(time.Hour))
+		matches = filterByOverlap(matches, userProfile.Schedule, minOverlap, time.Now())
+	}
+	matches = locFilter.apply(matches, time.Now())
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	annotateSlots(matches, userProfile.Tags)
+
+	return matches, nil
+}
+
 // GetGroup retrieves a group by ID
 func (s *Service) GetGroup(ctx context.Context, groupID string) (*Group, error) {
-	group, err := s.repo.GetGroupByID(ctx, groupID)
+	group, err := s.groupCache.Group(ctx, groupID, func(ctx context.Context) (*Group, error) {
+		return s.repo.GetGroupByID(ctx, groupID)
+	})
 	if err != nil {
 		s.logger.Error(ctx, "failed to get group",
 			logger.Field{Key: "group_id", Value: groupID},
@@ -362,6 +648,11 @@ func (s *Service) GetGroup(ctx context.Context, groupID string) (*Group, error)
 	return group, nil
 }
 
+// TrackGroupView records that a signed-in user opened a group page
+func (s *Service) TrackGroupView(ctx context.Context, groupID, userID string) {
+	s.experiments.Track(ctx, userID, experiment.EventView, groupID)
+}
+
 // GetUserGroups retrieves all groups a user is a member of
 func (s *Service) GetUserGroups(ctx context.Context, userID string) ([]*Group, error) {
 	groups, err := s.repo.GetUserGroups(ctx, userID)
@@ -378,7 +669,9 @@ func (s *Service) GetUserGroups(ctx context.Context, userID string) ([]*Group, e
 
 // GetGroupMembers retrieves all members of a group
 func (s *Service) GetGroupMembers(ctx context.Context, groupID string) ([]*GroupMember, error) {
-	members, err := s.repo.GetGroupMembers(ctx, groupID)
+	members, err := s.groupCache.Members(ctx, groupID, func(ctx context.Context) ([]*GroupMember, error) {
+		return s.repo.GetGroupMembers(ctx, groupID)
+	})
 	if err != nil {
 		s.logger.Error(ctx, "failed to get group members",
 			logger.Field{Key: "group_id", Value: groupID},
@@ -389,3 +682,36 @@ func (s *Service) GetGroupMembers(ctx context.Context, groupID string) ([]*Group
 
 	return members, nil
 }
+
+// TagsRewritten drops cached copies of groups whose tags were rewritten by a
+// tag alias or merge and rebuilds the profiles of groups whose members' tags
+// changed. Failures are logged; caches then catch up within their TTL.
+func (s *Service) TagsRewritten(ctx context.Context, groupIDs, userIDs []string) {
+	for _, groupID := range groupIDs {
+		s.groupCache.InvalidateGroup(ctx, groupID)
+	}
+	// Pages are scoped by tag versions, but the rewritten tag may have been
+	// merged away and the taxonomy changed with it
+	s.discover.InvalidateAll(ctx)
+
+	s.refreshMemberGroups(ctx, userIDs)
+}
+
+// ProfileUpdated refreshes the profiles of the user's groups after the user
+// changed their tags, skill level or schedule
+func (s *Service) ProfileUpdated(ctx context.Context, userID string) {
+	s.refreshMemberGroups(ctx, []string{userID})
+}
+
+// refreshMemberGroups refreshes the profiles of every group the users are in.
+// Failures are logged and leave the previous profiles in place.
+func (s *Service) refreshMemberGroups(ctx context.Context, userIDs []string) {
+	groupIDs, err := s.repo.GetMemberGroupIDs(ctx, userIDs)
+	if err != nil {
+		s.logger.Warn(ctx, "failed to get groups of updated members", logger.Field{Key: "error", Value: err})
+		return
+	}
+	for _, groupID := range groupIDs {
+		tryRefreshGroupProfile(ctx, s.repo, s.logger, groupID)
+	}
+}
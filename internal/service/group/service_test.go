@@ -0,0 +1,74 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"bmatch/pkg/availability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMatcher returns its matches, best first, up to the requested limit
+type stubMatcher struct {
+	matches []GroupMatch
+	limit   int
+}
+
+func (m *stubMatcher) FindMatches(_ context.Context, _ UserProfile, filters DiscoverGroupsRequest) ([]GroupMatch, error) {
+	m.limit = filters.Limit
+	if len(m.matches) > filters.Limit {
+		return m.matches[:filters.Limit], nil
+	}
+	return m.matches, nil
+}
+
+func TestService_FindMatches_Overlap(t *testing.T) {
+	// The best scored groups meet in the morning, only the last ones in the evening
+	matches := make([]GroupMatch, 0, 60)
+	for i := range 60 {
+		schedule := availability.Schedule{TimeZone: "UTC", Slots: []availability.Slot{{Day: availability.Monday, Start: "08:00", End: "10:00"}}}
+		if i >= 55 {
+			schedule = evenings("UTC")
+		}
+		matches = append(matches, GroupMatch{
+			Group:           &Group{ID: fmt.Sprintf("g%02d", i), Schedule: schedule},
+			SimilarityScore: 1 - float64(i)/100,
+		})
+	}
+
+	cases := map[string]struct {
+		filters DiscoverGroupsRequest
+		fetched int
+		want    int
+	}{
+		"no overlap filter":    {filters: DiscoverGroupsRequest{Limit: 10}, fetched: 10, want: 10},
+		"overlap before limit": {filters: DiscoverGroupsRequest{Limit: 3, MinOverlapHours: 2}, fetched: overlapPoolSize, want: 3},
+		"default limit":        {filters: DiscoverGroupsRequest{MinOverlapHours: 2}, fetched: overlapPoolSize, want: 5},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			matcher := &stubMatcher{matches: matches}
+			s := &Service{matcher: matcher}
+			user := UserProfile{Schedule: evenings("UTC")}
+
+			locFilter, err := newLocationFilter(user, &tc.filters)
+			require.NoError(t, err)
+
+			got, err := s.findMatches(context.Background(), user, tc.filters, locFilter)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.fetched, matcher.limit)
+			assert.Len(t, got, tc.want)
+			if tc.filters.MinOverlapHours > 0 {
+				for _, m := range got {
+					assert.GreaterOrEqual(t, availability.Overlap(user.Schedule, m.Group.Schedule, time.Now()), 2*time.Hour)
+				}
+			}
+		})
+	}
+}
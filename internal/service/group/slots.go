@@ -0,0 +1,137 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bmatch/pkg/taxonomy"
+)
+
+// Open is the number of seats left in the slot
+func (s RoleSlot) Open() int {
+	return max(s.Count-s.Filled, 0)
+}
+
+// findSlot returns the slot with the given name, ignoring case
+func (g *Group) findSlot(name string) *RoleSlot {
+	for i := range g.RoleSlots {
+		if strings.EqualFold(g.RoleSlots[i].Name, strings.TrimSpace(name)) {
+			return &g.RoleSlots[i]
+		}
+	}
+	return nil
+}
+
+// unreservedSeats is the number of open seats not held back for role slots
+func (g *Group) unreservedSeats() int {
+	reserved := 0
+	for _, slot := range g.RoleSlots {
+		reserved += slot.Open()
+	}
+	return g.Capacity - g.CurrentCount - reserved
+}
+
+// resolveRoleSlots validates a new group's role slots and normalizes their
+// tags. The owner holds one seat, so slots reserve at most capacity - 1.
+func (s *Service) resolveRoleSlots(ctx context.Context, req CreateGroupRequest) ([]RoleSlot, error) {
+	slots := make([]RoleSlot, 0, len(req.RoleSlots))
+	seen := make(map[string]bool, len(req.RoleSlots))
+	reserved := 0
+
+	for _, slot := range req.RoleSlots {
+		name := strings.TrimSpace(slot.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: slot name is empty", ErrInvalidRoleSlots)
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: duplicate slot %q", ErrInvalidRoleSlots, name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		tags, err := s.tags.Normalize(ctx, slot.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("normalize slot tags: %w", err)
+		}
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("%w: slot %q has no tags", ErrInvalidRoleSlots, name)
+		}
+
+		reserved += slot.Count
+		slots = append(slots, RoleSlot{Name: name, Tags: tags, Count: slot.Count})
+	}
+
+	if reserved > req.Capacity-1 {
+		return nil, fmt.Errorf("%w: slots reserve %d seats but only %d are free", ErrInvalidRoleSlots, reserved, req.Capacity-1)
+	}
+
+	return slots, nil
+}
+
+// checkSlot validates that a user can take a seat in the named slot, or an
+// unreserved seat when slot is empty, and returns the slot's stored name.
+// Callers have already checked the group's total capacity.
+func (s *Service) checkSlot(ctx context.Context, group *Group, userID, slot string) (string, error) {
+	if strings.TrimSpace(slot) == "" {
+		if group.unreservedSeats() <= 0 {
+			return "", ErrSeatsReserved
+		}
+		return "", nil
+	}
+
+	rs := group.findSlot(slot)
+	if rs == nil {
+		return "", ErrSlotNotFound
+	}
+	if rs.Open() == 0 {
+		return "", ErrSlotFull
+	}
+
+	profile, err := s.repo.GetUserProfile(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("get user profile: %w", err)
+	}
+	if len(intersect(profile.Tags, rs.Tags)) == 0 {
+		return "", ErrSlotTagsNotMet
+	}
+
+	return rs.Name, nil
+}
+
+// fillSlot takes a seat in the named slot; an empty name takes an unreserved seat
+func (g *Group) fillSlot(slot string) {
+	if rs := g.findSlot(slot); rs != nil {
+		rs.Filled++
+	}
+}
+
+// releaseSlot frees a seat in the named slot when a member leaves
+func (g *Group) releaseSlot(slot string) {
+	if rs := g.findSlot(slot); rs != nil && rs.Filled > 0 {
+		rs.Filled--
+	}
+}
+
+// slotFit is the best tag fit between the user and any open slot of the group
+func slotFit(userTags []string, group *Group, tax *taxonomy.Taxonomy) (float64, bool) {
+	best, open := 0.0, false
+	for _, slot := range group.RoleSlots {
+		if slot.Open() == 0 {
+			continue
+		}
+		open = true
+		best = max(best, CalculateTaxonomyScore(userTags, slot.Tags, tax))
+	}
+	return best, open
+}
+
+// annotateSlots lists on each match the open slots sharing a tag with the user
+func annotateSlots(matches []GroupMatch, userTags []string) {
+	for i, m := range matches {
+		for _, slot := range m.Group.RoleSlots {
+			if slot.Open() > 0 && len(intersect(userTags, slot.Tags)) > 0 {
+				matches[i].MatchingSlots = append(matches[i].MatchingSlots, slot.Name)
+			}
+		}
+	}
+}
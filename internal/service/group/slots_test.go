@@ -0,0 +1,156 @@
+package group
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lowerTags normalizes tags by trimming and lowercasing them
+type lowerTags struct{}
+
+func (lowerTags) Normalize(_ context.Context, tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// profileRepo serves user profiles from a map
+type profileRepo struct {
+	Repository
+	profiles map[string]*UserProfile
+}
+
+func (r *profileRepo) GetUserProfile(_ context.Context, userID string) (*UserProfile, error) {
+	if p, ok := r.profiles[userID]; ok {
+		return p, nil
+	}
+	return nil, ErrUserNotFound
+}
+
+func TestService_ResolveRoleSlots(t *testing.T) {
+	s := &Service{tags: lowerTags{}}
+
+	cases := map[string]struct {
+		capacity int
+		slots    []RoleSlot
+		want     []RoleSlot
+		err      error
+	}{
+		"no slots": {
+			capacity: 4,
+			slots:    []RoleSlot{},
+			want:     []RoleSlot{},
+		},
+		"normalized": {
+			capacity: 4,
+			slots:    []RoleSlot{{Name: " Backend ", Tags: []string{" Go", "RUST"}, Count: 2, Filled: 5}},
+			want:     []RoleSlot{{Name: "Backend", Tags: []string{"go", "rust"}, Count: 2}},
+		},
+		"owner seat is not reservable": {
+			capacity: 3,
+			slots:    []RoleSlot{{Name: "backend", Tags: []string{"go"}, Count: 2}, {Name: "frontend", Tags: []string{"react"}, Count: 1}},
+			err:      ErrInvalidRoleSlots,
+		},
+		"empty name": {
+			capacity: 4,
+			slots:    []RoleSlot{{Name: "  ", Tags: []string{"go"}, Count: 1}},
+			err:      ErrInvalidRoleSlots,
+		},
+		"duplicate name ignoring case": {
+			capacity: 4,
+			slots:    []RoleSlot{{Name: "Backend", Tags: []string{"go"}, Count: 1}, {Name: "backend", Tags: []string{"rust"}, Count: 1}},
+			err:      ErrInvalidRoleSlots,
+		},
+		"no tags after normalizing": {
+			capacity: 4,
+			slots:    []RoleSlot{{Name: "backend", Tags: []string{" "}, Count: 1}},
+			err:      ErrInvalidRoleSlots,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			slots, err := s.resolveRoleSlots(context.Background(), CreateGroupRequest{Capacity: tc.capacity, RoleSlots: tc.slots})
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, slots)
+		})
+	}
+}
+
+func TestService_CheckSlot(t *testing.T) {
+	s := &Service{repo: &profileRepo{profiles: map[string]*UserProfile{
+		"gopher": testProfile("gopher", IntentCasual, "go"),
+		"artist": testProfile("artist", IntentCasual, "design"),
+	}}}
+
+	newGroup := func() *Group {
+		return &Group{Capacity: 5, CurrentCount: 2, RoleSlots: []RoleSlot{
+			{Name: "Backend", Tags: []string{"go", "rust"}, Count: 2, Filled: 1},
+			{Name: "Frontend", Tags: []string{"react"}, Count: 1, Filled: 1},
+		}}
+	}
+
+	cases := map[string]struct {
+		group  func() *Group
+		userID string
+		slot   string
+		want   string
+		err    error
+	}{
+		"slot by any case": {group: newGroup, userID: "gopher", slot: " backend ", want: "Backend"},
+		"unreserved seat":  {group: newGroup, userID: "artist", slot: "", want: ""},
+		"unknown slot":     {group: newGroup, userID: "gopher", slot: "devops", err: ErrSlotNotFound},
+		"full slot":        {group: newGroup, userID: "gopher", slot: "frontend", err: ErrSlotFull},
+		"tags not met":     {group: newGroup, userID: "artist", slot: "backend", err: ErrSlotTagsNotMet},
+		"unknown user":     {group: newGroup, userID: "ghost", slot: "backend", err: ErrUserNotFound},
+		"only reserved seats left": {
+			group: func() *Group {
+				g := newGroup()
+				g.CurrentCount = 4
+				return g
+			},
+			userID: "artist",
+			err:    ErrSeatsReserved,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			slot, err := s.checkSlot(context.Background(), tc.group(), tc.userID, tc.slot)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, slot)
+		})
+	}
+}
+
+func TestGroup_FillAndReleaseSlot(t *testing.T) {
+	g := &Group{Capacity: 4, CurrentCount: 1, RoleSlots: []RoleSlot{{Name: "Backend", Tags: []string{"go"}, Count: 2}}}
+	assert.Equal(t, 1, g.unreservedSeats())
+
+	g.fillSlot("backend")
+	assert.Equal(t, 1, g.RoleSlots[0].Filled)
+	assert.Equal(t, 1, g.RoleSlots[0].Open())
+
+	g.releaseSlot("BACKEND")
+	g.releaseSlot("backend")
+	assert.Zero(t, g.RoleSlots[0].Filled, "releasing never goes below zero")
+
+	g.fillSlot("")
+	assert.Zero(t, g.RoleSlots[0].Filled, "an unreserved seat fills no slot")
+}
@@ -1,6 +1,12 @@
 package group
 
-import "time"
+import (
+	"time"
+
+	"bmatch/pkg/availability"
+	"bmatch/pkg/geo"
+	"bmatch/pkg/language"
+)
 
 // Enums
 const (
@@ -30,61 +36,460 @@ const (
 	ApplicationStatusPending  = "PENDING"
 	ApplicationStatusApproved = "APPROVED"
 	ApplicationStatusRejected = "REJECTED"
+
+	// Meeting Modes
+	MeetingModeOnline   = "ONLINE"
+	MeetingModeInPerson = "IN_PERSON"
+	MeetingModeHybrid   = "HYBRID"
+
+	// Language Modes for discover
+	LanguageModeFilter = "FILTER" // only groups in one of the caller's languages
+	LanguageModeScore  = "SCORE"  // rank groups in the caller's languages higher (default)
+
+	// Invite Status
+	InviteStatusPending  = "PENDING"
+	InviteStatusAccepted = "ACCEPTED"
+	InviteStatusDeclined = "DECLINED"
+)
+
+// Search
+const (
+	// Weight of full-text relevance when blended with tag similarity
+	textRankWeight = 0.3
+
+	// Queries with at most this many words get prefix matching
+	prefixQueryMaxTerms = 3
+)
+
+// Taxonomy
+const (
+	// Related tags further apart than this earn no credit
+	taxonomyMaxDistance = 2
+
+	// Credit multiplier per taxonomy edge: parent/child 0.5, siblings 0.25
+	taxonomyDecay = 0.5
+
+	// Discover widens to related tags when fewer exact matches are found
+	expandMinResults = 5
+
+	// Groups fetched before the schedule overlap filter, which cannot run in
+	// the database, so the filter does not starve the requested limit
+	overlapPoolSize = 500
+)
+
+// Candidate ranking
+const (
+	// Users fetched from the database before scoring
+	candidatePoolSize = 200
+
+	candidateTagWeight          = 0.5
+	candidateSkillWeight        = 0.2
+	candidateAvailabilityWeight = 0.15
+	candidateIntentWeight       = 0.15
+)
+
+// Diversity re-ranking
+const (
+	// How much shared tags and a shared owner make two results redundant
+	mmrTagWeight   = 0.7
+	mmrOwnerWeight = 0.3
+)
+
+// Collaborative filtering
+const (
+	// Group-to-group similarity: shared members (cosine) blended with shared tags (Jaccard)
+	cfMembershipWeight = 0.7
+	cfTagWeight        = 0.3
+
+	// Recommendations kept per user
+	cfMaxPerUser = 50
+)
+
+// Weekly availability
+const (
+	// Weight of schedule coverage in discover scores when both sides have a schedule
+	discoverAvailabilityWeight = 0.2
+)
+
+// Role slots
+const (
+	// Weight of the best open role slot's tag fit in discover scores
+	discoverSlotWeight = 0.2
+)
+
+// Language matching
+const (
+	// Weight of the caller's proficiency in the group's language in discover scores
+	discoverLanguageWeight = 0.2
+)
+
+// Saved searches
+const (
+	// Score a new group must reach to alert a saved search without its own minimum
+	savedSearchDefaultMinScore = 0.3
+
+	// Saved searches kept per user
+	savedSearchMaxPerUser = 20
+
+	// How long matching a new group against saved searches may run after
+	// the group was created
+	savedSearchMatchTimeout = time.Minute
+
+	// Notification Types
+	NotificationSavedSearchMatch = "SAVED_SEARCH_MATCH"
+)
+
+// Bookmarks
+const (
+	// Bookmark count at which the bookmark boost reaches half its weight
+	bookmarkHalfBoostCount = 5
+
+	// Seats left when bookmarkers are told a group is about to fill up and close
+	bookmarkAlmostFullSeats = 1
+
+	// Notification Types
+	NotificationGroupAlmostFull = "GROUP_ALMOST_FULL"
+	NotificationGroupReopened   = "GROUP_REOPENED"
+)
+
+// Auto-formation
+const (
+	// Smallest group worth creating
+	autoFormMinSize = 2
+
+	// Users fitting a cluster worse than this are left unmatched
+	autoFormMinFit = 0.2
+
+	// Maximum number of tags carried by an auto-formed group
+	autoFormMaxTags = 5
+
+	autoFormLockKey = "lock:group:auto-form"
+	autoFormLockTTL = 10 * time.Minute
+)
+
+// Matchmaking queue
+const (
+	// Sorted set of waiting user IDs scored by enqueue time (unix ms)
+	matchQueueKey = "matchqueue"
+
+	// Sorted set of pending proposal IDs scored by deadline (unix ms)
+	matchProposalsKey = "matchqueue:proposals"
+
+	matchEntryKeyPrefix        = "matchqueue:entry:"
+	matchProposalKeyPrefix     = "matchqueue:proposal:"
+	matchUserProposalKeyPrefix = "matchqueue:user:"
+
+	matchQueueLockKey = "lock:matchqueue"
+	matchQueueLockTTL = 10 * time.Second
+
+	// The lock is extended this often while a scan or mutation runs
+	matchQueueLockRenew = matchQueueLockTTL / 3
+
+	// Queued users whose tags score lower than this are never matched together
+	matchMinTagScore = 0.3
+
+	// Proposal Status
+	ProposalStatusPending  = "PENDING"
+	ProposalStatusAccepted = "ACCEPTED"
+	ProposalStatusDeclined = "DECLINED"
+	ProposalStatusExpired  = "EXPIRED"
+)
+
+// Group cache
+const (
+	// Groups are cached at group:<id> and their members at group:<id>:members
+	groupCacheKeyPrefix = "group:"
+
+	// Fill locks expire on their own if the instance holding one dies
+	groupCacheLockTTL = 5 * time.Second
+
+	// How long a miss waits for another instance to fill the key before
+	// loading it itself
+	groupCacheLockWait = 500 * time.Millisecond
+	groupCacheLockPoll = 25 * time.Millisecond
+)
+
+// Discover cache
+const (
+	discoverCacheKeyPrefix = "discover:page:"
+
+	// Versions of what discover pages depend on, see DiscoverCache
+	discoverVersionEpochKey   = "discover:ver:epoch"
+	discoverVersionAnyKey     = "discover:ver:any"
+	discoverVersionTagPrefix  = "discover:ver:tag:"
+	discoverVersionUserPrefix = "discover:ver:user:"
+
+	// Versions not bumped for this long expire; pages never live longer
+	discoverVersionTTL = 24 * time.Hour
+)
+
+// Buddy mode
+const (
+	// Buddy Reactions
+	BuddyReactionInterest = "INTEREST"
+	BuddyReactionPass     = "PASS"
+
+	// A buddy pair is a group of exactly two
+	buddyGroupCapacity = 2
+
+	// Serialization failures between simultaneous interests are retried
+	buddyInterestAttempts = 3
 )
 
 // Domain Models
 type Group struct {
-	ID           string        `json:"id"`
-	OwnerID      string        `json:"owner_id"`
-	Title        string        `json:"title"`
-	Description  string        `json:"description"`
-	Proposal     string        `json:"proposal"`
-	Tags         []string      `json:"tags"`
-	Capacity     int           `json:"capacity"`
-	CurrentCount int           `json:"current_count"`
-	JoinType     string        `json:"join_type"`
-	Status       string        `json:"status"`
-	Applications []Application `json:"applications,omitempty"`
-	CreatedAt    time.Time     `json:"created_at"`
-	UpdatedAt    time.Time     `json:"updated_at"`
+	ID                  string                `json:"id"`
+	OwnerID             string                `json:"owner_id"`
+	Title               string                `json:"title"`
+	Description         string                `json:"description"`
+	Proposal            string                `json:"proposal"`
+	Tags                []string              `json:"tags"`
+	Capacity            int                   `json:"capacity"`
+	CurrentCount        int                   `json:"current_count"`
+	JoinType            string                `json:"join_type"`
+	Status              string                `json:"status"`
+	Applications        []Application         `json:"applications,omitempty"`
+	Schedule            availability.Schedule `json:"schedule"` // when the group meets
+	MeetingMode         string                `json:"meeting_mode"`
+	Location            *geo.Point            `json:"location,omitempty"` // where in-person meetings happen
+	City                string                `json:"city,omitempty"`
+	TimeZone            string                `json:"time_zone,omitempty"`
+	Language            string                `json:"language,omitempty"`             // primary ISO 639 code
+	RequiredProficiency string                `json:"required_proficiency,omitempty"` // screening of new members
+	RoleSlots           []RoleSlot            `json:"role_slots,omitempty"`
+	BookmarkCount       int                   `json:"bookmark_count"`
+	CreatedAt           time.Time             `json:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at"`
+}
+
+// GroupSeats is the live availability of an open group with free seats
+type GroupSeats struct {
+	CurrentCount int
+	RoleSlots    []RoleSlot
 }
 
 type GroupMember struct {
 	GroupID  string    `json:"group_id"`
 	UserID   string    `json:"user_id"`
 	Role     string    `json:"role"`
+	Slot     string    `json:"slot,omitempty"` // role slot joined through
 	JoinedAt time.Time `json:"joined_at"`
 }
 
+// RoleSlot reserves seats for a role, e.g. two backend developers. Seats
+// not reserved by open slots are taken by members joining without one.
+type RoleSlot struct {
+	Name   string   `json:"name" binding:"required,min=1,max=100"`
+	Tags   []string `json:"tags" binding:"required,min=1,max=10"` // members need at least one
+	Count  int      `json:"count" binding:"required,min=1,max=9"`
+	Filled int      `json:"filled"`
+}
+
 type Application struct {
 	UserID    string     `json:"user_id"`
 	Pitch     string     `json:"pitch"`
+	Slot      string     `json:"slot,omitempty"`
 	Status    string     `json:"status"`
 	AppliedAt time.Time  `json:"applied_at"`
 	DecidedAt *time.Time `json:"decided_at,omitempty"`
 }
 
+type GroupInvite struct {
+	GroupID   string     `json:"group_id"`
+	UserID    string     `json:"user_id"`
+	InvitedBy string     `json:"invited_by"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
 type GroupMatch struct {
-	Group           *Group  `json:"group"`
-	SimilarityScore float64 `json:"similarity_score"`
+	Group           *Group   `json:"group"`
+	SimilarityScore float64  `json:"similarity_score"`
+	DistanceKm      *float64 `json:"distance_km,omitempty"`    // set when both sides have coordinates
+	MatchingSlots   []string `json:"matching_slots,omitempty"` // open role slots sharing a tag with the caller
+}
+
+// GroupProfile is a group's matching profile computed from who is actually
+// in it, refreshed on membership changes and members' profile updates
+type GroupProfile struct {
+	GroupID         string                  `json:"group_id"`
+	MemberCount     int                     `json:"member_count"`
+	TagCounts       map[string]int          `json:"tag_counts"`      // members having each tag
+	SkillLevels     map[string]int          `json:"skill_levels"`    // members per skill level
+	MemberSchedules []availability.Schedule `json:"-"`               // schedules of members who set one, in their own time zones
+	SharedSchedule  availability.Schedule   `json:"shared_schedule"` // weekly time every member with a schedule is available, in UTC for the current week
+	UpdatedAt       time.Time               `json:"updated_at"`
+}
+
+// SavedSearch is a discover search kept by a user who wants to hear about
+// new groups matching it
+type SavedSearch struct {
+	ID            string                `json:"id"`
+	UserID        string                `json:"user_id"`
+	Name          string                `json:"name"`
+	Filters       DiscoverGroupsRequest `json:"filters"` // tags, text query and filters
+	MinScore      float64               `json:"min_score"`
+	Paused        bool                  `json:"paused"`
+	LastMatchedAt *time.Time            `json:"last_matched_at,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+// Bookmark is a group a user shortlisted
+type Bookmark struct {
+	Group        *Group    `json:"group"`
+	BookmarkedAt time.Time `json:"bookmarked_at"`
+}
+
+// GroupRecommendation is a precomputed collaborative-filtering score
+type GroupRecommendation struct {
+	UserID  string
+	GroupID string
+	Score   float64
+}
+
+// GroupCandidate is a group returned by discovery queries together with
+// the signals computed in the database that feed into scoring
+type GroupCandidate struct {
+	Group    *Group
+	TextRank float64 // normalized ts_rank in [0, 1), zero when no text query is given
 }
 
 // DTOs
 type CreateGroupRequest struct {
-	Title       string   `json:"title" binding:"required,min=3,max=255"`
-	Description string   `json:"description" binding:"required,min=10"`
-	Proposal    string   `json:"proposal" binding:"required,min=20"`
-	Tags        []string `json:"tags" binding:"required,min=1,max=10"`
-	Capacity    int      `json:"capacity" binding:"omitempty,min=2,max=10"`
-	JoinType    string   `json:"join_type" binding:"required,oneof=OPEN APPLICATION"`
+	Title       string                 `json:"title" binding:"required,min=3,max=255"`
+	Description string                 `json:"description" binding:"required,min=10"`
+	Proposal    string                 `json:"proposal" binding:"required,min=20"`
+	Tags        []string               `json:"tags" binding:"required,min=1,max=10"`
+	Capacity    int                    `json:"capacity" binding:"omitempty,min=2,max=10"`
+	JoinType    string                 `json:"join_type" binding:"required,oneof=OPEN APPLICATION"`
+	Schedule    *availability.Schedule `json:"schedule"`
+	MeetingMode string                 `json:"meeting_mode" binding:"omitempty,oneof=ONLINE IN_PERSON HYBRID"`
+	Location    *geo.Point             `json:"location"`
+	City        string                 `json:"city" binding:"omitempty,max=100"`
+	TimeZone    string                 `json:"time_zone" binding:"omitempty,max=64"`
+	Language    string                 `json:"language" binding:"omitempty,max=3"`
+	// Members must speak Language at this level or above
+	RequiredProficiency string `json:"required_proficiency" binding:"omitempty,oneof=BASIC CONVERSATIONAL FLUENT NATIVE"`
+	// Seats reserved for roles, at most capacity minus the owner's seat
+	RoleSlots []RoleSlot `json:"role_slots" binding:"omitempty,max=10,dive"`
+}
+
+type CandidateMatch struct {
+	User            *UserProfile `json:"user"`
+	SimilarityScore float64      `json:"similarity_score"`
+}
+
+type FindCandidatesRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type BuddySuggestion struct {
+	User            *UserProfile `json:"user"`
+	SimilarityScore float64      `json:"similarity_score"`
+}
+
+// BuddyMatch is a pair formed by mutual interest, backed by a two-person group
+type BuddyMatch struct {
+	GroupID   string       `json:"group_id"`
+	Buddy     *UserProfile `json:"buddy"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type BuddySuggestionsRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type BuddyInterestResponse struct {
+	Matched bool        `json:"matched"`
+	Match   *BuddyMatch `json:"match,omitempty"`
+}
+
+type InviteUserRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid"`
+}
+
+type RespondToInviteRequest struct {
+	Accept *bool  `json:"accept" binding:"required"`
+	Slot   string `json:"slot" binding:"omitempty,max=100"` // role slot to take when accepting
+}
+
+type AutoFormRequest struct {
+	Seed   int64 `json:"seed"`
+	DryRun bool  `json:"dry_run"`
+	// At is the reference time weekly schedules are compared at. It defaults
+	// to the start of the current UTC week, so runs in one week agree.
+	At time.Time `json:"at"`
+}
+
+// ProposedGroup is a cluster of users; Group.ID is empty on dry runs
+type ProposedGroup struct {
+	Group     *Group   `json:"group"`
+	MemberIDs []string `json:"member_ids"`
+	LeaderID  string   `json:"leader_id"`
+	Cohesion  float64  `json:"cohesion"` // mean pairwise similarity of members
+}
+
+type AutoFormResult struct {
+	Seed      int64           `json:"seed"`
+	At        time.Time       `json:"at"`
+	DryRun    bool            `json:"dry_run"`
+	Groups    []ProposedGroup `json:"groups"`
+	Unmatched []string        `json:"unmatched"`
+}
+
+// QueueEntry is a user waiting in the matchmaking queue with their preferences
+type QueueEntry struct {
+	UserProfile
+	GroupSize  int       `json:"group_size"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
+
+// MatchProposal is offered to compatible queued users; the group is created
+// once every user accepts before ExpiresAt
+type MatchProposal struct {
+	ID        string    `json:"id"`
+	UserIDs   []string  `json:"user_ids"`
+	Accepted  []string  `json:"accepted"`
+	Tags      []string  `json:"tags"`
+	Intent    string    `json:"intent"`
+	GroupSize int       `json:"group_size"`
+	Status    string    `json:"status"`
+	GroupID   string    `json:"group_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type EnterQueueRequest struct {
+	Tags      []string `json:"tags" binding:"omitempty,max=10"` // defaults to profile tags
+	Intent    string   `json:"intent" binding:"omitempty,oneof=CASUAL SERIOUS"`
+	GroupSize int      `json:"group_size" binding:"required,min=2,max=10"`
+}
+
+type RespondToProposalRequest struct {
+	Accept *bool `json:"accept" binding:"required"`
+}
+
+type QueueStatus struct {
+	Queued   bool           `json:"queued"`
+	Position int            `json:"position,omitempty"` // 1-based, oldest first
+	Entry    *QueueEntry    `json:"entry,omitempty"`
+	Proposal *MatchProposal `json:"proposal,omitempty"`
 }
 
 type JoinGroupRequest struct {
 	GroupID string `json:"group_id" binding:"required,uuid"`
 }
 
+// JoinSlotRequest is the optional body of a join
+type JoinSlotRequest struct {
+	Slot string `json:"slot" binding:"omitempty,max=100"`
+}
+
 type ApplyToGroupRequest struct {
 	Pitch string `json:"pitch" binding:"required,min=50,max=500"`
+	Slot  string `json:"slot" binding:"omitempty,max=100"`
 }
 
 type ApproveApplicationRequest struct {
@@ -94,10 +499,53 @@ type ApproveApplicationRequest struct {
 }
 
 type DiscoverGroupsRequest struct {
+	Query      string   `json:"q" form:"q" binding:"omitempty,max=200"`
 	Tags       []string `json:"tags" form:"tags"`
 	SkillLevel string   `json:"skill_level" form:"skill_level"`
 	JoinType   string   `json:"join_type" form:"join_type"`
 	Limit      int      `json:"limit" form:"limit" binding:"omitempty,min=1,max=100"`
+	Expand     bool     `json:"expand" form:"expand"` // widen to related tags when matches are scarce
+
+	// Only groups whose schedule overlaps the caller's by at least this many hours a week
+	MinOverlapHours float64 `json:"min_overlap_hours" form:"min_overlap_hours" binding:"omitempty,min=0,max=168"`
+
+	// Location filters apply to in-person groups only; lat/lon default to the caller's profile
+	MeetingMode string   `json:"meeting_mode" form:"meeting_mode" binding:"omitempty,oneof=ONLINE IN_PERSON HYBRID"`
+	Latitude    *float64 `json:"lat" form:"lat" binding:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude   *float64 `json:"lon" form:"lon" binding:"required_with=Latitude,omitempty,min=-180,max=180"`
+	RadiusKm    float64  `json:"radius_km" form:"radius_km" binding:"omitempty,gt=0,max=20000"`
+	City        string   `json:"city" form:"city" binding:"omitempty,max=100"`
+
+	// Time zone filter applies to all groups; tz defaults to the caller's profile
+	TimeZone         string   `json:"tz" form:"tz" binding:"omitempty,max=64"`
+	MaxTZOffsetHours *float64 `json:"max_tz_offset_hours" form:"max_tz_offset_hours" binding:"omitempty,min=0,max=26"`
+
+	// Languages default to the caller's profile
+	Languages    []string `json:"languages" form:"languages"`
+	LanguageMode string   `json:"language_mode" form:"language_mode" binding:"omitempty,oneof=FILTER SCORE"`
+
+	// For authenticated callers these groups are hidden unless requested
+	IncludeJoined   bool `json:"include_joined" form:"include_joined"`
+	IncludeApplied  bool `json:"include_applied" form:"include_applied"`   // pending applications
+	IncludeRejected bool `json:"include_rejected" form:"include_rejected"` // rejected applications
+}
+
+type CreateSavedSearchRequest struct {
+	Name     string                `json:"name" binding:"required,min=1,max=100"`
+	Filters  DiscoverGroupsRequest `json:"filters"`
+	MinScore *float64              `json:"min_score" binding:"omitempty,min=0,max=1"`
+}
+
+// UpdateSavedSearchRequest changes only the fields that are set
+type UpdateSavedSearchRequest struct {
+	Name     *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	MinScore *float64 `json:"min_score" binding:"omitempty,min=0,max=1"`
+	Paused   *bool    `json:"paused"`
+}
+
+type SavedSearchesResponse struct {
+	SavedSearches []*SavedSearch `json:"saved_searches"`
+	Total         int            `json:"total"`
 }
 
 type GroupResponse struct {
@@ -112,6 +560,7 @@ type GroupMemberResponse struct {
 	UserID   string    `json:"user_id"`
 	FullName string    `json:"full_name"`
 	Role     string    `json:"role"`
+	Slot     string    `json:"slot,omitempty"`
 	JoinedAt time.Time `json:"joined_at"`
 }
 
@@ -120,10 +569,31 @@ type DiscoverGroupsResponse struct {
 	Total  int          `json:"total"`
 }
 
+type CandidatesResponse struct {
+	Candidates []CandidateMatch `json:"candidates"`
+	Total      int              `json:"total"`
+}
+
+type BuddySuggestionsResponse struct {
+	Suggestions []BuddySuggestion `json:"suggestions"`
+	Total       int               `json:"total"`
+}
+
+type BuddyMatchesResponse struct {
+	Matches []*BuddyMatch `json:"matches"`
+	Total   int           `json:"total"`
+}
+
 type UserProfile struct {
-	UserID       string   `json:"user_id"`
-	Tags         []string `json:"tags"`
-	SkillLevel   string   `json:"skill_level"`
-	Availability []string `json:"availability"`
-	Intent       string   `json:"intent"`
+	UserID       string                `json:"user_id"`
+	FullName     string                `json:"full_name,omitempty"`
+	Tags         []string              `json:"tags"`
+	SkillLevel   string                `json:"skill_level"`
+	Availability []string              `json:"availability"`
+	Schedule     availability.Schedule `json:"schedule"`
+	Intent       string                `json:"intent"`
+	Location     *geo.Point            `json:"location,omitempty"`
+	City         string                `json:"city,omitempty"`
+	TimeZone     string                `json:"time_zone,omitempty"`
+	Languages    []language.Spoken     `json:"languages,omitempty"`
 }
@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"bmatch/pkg/db"
+)
+
+type Repository interface {
+	CreateNotification(ctx context.Context, n *Notification) error
+	ListNotifications(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*Notification, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID string, id int64) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type repository struct {
+	db db.SQLExecutor
+}
+
+func NewRepository(database db.SQLExecutor) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+// CreateNotification stores a notification
+func (r *repository) CreateNotification(ctx context.Context, n *Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, type, group_id, data)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, n.UserID, n.Type, n.GroupID, []byte(n.Data)).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert notification: %w", err)
+	}
+
+	return nil
+}
+
+// ListNotifications retrieves a user's notifications, newest first
+func (r *repository) ListNotifications(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*Notification, error) {
+	query := `
+		SELECT id, user_id, type, COALESCE(group_id::text, ''), data, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+	`
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT $2"
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]*Notification, 0)
+	for rows.Next() {
+		var n Notification
+		var data []byte
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.GroupID, &data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		n.Data = data
+		notifications = append(notifications, &n)
+	}
+
+	return notifications, nil
+}
+
+// CountUnread counts a user's unread notifications
+func (r *repository) CountUnread(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead marks one of a user's notifications as read
+func (r *repository) MarkRead(ctx context.Context, userID string, id int64) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("mark notification read: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+// MarkAllRead marks all of a user's notifications as read
+func (r *repository) MarkAllRead(ctx context.Context, userID string) error {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("mark notifications read: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+
+	"bmatch/pkg/logger"
+)
+
+type Service struct {
+	repo   Repository
+	logger logger.Logger
+}
+
+func NewService(repo Repository, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Notify stores a notification for the user with data as its details.
+// Failures are logged rather than returned so notifying never breaks the
+// action that caused it.
+func (s *Service) Notify(ctx context.Context, userID, notificationType, groupID string, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil || data == nil {
+		raw = []byte("{}")
+	}
+
+	n := &Notification{
+		UserID:  userID,
+		Type:    notificationType,
+		GroupID: groupID,
+		Data:    raw,
+	}
+
+	if err := s.repo.CreateNotification(ctx, n); err != nil {
+		s.logger.Warn(ctx, "failed to create notification",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "type", Value: notificationType},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
+
+// ListNotifications returns a user's newest notifications and their unread count
+func (s *Service) ListNotifications(ctx context.Context, userID string, req ListNotificationsRequest) (*NotificationsResponse, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	notifications, err := s.repo.ListNotifications(ctx, userID, req.UnreadOnly, limit)
+	if err != nil {
+		s.logger.Error(ctx, "failed to list notifications",
+			logger.Field{Key: "user_id", Value: userID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationsResponse{
+		Notifications: notifications,
+		Unread:        unread,
+	}, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *Service) MarkRead(ctx context.Context, userID string, id int64) error {
+	return s.repo.MarkRead(ctx, userID, id)
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (s *Service) MarkAllRead(ctx context.Context, userID string) error {
+	return s.repo.MarkAllRead(ctx, userID)
+}
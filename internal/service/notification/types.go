@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification tells a user about something that happened, usually to a group
+type Notification struct {
+	ID        int64           `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	GroupID   string          `json:"group_id,omitempty"`
+	Data      json.RawMessage `json:"data"` // type-specific details
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// DTOs
+type ListNotificationsRequest struct {
+	UnreadOnly bool `form:"unread"`
+	Limit      int  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type NotificationsResponse struct {
+	Notifications []*Notification `json:"notifications"`
+	Unread        int             `json:"unread"`
+}
@@ -3,6 +3,7 @@ package session
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"bmatch/pkg/cache"
@@ -65,9 +66,12 @@ func (s *RedisStore) Get(sessionID string) (*Session, error) {
 
 	// Retrieve from Redis
 	data, err := s.cache.Get(s.ctx, key)
-	if err != nil {
+	if errors.Is(err, cache.ErrCacheMiss) {
 		return nil, ErrSessionNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Deserialize session
 	var session Session
@@ -135,9 +139,12 @@ func (s *RedisStore) GetWithContext(ctx context.Context, sessionID string) (*Ses
 	key := sessionPrefix + sessionID
 
 	data, err := s.cache.Get(ctx, key)
-	if err != nil {
+	if errors.Is(err, cache.ErrCacheMiss) {
 		return nil, ErrSessionNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	var session Session
 	if err := json.Unmarshal([]byte(data), &session); err != nil {
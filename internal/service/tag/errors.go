@@ -0,0 +1,14 @@
+package tag
+
+import "errors"
+
+var (
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagExists        = errors.New("tag already exists")
+	ErrInvalidTag       = errors.New("invalid tag")
+	ErrAliasNotFound    = errors.New("alias not found")
+	ErrAliasExists      = errors.New("alias already exists")
+	ErrAliasIsCanonical = errors.New("alias conflicts with a canonical tag")
+	ErrInvalidAliasKind = errors.New("invalid alias kind")
+	ErrTaxonomyCycle    = errors.New("parent would create a cycle in the taxonomy")
+)
@@ -0,0 +1,182 @@
+package tag
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// SuggestTags handles GET /tags/suggest
+func (h *Handler) SuggestTags(c *gin.Context) {
+	var req SuggestTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	suggestions, err := h.service.SuggestTags(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuggestTagsResponse{
+		Suggestions: suggestions,
+		Total:       len(suggestions),
+	})
+}
+
+// PopularTags handles GET /tags/popular
+func (h *Handler) PopularTags(c *gin.Context) {
+	var req PopularTagsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := h.service.PopularTags(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, PopularTagsResponse{
+		Tags:  tags,
+		Total: len(tags),
+	})
+}
+
+// ListTags handles GET /admin/tags
+func (h *Handler) ListTags(c *gin.Context) {
+	tags, err := h.service.ListTags(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListTagsResponse{
+		Tags:  tags,
+		Total: len(tags),
+	})
+}
+
+// ListCandidates handles GET /admin/tags/candidates
+func (h *Handler) ListCandidates(c *gin.Context) {
+	var req ListCandidatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	candidates, err := h.service.ListCandidates(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListCandidatesResponse{
+		Candidates: candidates,
+		Total:      len(candidates),
+	})
+}
+
+// CreateTag handles POST /admin/tags
+func (h *Handler) CreateTag(c *gin.Context) {
+	var req CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.CreateTag(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// CreateAlias handles POST /admin/tags/:name/aliases
+func (h *Handler) CreateAlias(c *gin.Context) {
+	tagName := c.Param("name")
+
+	var req CreateAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias, err := h.service.CreateAlias(c.Request.Context(), tagName, req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// DeleteAlias handles DELETE /admin/tags/aliases/:alias
+func (h *Handler) DeleteAlias(c *gin.Context) {
+	alias := c.Param("alias")
+
+	if err := h.service.DeleteAlias(c.Request.Context(), alias); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "alias deleted"})
+}
+
+// SetParent handles PUT /admin/tags/:name/parent
+func (h *Handler) SetParent(c *gin.Context) {
+	tagName := c.Param("name")
+
+	var req SetParentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.SetParent(c.Request.Context(), tagName, req); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag parent updated"})
+}
+
+// handleError maps domain errors to HTTP status codes
+func (h *Handler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAliasNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTagExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAliasExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrAliasIsCanonical):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTag):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidAliasKind):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTaxonomyCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,468 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"bmatch/pkg/db"
+
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	// Catalog operations
+	ListTags(ctx context.Context) ([]*Tag, error)
+	CreateTag(ctx context.Context, tag *Tag) error
+	TagExists(ctx context.Context, name string) (bool, error)
+	LockTag(ctx context.Context, tx *sql.Tx, name string) (bool, error)
+	MergeTag(ctx context.Context, tx *sql.Tx, from, to string) error
+
+	// Taxonomy operations
+	GetParents(ctx context.Context) (map[string]string, error)
+	SetParent(ctx context.Context, name, parent string) error
+
+	// Discovery operations
+	SuggestTags(ctx context.Context, prefix string, limit int) ([]TagSuggestion, error)
+	ListCandidates(ctx context.Context, limit int) ([]TagCandidate, error)
+	GetPopularTags(ctx context.Context, since time.Time, limit int) ([]PopularTag, error)
+
+	// Alias operations
+	ResolveAliases(ctx context.Context, names []string) (map[string]string, error)
+	CreateAlias(ctx context.Context, tx *sql.Tx, alias *Alias) error
+	DeleteAlias(ctx context.Context, alias string) error
+	RewriteTags(ctx context.Context, tx *sql.Tx, from, to string) (*Rewrite, error)
+
+	// Transaction helper
+	WithTransaction(ctx context.Context, isolation sql.IsolationLevel, fn db.TxFunc) error
+}
+
+type repository struct {
+	db db.SQLExecutor
+}
+
+func NewRepository(database db.SQLExecutor) Repository {
+	return &repository{
+		db: database,
+	}
+}
+
+// ListTags retrieves all canonical tags together with their aliases
+func (r *repository) ListTags(ctx context.Context) ([]*Tag, error) {
+	query := `
+		SELECT t.name, t.parent_name, t.created_at, a.alias, a.kind, a.created_at
+		FROM tags t
+		LEFT JOIN tag_aliases a ON a.tag_name = t.name
+		ORDER BY t.name ASC, a.alias ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]*Tag, 0)
+	var current *Tag
+	for rows.Next() {
+		var name string
+		var createdAt time.Time
+		var parent, alias, kind sql.NullString
+		var aliasCreatedAt sql.NullTime
+
+		if err := rows.Scan(&name, &parent, &createdAt, &alias, &kind, &aliasCreatedAt); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+
+		if current == nil || current.Name != name {
+			current = &Tag{Name: name, Parent: parent.String, Aliases: []Alias{}, CreatedAt: createdAt}
+			tags = append(tags, current)
+		}
+
+		if alias.Valid {
+			current.Aliases = append(current.Aliases, Alias{
+				Alias:     alias.String,
+				TagName:   name,
+				Kind:      kind.String,
+				CreatedAt: aliasCreatedAt.Time,
+			})
+		}
+	}
+
+	return tags, nil
+}
+
+// CreateTag inserts a canonical tag, promoting the candidate of the same name
+func (r *repository) CreateTag(ctx context.Context, tag *Tag) error {
+	query := `
+		WITH promoted AS (
+			DELETE FROM tag_candidates WHERE name = $1
+		)
+		INSERT INTO tags (name)
+		VALUES ($1)
+		ON CONFLICT DO NOTHING
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, tag.Name).Scan(&tag.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrTagExists
+	}
+	if err != nil {
+		return fmt.Errorf("insert tag: %w", err)
+	}
+
+	return nil
+}
+
+// TagExists checks if a canonical tag exists
+func (r *repository) TagExists(ctx context.Context, name string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM tags WHERE name = $1)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check tag: %w", err)
+	}
+
+	return exists, nil
+}
+
+// LockTag locks a canonical tag for the rest of the transaction and reports
+// whether it exists
+func (r *repository) LockTag(ctx context.Context, tx *sql.Tx, name string) (bool, error) {
+	query := `SELECT name FROM tags WHERE name = $1 FOR UPDATE`
+
+	err := tx.QueryRowContext(ctx, query, name).Scan(&name)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("lock tag: %w", err)
+	}
+
+	return true, nil
+}
+
+// MergeTag folds a canonical tag into another: its children and aliases move
+// to the target, which takes its place in the taxonomy if it was a child,
+// and the tag is removed from the catalog. Uses are rewritten by RewriteTags.
+func (r *repository) MergeTag(ctx context.Context, tx *sql.Tx, from, to string) error {
+	queries := []struct {
+		name  string
+		query string
+	}{
+		{"move target up", `
+			UPDATE tags SET parent_name = (SELECT parent_name FROM tags WHERE name = $1)
+			WHERE name = $2 AND parent_name = $1
+		`},
+		{"move children", `UPDATE tags SET parent_name = $2 WHERE parent_name = $1`},
+		{"move aliases", `UPDATE tag_aliases SET tag_name = $2 WHERE tag_name = $1`},
+		{"delete tag", `DELETE FROM tags WHERE name = $1`},
+	}
+
+	for _, q := range queries {
+		if _, err := tx.ExecContext(ctx, q.query, from, to); err != nil {
+			return fmt.Errorf("merge tag: %s: %w", q.name, err)
+		}
+	}
+
+	return nil
+}
+
+// GetParents retrieves every child -> parent edge of the taxonomy
+func (r *repository) GetParents(ctx context.Context) (map[string]string, error) {
+	query := `SELECT name, parent_name FROM tags WHERE parent_name IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tag parents: %w", err)
+	}
+	defer rows.Close()
+
+	parents := make(map[string]string)
+	for rows.Next() {
+		var name, parent string
+		if err := rows.Scan(&name, &parent); err != nil {
+			return nil, fmt.Errorf("scan tag parent: %w", err)
+		}
+		parents[name] = parent
+	}
+
+	return parents, nil
+}
+
+// SetParent sets or, when parent is empty, clears the parent of a tag
+func (r *repository) SetParent(ctx context.Context, name, parent string) error {
+	query := `UPDATE tags SET parent_name = NULLIF($2, '') WHERE name = $1`
+
+	result, err := r.db.ExecContext(ctx, query, name, parent)
+	if err != nil {
+		return fmt.Errorf("update tag parent: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrTagNotFound
+	}
+
+	return nil
+}
+
+// SuggestTags finds canonical tags whose name or alias starts with prefix or
+// is trigram-similar to it. Prefix matches always rank above fuzzy matches.
+// Candidate tags are suggested at half their score, below canonical prefix
+// matches.
+func (r *repository) SuggestTags(ctx context.Context, prefix string, limit int) ([]TagSuggestion, error) {
+	query := `
+		WITH matches AS (
+			SELECT name AS tag_name, name AS matched, false AS candidate FROM tags
+			WHERE name LIKE $2 OR name % $1
+			UNION ALL
+			SELECT tag_name, alias AS matched, false AS candidate FROM tag_aliases
+			WHERE alias LIKE $2 OR alias % $1
+			UNION ALL
+			SELECT name AS tag_name, name AS matched, true AS candidate FROM tag_candidates
+			WHERE name LIKE $2 OR name % $1
+		), scored AS (
+			SELECT DISTINCT ON (tag_name) tag_name, matched, candidate,
+			       CASE WHEN matched LIKE $2 THEN 1 + similarity(matched, $1)
+			            ELSE similarity(matched, $1) END
+			       * CASE WHEN candidate THEN 0.5 ELSE 1 END AS score
+			FROM matches
+			ORDER BY tag_name, score DESC
+		)
+		SELECT tag_name, matched, candidate, score
+		FROM scored
+		ORDER BY score DESC, tag_name ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, prefix, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("query tag suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]TagSuggestion, 0)
+	for rows.Next() {
+		var s TagSuggestion
+		if err := rows.Scan(&s.Name, &s.Matched, &s.Candidate, &s.Score); err != nil {
+			return nil, fmt.Errorf("scan tag suggestion: %w", err)
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions, nil
+}
+
+// ListCandidates retrieves the most used tags that are not in the catalog
+func (r *repository) ListCandidates(ctx context.Context, limit int) ([]TagCandidate, error) {
+	query := `
+		SELECT name, use_count, first_used_at, last_used_at
+		FROM tag_candidates
+		ORDER BY use_count DESC, name ASC
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query tag candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]TagCandidate, 0)
+	for rows.Next() {
+		var c TagCandidate
+		if err := rows.Scan(&c.Name, &c.UseCount, &c.FirstUsedAt, &c.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("scan tag candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+}
+
+// GetPopularTags counts tag usage across groups created and profiles updated since the given time
+func (r *repository) GetPopularTags(ctx context.Context, since time.Time, limit int) ([]PopularTag, error) {
+	query := `
+		SELECT value,
+		       COUNT(*) AS total,
+		       COUNT(*) FILTER (WHERE source = 'group') AS group_count,
+		       COUNT(*) FILTER (WHERE source = 'user') AS user_count
+		FROM (
+			SELECT jsonb_array_elements_text(tags) AS value, 'group' AS source
+			FROM groups WHERE created_at >= $1
+			UNION ALL
+			SELECT jsonb_array_elements_text(tags) AS value, 'user' AS source
+			FROM users WHERE updated_at >= $1
+		) usage
+		GROUP BY value
+		ORDER BY total DESC, value ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query popular tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]PopularTag, 0)
+	for rows.Next() {
+		var t PopularTag
+		if err := rows.Scan(&t.Name, &t.Count, &t.GroupCount, &t.UserCount); err != nil {
+			return nil, fmt.Errorf("scan popular tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+}
+
+// ResolveAliases maps each known alias among names to its canonical tag
+func (r *repository) ResolveAliases(ctx context.Context, names []string) (map[string]string, error) {
+	resolved := make(map[string]string)
+	if len(names) == 0 {
+		return resolved, nil
+	}
+
+	query := `SELECT alias, tag_name FROM tag_aliases WHERE alias = ANY($1)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(names))
+	if err != nil {
+		return nil, fmt.Errorf("query aliases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias, tagName string
+		if err := rows.Scan(&alias, &tagName); err != nil {
+			return nil, fmt.Errorf("scan alias: %w", err)
+		}
+		resolved[alias] = tagName
+	}
+
+	return resolved, nil
+}
+
+// CreateAlias inserts an alias for a canonical tag, retiring the candidate
+// of the same name
+func (r *repository) CreateAlias(ctx context.Context, tx *sql.Tx, alias *Alias) error {
+	query := `
+		WITH retired AS (
+			DELETE FROM tag_candidates WHERE name = $1
+		)
+		INSERT INTO tag_aliases (alias, tag_name, kind)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING created_at
+	`
+
+	err := tx.QueryRowContext(ctx, query, alias.Alias, alias.TagName, alias.Kind).Scan(&alias.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrAliasExists
+	}
+	if err != nil {
+		return fmt.Errorf("insert alias: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAlias removes an alias
+func (r *repository) DeleteAlias(ctx context.Context, alias string) error {
+	query := `DELETE FROM tag_aliases WHERE alias = $1`
+
+	result, err := r.db.ExecContext(ctx, query, alias)
+	if err != nil {
+		return fmt.Errorf("delete alias: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrAliasNotFound
+	}
+
+	return nil
+}
+
+// RewriteTags replaces a tag on every user, group, role slot and saved
+// search that carries it, dropping the duplicate if the target tag is
+// already present
+func (r *repository) RewriteTags(ctx context.Context, tx *sql.Tx, from, to string) (*Rewrite, error) {
+	rewrite := &Rewrite{From: from, To: to, UserIDs: []string{}, GroupIDs: []string{}}
+
+	// rewritten is the tags array in expr with from replaced by to
+	rewritten := func(expr string) string {
+		return fmt.Sprintf(`(
+			SELECT COALESCE(jsonb_agg(DISTINCT CASE WHEN value = $1 THEN $2 ELSE value END), '[]'::jsonb)
+			FROM jsonb_array_elements_text(%s)
+		)`, expr)
+	}
+
+	targets := []struct {
+		name  string
+		query string
+		ids   *[]string
+	}{
+		{"users", `UPDATE users t SET tags = ` + rewritten("t.tags") + `
+			WHERE t.tags ? $1
+			RETURNING t.id`, &rewrite.UserIDs},
+		{"groups", `UPDATE groups t SET tags = ` + rewritten("t.tags") + `
+			WHERE t.tags ? $1
+			RETURNING t.id`, &rewrite.GroupIDs},
+		{"role slots", `UPDATE groups g SET role_slots = (
+				SELECT jsonb_agg(CASE WHEN s.slot->'tags' ? $1 THEN jsonb_set(s.slot, '{tags}', ` + rewritten("s.slot->'tags'") + `) ELSE s.slot END ORDER BY s.ord)
+				FROM jsonb_array_elements(g.role_slots) WITH ORDINALITY AS s(slot, ord)
+			)
+			WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(g.role_slots) AS s(slot) WHERE s.slot->'tags' ? $1)
+			RETURNING g.id`, &rewrite.GroupIDs},
+		{"saved searches", `UPDATE saved_searches t SET filters = jsonb_set(t.filters, '{tags}', ` + rewritten("t.filters->'tags'") + `)
+			WHERE t.filters->'tags' ? $1
+			RETURNING t.id`, nil},
+	}
+
+	for _, target := range targets {
+		rows, err := tx.QueryContext(ctx, target.query, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("rewrite %s tags: %w", target.name, err)
+		}
+
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan rewritten %s id: %w", target.name, err)
+			}
+			if target.ids != nil {
+				*target.ids = append(*target.ids, id)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("rewrite %s tags: %w", target.name, err)
+		}
+	}
+
+	return rewrite, nil
+}
+
+// WithTransaction executes a function within a database transaction
+func (r *repository) WithTransaction(ctx context.Context, isolation sql.IsolationLevel, fn db.TxFunc) error {
+	return r.db.WithTransaction(ctx, isolation, fn)
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,483 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/logger"
+	"bmatch/pkg/taxonomy"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo   Repository
+	cache  cache.Cache
+	config cfg.TagConfig
+	logger logger.Logger
+
+	// taxonomy snapshot shared by matchers, reloaded after taxonomyRefreshInterval
+	mu       sync.RWMutex
+	taxonomy *taxonomy.Taxonomy
+	loadedAt time.Time
+
+	// OnRewrite is called after tags on users and groups were rewritten and
+	// committed, so services caching them can invalidate
+	OnRewrite func(ctx context.Context, rewrite Rewrite)
+}
+
+func NewService(repo Repository, cache cache.Cache, config cfg.TagConfig, logger logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		cache:  cache,
+		config: config,
+		logger: logger,
+	}
+}
+
+// NormalizeTag folds case, trims and collapses inner whitespace.
+// It does not resolve aliases.
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// Normalize normalizes tags, resolves aliases to their canonical tag and
+// removes empty and duplicate entries while keeping the original order
+func (s *Service) Normalize(ctx context.Context, tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if n := NormalizeTag(t); n != "" {
+			normalized = append(normalized, n)
+		}
+	}
+
+	aliases, err := s.repo.ResolveAliases(ctx, normalized)
+	if err != nil {
+		s.logger.Error(ctx, "failed to resolve tag aliases", logger.Field{Key: "error", Value: err})
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(normalized))
+	result := make([]string, 0, len(normalized))
+	for _, n := range normalized {
+		if canonical, ok := aliases[n]; ok {
+			n = canonical
+		}
+		if !seen[n] {
+			seen[n] = true
+			result = append(result, n)
+		}
+	}
+
+	return result, nil
+}
+
+// ListTags retrieves the tag catalog
+func (s *Service) ListTags(ctx context.Context) ([]*Tag, error) {
+	tags, err := s.repo.ListTags(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "failed to list tags", logger.Field{Key: "error", Value: err})
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// ListCandidates retrieves the most used tags not yet in the catalog, for
+// admins to create as tags or alias to existing ones
+func (s *Service) ListCandidates(ctx context.Context, req ListCandidatesRequest) ([]TagCandidate, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	candidates, err := s.repo.ListCandidates(ctx, limit)
+	if err != nil {
+		s.logger.Error(ctx, "failed to list tag candidates", logger.Field{Key: "error", Value: err})
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// CreateTag adds a canonical tag to the catalog
+func (s *Service) CreateTag(ctx context.Context, req CreateTagRequest) (*Tag, error) {
+	name := NormalizeTag(req.Name)
+	if name == "" || len(name) > MaxTagLength {
+		return nil, ErrInvalidTag
+	}
+
+	// A name that is already an alias must not become canonical as well
+	aliases, err := s.repo.ResolveAliases(ctx, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := aliases[name]; ok {
+		return nil, ErrAliasExists
+	}
+
+	tag := &Tag{Name: name, Aliases: []Alias{}}
+	if err := s.repo.CreateTag(ctx, tag); err != nil {
+		s.logger.Error(ctx, "failed to create tag",
+			logger.Field{Key: "tag", Value: name},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	s.invalidateSuggestions(ctx)
+
+	s.logger.Info(ctx, "tag created", logger.Field{Key: "tag", Value: name})
+	return tag, nil
+}
+
+// CreateAlias maps an alias to a canonical tag and rewrites existing
+// user and group tags that still use the alias. An alias that is itself a
+// canonical tag is merged into the target: its children and aliases move
+// over and it leaves the catalog.
+func (s *Service) CreateAlias(ctx context.Context, tagName string, req CreateAliasRequest) (*Alias, error) {
+	canonical := NormalizeTag(tagName)
+	aliasName := NormalizeTag(req.Alias)
+	if aliasName == "" || len(aliasName) > MaxTagLength {
+		return nil, ErrInvalidTag
+	}
+	if aliasName == canonical {
+		return nil, ErrAliasIsCanonical
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = AliasKindAlias
+	}
+	if kind != AliasKindAlias && kind != AliasKindSynonym {
+		return nil, ErrInvalidAliasKind
+	}
+
+	alias := &Alias{
+		Alias:   aliasName,
+		TagName: canonical,
+		Kind:    kind,
+	}
+
+	var rewrite *Rewrite
+	merged := false
+	err := s.repo.WithTransaction(ctx, sql.LevelReadCommitted, func(ctx context.Context, tx *sql.Tx) error {
+		exists, err := s.repo.LockTag(ctx, tx, canonical)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrTagNotFound
+		}
+
+		merged, err = s.repo.LockTag(ctx, tx, aliasName)
+		if err != nil {
+			return err
+		}
+		if merged {
+			if err := s.checkMerge(ctx, aliasName, canonical); err != nil {
+				return err
+			}
+			if err := s.repo.MergeTag(ctx, tx, aliasName, canonical); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.CreateAlias(ctx, tx, alias); err != nil {
+			return err
+		}
+
+		rewrite, err = s.repo.RewriteTags(ctx, tx, aliasName, canonical)
+		if err != nil {
+			return fmt.Errorf("rewrite tags: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		s.logger.Error(ctx, "failed to create alias",
+			logger.Field{Key: "tag", Value: canonical},
+			logger.Field{Key: "alias", Value: aliasName},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	if merged {
+		s.invalidateTaxonomy()
+	}
+	s.invalidateSuggestions(ctx)
+	if s.OnRewrite != nil {
+		s.OnRewrite(ctx, *rewrite)
+	}
+
+	s.logger.Info(ctx, "alias created",
+		logger.Field{Key: "tag", Value: canonical},
+		logger.Field{Key: "alias", Value: aliasName},
+		logger.Field{Key: "merged", Value: merged},
+		logger.Field{Key: "users", Value: len(rewrite.UserIDs)},
+		logger.Field{Key: "groups", Value: len(rewrite.GroupIDs)},
+	)
+
+	return alias, nil
+}
+
+// checkMerge rejects merging a tag into one of its descendants below its
+// direct children: moving the children under the target would form a cycle
+func (s *Service) checkMerge(ctx context.Context, from, to string) error {
+	parents, err := s.repo.GetParents(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := taxonomy.New(parents)
+	if parent, _ := t.Parent(to); t.IsAncestor(from, to) && parent != from {
+		return ErrTaxonomyCycle
+	}
+	return nil
+}
+
+// DeleteAlias removes an alias. Tags already rewritten stay canonical.
+func (s *Service) DeleteAlias(ctx context.Context, alias string) error {
+	if err := s.repo.DeleteAlias(ctx, NormalizeTag(alias)); err != nil {
+		s.logger.Error(ctx, "failed to delete alias",
+			logger.Field{Key: "alias", Value: alias},
+			logger.Field{Key: "error", Value: err},
+		)
+		return err
+	}
+
+	s.invalidateSuggestions(ctx)
+
+	s.logger.Info(ctx, "alias deleted", logger.Field{Key: "alias", Value: alias})
+	return nil
+}
+
+// Taxonomy returns the current tag taxonomy, reloading it when stale
+func (s *Service) Taxonomy(ctx context.Context) (*taxonomy.Taxonomy, error) {
+	s.mu.RLock()
+	t, loadedAt := s.taxonomy, s.loadedAt
+	s.mu.RUnlock()
+
+	if t != nil && time.Since(loadedAt) < taxonomyRefreshInterval {
+		return t, nil
+	}
+
+	parents, err := s.repo.GetParents(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "failed to load taxonomy", logger.Field{Key: "error", Value: err})
+		// Serve the stale snapshot rather than failing discovery
+		if t != nil {
+			return t, nil
+		}
+		return nil, err
+	}
+
+	t = taxonomy.New(parents)
+
+	s.mu.Lock()
+	s.taxonomy = t
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return t, nil
+}
+
+// SetParent moves a tag under a parent tag, or detaches it when parent is empty
+func (s *Service) SetParent(ctx context.Context, tagName string, req SetParentRequest) error {
+	name := NormalizeTag(tagName)
+	parent := NormalizeTag(req.Parent)
+
+	if parent != "" {
+		if parent == name {
+			return ErrTaxonomyCycle
+		}
+
+		exists, err := s.repo.TagExists(ctx, parent)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrTagNotFound
+		}
+
+		// Read fresh edges so the cycle check sees concurrent admin edits
+		parents, err := s.repo.GetParents(ctx)
+		if err != nil {
+			return err
+		}
+		if taxonomy.New(parents).IsAncestor(name, parent) {
+			return ErrTaxonomyCycle
+		}
+	}
+
+	if err := s.repo.SetParent(ctx, name, parent); err != nil {
+		s.logger.Error(ctx, "failed to set tag parent",
+			logger.Field{Key: "tag", Value: name},
+			logger.Field{Key: "parent", Value: parent},
+			logger.Field{Key: "error", Value: err},
+		)
+		return err
+	}
+
+	s.invalidateTaxonomy()
+
+	s.logger.Info(ctx, "tag parent updated",
+		logger.Field{Key: "tag", Value: name},
+		logger.Field{Key: "parent", Value: parent},
+	)
+
+	return nil
+}
+
+// invalidateTaxonomy forces the next Taxonomy call to reload
+func (s *Service) invalidateTaxonomy() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// SuggestTags autocompletes a tag prefix using prefix and trigram fuzzy matching
+func (s *Service) SuggestTags(ctx context.Context, req SuggestTagsRequest) ([]TagSuggestion, error) {
+	prefix := NormalizeTag(req.Prefix)
+	if prefix == "" {
+		return nil, ErrInvalidTag
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = 10
+	}
+
+	version, cacheable := s.suggestVersion(ctx)
+	key := fmt.Sprintf("%s%s:%d:%s", suggestTagsCachePrefix, version, limit, prefix)
+	if cacheable {
+		if suggestions, err := cache.GetJSON[[]TagSuggestion](ctx, s.cache, key); err == nil {
+			return suggestions, nil
+		}
+	}
+
+	suggestions, err := s.repo.SuggestTags(ctx, prefix, limit)
+	if err != nil {
+		s.logger.Error(ctx, "failed to suggest tags",
+			logger.Field{Key: "prefix", Value: prefix},
+			logger.Field{Key: "error", Value: err},
+		)
+		return nil, err
+	}
+
+	if cacheable {
+		s.store(ctx, key, suggestions)
+	}
+	return suggestions, nil
+}
+
+// suggestVersion returns the generation of cached suggestions, starting one
+// when none is stored. Random generations keep an evicted version from
+// coming back and reviving stale entries. cacheable is false when the cache
+// is unavailable.
+func (s *Service) suggestVersion(ctx context.Context) (version string, cacheable bool) {
+	version, err := s.cache.Get(ctx, suggestTagsVersionKey)
+	if err == nil {
+		return version, true
+	}
+	if !errors.Is(err, cache.ErrCacheMiss) {
+		return "", false
+	}
+
+	if _, err := s.cache.SetNX(ctx, suggestTagsVersionKey, uuid.NewString(), 0); err != nil {
+		return "", false
+	}
+	version, err = s.cache.Get(ctx, suggestTagsVersionKey)
+	return version, err == nil
+}
+
+// invalidateSuggestions drops cached suggestions and popular tags after the
+// catalog or tag uses changed. Failures are logged; entries then live out
+// their TTL.
+func (s *Service) invalidateSuggestions(ctx context.Context) {
+	if err := s.cache.Set(ctx, suggestTagsVersionKey, uuid.NewString(), 0); err != nil {
+		s.logger.Warn(ctx, "failed to invalidate tag suggestions", logger.Field{Key: "error", Value: err})
+	}
+	if err := s.cache.Del(ctx, popularTagsCacheKey); err != nil {
+		s.logger.Warn(ctx, "failed to invalidate popular tags", logger.Field{Key: "error", Value: err})
+	}
+}
+
+// PopularTags returns the most used tags within the configured window,
+// served from the cache kept warm by StartPopularTagsRefresher
+func (s *Service) PopularTags(ctx context.Context, req PopularTagsRequest) ([]PopularTag, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	tags, err := cache.GetJSON[[]PopularTag](ctx, s.cache, popularTagsCacheKey)
+	if err != nil {
+		// Cold cache: compute inline
+		tags, err = s.RefreshPopularTags(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+	return tags, nil
+}
+
+// RefreshPopularTags recomputes popular tags and stores them in the cache
+func (s *Service) RefreshPopularTags(ctx context.Context) ([]PopularTag, error) {
+	since := time.Now().Add(-s.config.PopularWindow)
+
+	tags, err := s.repo.GetPopularTags(ctx, since, popularTagsMax)
+	if err != nil {
+		s.logger.Error(ctx, "failed to compute popular tags", logger.Field{Key: "error", Value: err})
+		return nil, err
+	}
+
+	s.store(ctx, popularTagsCacheKey, tags)
+	return tags, nil
+}
+
+// StartPopularTagsRefresher refreshes popular tags every RefreshInterval until ctx is done
+func (s *Service) StartPopularTagsRefresher(ctx context.Context) {
+	if s.config.RefreshInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.config.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			// Errors are logged by RefreshPopularTags; keep serving the previous value
+			s.RefreshPopularTags(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// store caches value as JSON. Cache failures are logged and otherwise ignored.
+func (s *Service) store(ctx context.Context, key string, value any) {
+	if err := cache.SetJSON(ctx, s.cache, key, value, s.config.CacheTTL); err != nil {
+		s.logger.Warn(ctx, "failed to cache tags",
+			logger.Field{Key: "key", Value: key},
+			logger.Field{Key: "error", Value: err},
+		)
+	}
+}
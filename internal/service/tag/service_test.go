@@ -0,0 +1,242 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"bmatch/cfg"
+	"bmatch/pkg/cache"
+	"bmatch/pkg/db"
+	"bmatch/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagRepo keeps the catalog, aliases and tagged users in memory
+type tagRepo struct {
+	Repository
+	parents map[string]string // every canonical tag, "" when it has no parent
+	aliases map[string]string
+	users   map[string][]string
+}
+
+func newTagRepo() *tagRepo {
+	return &tagRepo{
+		parents: map[string]string{},
+		aliases: map[string]string{},
+		users:   map[string][]string{},
+	}
+}
+
+func (r *tagRepo) ResolveAliases(_ context.Context, names []string) (map[string]string, error) {
+	resolved := make(map[string]string)
+	for _, n := range names {
+		if canonical, ok := r.aliases[n]; ok {
+			resolved[n] = canonical
+		}
+	}
+	return resolved, nil
+}
+
+func (r *tagRepo) WithTransaction(ctx context.Context, _ sql.IsolationLevel, fn db.TxFunc) error {
+	return fn(ctx, nil)
+}
+
+func (r *tagRepo) LockTag(_ context.Context, _ *sql.Tx, name string) (bool, error) {
+	_, ok := r.parents[name]
+	return ok, nil
+}
+
+func (r *tagRepo) GetParents(context.Context) (map[string]string, error) {
+	parents := make(map[string]string)
+	for name, parent := range r.parents {
+		if parent != "" {
+			parents[name] = parent
+		}
+	}
+	return parents, nil
+}
+
+func (r *tagRepo) MergeTag(_ context.Context, _ *sql.Tx, from, to string) error {
+	for name, parent := range r.parents {
+		if parent == from {
+			r.parents[name] = to
+		}
+	}
+	for alias, canonical := range r.aliases {
+		if canonical == from {
+			r.aliases[alias] = to
+		}
+	}
+	delete(r.parents, from)
+	return nil
+}
+
+func (r *tagRepo) CreateAlias(_ context.Context, _ *sql.Tx, alias *Alias) error {
+	if _, ok := r.aliases[alias.Alias]; ok {
+		return ErrAliasExists
+	}
+	r.aliases[alias.Alias] = alias.TagName
+	return nil
+}
+
+func (r *tagRepo) RewriteTags(_ context.Context, _ *sql.Tx, from, to string) (*Rewrite, error) {
+	rewrite := &Rewrite{From: from, To: to}
+	for userID, tags := range r.users {
+		for i, t := range tags {
+			if t == from {
+				tags[i] = to
+				rewrite.UserIDs = append(rewrite.UserIDs, userID)
+			}
+		}
+	}
+	return rewrite, nil
+}
+
+func newTestService(repo Repository) *Service {
+	return NewService(repo, cache.NewMemoryCache(0), cfg.TagConfig{}, logger.NewLogger("test"))
+}
+
+func TestNormalizeTag(t *testing.T) {
+	cases := map[string]string{
+		"Go":                    "go",
+		"  Machine   Learning ": "machine learning",
+		"C++":                   "c++",
+		" \t ":                  "",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, NormalizeTag(in), in)
+	}
+}
+
+func TestService_Normalize(t *testing.T) {
+	repo := newTagRepo()
+	repo.aliases["golang"] = "go"
+	repo.aliases["js"] = "javascript"
+	s := newTestService(repo)
+
+	cases := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "folds case and trims", tags: []string{" Rust ", "MACHINE  learning"}, want: []string{"rust", "machine learning"}},
+		{name: "resolves aliases", tags: []string{"Golang", "JS"}, want: []string{"go", "javascript"}},
+		{name: "drops empty tags", tags: []string{"", "  ", "go"}, want: []string{"go"}},
+		{name: "drops duplicates after resolving", tags: []string{"go", "golang", "Go"}, want: []string{"go"}},
+		{name: "keeps the original order", tags: []string{"rust", "js", "go"}, want: []string{"rust", "javascript", "go"}},
+		{name: "no tags", tags: nil, want: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.Normalize(context.Background(), tc.tags)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestService_CreateAlias(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rewrites tags using the alias", func(t *testing.T) {
+		repo := newTagRepo()
+		repo.parents["go"] = ""
+		repo.users["u1"] = []string{"golang", "rust"}
+		s := newTestService(repo)
+
+		var rewrites []Rewrite
+		s.OnRewrite = func(_ context.Context, rewrite Rewrite) { rewrites = append(rewrites, rewrite) }
+
+		alias, err := s.CreateAlias(ctx, "Go", CreateAliasRequest{Alias: " GoLang "})
+		require.NoError(t, err)
+		assert.Equal(t, &Alias{Alias: "golang", TagName: "go", Kind: AliasKindAlias}, alias)
+		assert.Equal(t, "go", repo.aliases["golang"])
+		assert.Equal(t, []string{"go", "rust"}, repo.users["u1"])
+		assert.Equal(t, []Rewrite{{From: "golang", To: "go", UserIDs: []string{"u1"}}}, rewrites)
+	})
+
+	t.Run("merges an existing canonical tag", func(t *testing.T) {
+		repo := newTagRepo()
+		repo.parents["backend"] = ""
+		repo.parents["go"] = "backend"
+		repo.parents["golang"] = ""
+		repo.parents["gin"] = "golang"
+		repo.aliases["go-lang"] = "golang"
+		repo.users["u1"] = []string{"golang"}
+		s := newTestService(repo)
+
+		// Load the taxonomy before the merge so a stale snapshot would show
+		before, err := s.Taxonomy(ctx)
+		require.NoError(t, err)
+		parent, _ := before.Parent("gin")
+		require.Equal(t, "golang", parent)
+
+		var rewrites []Rewrite
+		s.OnRewrite = func(_ context.Context, rewrite Rewrite) { rewrites = append(rewrites, rewrite) }
+
+		_, err = s.CreateAlias(ctx, "go", CreateAliasRequest{Alias: "golang", Kind: AliasKindSynonym})
+		require.NoError(t, err)
+
+		assert.NotContains(t, repo.parents, "golang", "merged tag leaves the catalog")
+		assert.Equal(t, "go", repo.parents["gin"], "children move to the target")
+		assert.Equal(t, "go", repo.aliases["go-lang"], "aliases move to the target")
+		assert.Equal(t, "go", repo.aliases["golang"])
+		assert.Equal(t, []string{"go"}, repo.users["u1"])
+		assert.Equal(t, []Rewrite{{From: "golang", To: "go", UserIDs: []string{"u1"}}}, rewrites)
+
+		after, err := s.Taxonomy(ctx)
+		require.NoError(t, err)
+		parent, _ = after.Parent("gin")
+		assert.Equal(t, "go", parent, "taxonomy is reloaded after a merge")
+
+		got, err := s.Normalize(ctx, []string{"Go-Lang", "golang", "gin"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "gin"}, got)
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			tag   string
+			req   CreateAliasRequest
+			setup func(r *tagRepo)
+			err   error
+		}{
+			{name: "empty alias", tag: "go", req: CreateAliasRequest{Alias: "  "}, err: ErrInvalidTag},
+			{name: "alias of itself", tag: "Go", req: CreateAliasRequest{Alias: " go"}, err: ErrAliasIsCanonical},
+			{name: "unknown kind", tag: "go", req: CreateAliasRequest{Alias: "golang", Kind: "nickname"}, err: ErrInvalidAliasKind},
+			{name: "unknown tag", tag: "zig", req: CreateAliasRequest{Alias: "ziglang"}, err: ErrTagNotFound},
+			{
+				name: "merge into a grandchild",
+				tag:  "gin",
+				req:  CreateAliasRequest{Alias: "backend"},
+				setup: func(r *tagRepo) {
+					r.parents["backend"] = ""
+					r.parents["gin"] = "go"
+				},
+				err: ErrTaxonomyCycle,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				repo := newTagRepo()
+				repo.parents["go"] = "backend"
+				if tc.setup != nil {
+					tc.setup(repo)
+				}
+				s := newTestService(repo)
+				s.OnRewrite = func(context.Context, Rewrite) { t.Error("rewrite reported for a rejected alias") }
+
+				alias, err := s.CreateAlias(ctx, tc.tag, tc.req)
+				assert.ErrorIs(t, err, tc.err)
+				assert.Nil(t, alias)
+				assert.Empty(t, repo.aliases)
+			})
+		}
+	})
+}
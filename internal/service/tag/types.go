@@ -0,0 +1,116 @@
+package tag
+
+import "time"
+
+const (
+	// Alias kinds
+	AliasKindAlias   = "ALIAS"   // alternative spelling, e.g. golang -> go
+	AliasKindSynonym = "SYNONYM" // equivalent term, e.g. front-end -> frontend
+
+	// Maximum length of a normalized tag name
+	MaxTagLength = 64
+
+	// How long a loaded taxonomy is reused before it is read again
+	taxonomyRefreshInterval = 5 * time.Minute
+
+	// Popular tags are computed once for this many tags and sliced per request
+	popularTagsMax = 100
+
+	// Cache keys
+	popularTagsCacheKey    = "tags:popular"
+	suggestTagsCachePrefix = "tags:suggest:"
+	suggestTagsVersionKey  = "tags:suggest:ver" // bumped when the catalog changes
+)
+
+// Domain Models
+type Tag struct {
+	Name      string    `json:"name"`
+	Parent    string    `json:"parent,omitempty"`
+	Aliases   []Alias   `json:"aliases"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagCandidate is a tag in use that is neither canonical nor an alias
+type TagCandidate struct {
+	Name        string    `json:"name"`
+	UseCount    int       `json:"use_count"`
+	FirstUsedAt time.Time `json:"first_used_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+}
+
+type Alias struct {
+	Alias     string    `json:"alias"`
+	TagName   string    `json:"tag_name"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Rewrite lists the users and groups whose tags were rewritten from one
+// tag to another
+type Rewrite struct {
+	From     string
+	To       string
+	UserIDs  []string
+	GroupIDs []string
+}
+
+// DTOs
+type CreateTagRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=64"`
+}
+
+type CreateAliasRequest struct {
+	Alias string `json:"alias" binding:"required,min=1,max=64"`
+	Kind  string `json:"kind" binding:"omitempty,oneof=ALIAS SYNONYM"`
+}
+
+type SetParentRequest struct {
+	Parent string `json:"parent" binding:"omitempty,max=64"` // empty detaches the tag
+}
+
+type SuggestTagsRequest struct {
+	Prefix string `form:"prefix" binding:"required,min=1,max=64"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+type ListCandidatesRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type PopularTagsRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type TagSuggestion struct {
+	Name      string  `json:"name"`
+	Matched   string  `json:"matched"`             // tag or alias that matched the prefix
+	Candidate bool    `json:"candidate,omitempty"` // in use but not yet in the catalog
+	Score     float64 `json:"score"`
+}
+
+type PopularTag struct {
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+	GroupCount int    `json:"group_count"`
+	UserCount  int    `json:"user_count"`
+}
+
+type SuggestTagsResponse struct {
+	Suggestions []TagSuggestion `json:"suggestions"`
+	Total       int             `json:"total"`
+}
+
+type PopularTagsResponse struct {
+	Tags  []PopularTag `json:"tags"`
+	Total int          `json:"total"`
+}
+
+type ListCandidatesResponse struct {
+	Candidates []TagCandidate `json:"candidates"`
+	Total      int            `json:"total"`
+}
+
+type ListTagsResponse struct {
+	Tags  []*Tag `json:"tags"`
+	Total int    `json:"total"`
+}
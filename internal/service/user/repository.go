@@ -8,11 +8,19 @@ import (
 	"fmt"
 
 	"bmatch/pkg/db"
+	"bmatch/pkg/geo"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
-	ErrUserExists   = errors.New("user already exists")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrUserExists      = errors.New("user already exists")
+	ErrInvalidTags     = errors.New("tags must contain at least one non-empty tag")
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	ErrInvalidLocation = errors.New("invalid location")
+	ErrInvalidTimeZone = errors.New("invalid time zone")
+	ErrInvalidLanguage = errors.New("invalid language")
+	ErrBlockSelf       = errors.New("cannot block yourself")
+	ErrNotBlocked      = errors.New("user is not blocked")
 )
 
 type Repository interface {
@@ -20,6 +28,10 @@ type Repository interface {
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	UpdateUser(ctx context.Context, user *User) error
 	UpdateUserStats(ctx context.Context, userID string, stats Stats) error
+
+	// Block operations
+	BlockUser(ctx context.Context, blockerID, blockedID string) error
+	UnblockUser(ctx context.Context, blockerID, blockedID string) error
 }
 
 type repository struct {
@@ -35,13 +47,15 @@ func NewRepository(database db.SQLExecutor) Repository {
 // GetUserByID retrieves a user by ID
 func (r *repository) GetUserByID(ctx context.Context, userID string) (*User, error) {
 	query := `
-		SELECT id, email, full_name, tags, skill_level, availability, intent, stats, created_at, updated_at
+		SELECT id, email, full_name, tags, skill_level, availability, schedule, intent, open_to_invites, auto_match, buddy_mode,
+		       latitude, longitude, city, time_zone, languages, stats, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 
 	var user User
-	var tagsJSON, availabilityJSON, statsJSON []byte
+	var tagsJSON, availabilityJSON, scheduleJSON, languagesJSON, statsJSON []byte
+	var lat, lon sql.NullFloat64
 
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
 		&user.ID,
@@ -50,7 +64,16 @@ func (r *repository) GetUserByID(ctx context.Context, userID string) (*User, err
 		&tagsJSON,
 		&user.SkillLevel,
 		&availabilityJSON,
+		&scheduleJSON,
 		&user.Intent,
+		&user.OpenToInvites,
+		&user.AutoMatch,
+		&user.BuddyMode,
+		&lat,
+		&lon,
+		&user.City,
+		&user.TimeZone,
+		&languagesJSON,
 		&statsJSON,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -71,23 +94,35 @@ func (r *repository) GetUserByID(ctx context.Context, userID string) (*User, err
 		return nil, fmt.Errorf("unmarshal availability: %w", err)
 	}
 
+	if err := json.Unmarshal(scheduleJSON, &user.Schedule); err != nil {
+		return nil, fmt.Errorf("unmarshal schedule: %w", err)
+	}
+
+	if err := json.Unmarshal(languagesJSON, &user.Languages); err != nil {
+		return nil, fmt.Errorf("unmarshal languages: %w", err)
+	}
+
 	if err := json.Unmarshal(statsJSON, &user.Stats); err != nil {
 		return nil, fmt.Errorf("unmarshal stats: %w", err)
 	}
 
+	user.Location = scanPoint(lat, lon)
+
 	return &user, nil
 }
 
 // GetUserByEmail retrieves a user by email
 func (r *repository) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	query := `
-		SELECT id, email, full_name, tags, skill_level, availability, intent, stats, created_at, updated_at
+		SELECT id, email, full_name, tags, skill_level, availability, schedule, intent, open_to_invites, auto_match, buddy_mode,
+		       latitude, longitude, city, time_zone, languages, stats, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
 
 	var user User
-	var tagsJSON, availabilityJSON, statsJSON []byte
+	var tagsJSON, availabilityJSON, scheduleJSON, languagesJSON, statsJSON []byte
+	var lat, lon sql.NullFloat64
 
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
@@ -96,7 +131,16 @@ func (r *repository) GetUserByEmail(ctx context.Context, email string) (*User, e
 		&tagsJSON,
 		&user.SkillLevel,
 		&availabilityJSON,
+		&scheduleJSON,
 		&user.Intent,
+		&user.OpenToInvites,
+		&user.AutoMatch,
+		&user.BuddyMode,
+		&lat,
+		&lon,
+		&user.City,
+		&user.TimeZone,
+		&languagesJSON,
 		&statsJSON,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -117,10 +161,20 @@ func (r *repository) GetUserByEmail(ctx context.Context, email string) (*User, e
 		return nil, fmt.Errorf("unmarshal availability: %w", err)
 	}
 
+	if err := json.Unmarshal(scheduleJSON, &user.Schedule); err != nil {
+		return nil, fmt.Errorf("unmarshal schedule: %w", err)
+	}
+
+	if err := json.Unmarshal(languagesJSON, &user.Languages); err != nil {
+		return nil, fmt.Errorf("unmarshal languages: %w", err)
+	}
+
 	if err := json.Unmarshal(statsJSON, &user.Stats); err != nil {
 		return nil, fmt.Errorf("unmarshal stats: %w", err)
 	}
 
+	user.Location = scanPoint(lat, lon)
+
 	return &user, nil
 }
 
@@ -136,9 +190,23 @@ func (r *repository) UpdateUser(ctx context.Context, user *User) error {
 		return fmt.Errorf("marshal availability: %w", err)
 	}
 
+	scheduleJSON, err := json.Marshal(user.Schedule)
+	if err != nil {
+		return fmt.Errorf("marshal schedule: %w", err)
+	}
+
+	languagesJSON, err := json.Marshal(user.Languages)
+	if err != nil {
+		return fmt.Errorf("marshal languages: %w", err)
+	}
+
+	lat, lon := pointArgs(user.Location)
+
 	query := `
 		UPDATE users
-		SET full_name = $2, tags = $3, skill_level = $4, availability = $5, intent = $6
+		SET full_name = $2, tags = $3, skill_level = $4, availability = $5, intent = $6,
+		    open_to_invites = $7, auto_match = $8, buddy_mode = $9, schedule = $10,
+		    latitude = $11, longitude = $12, city = $13, time_zone = $14, languages = $15, updated_at = NOW()
 		WHERE id = $1
 	`
 
@@ -149,6 +217,15 @@ func (r *repository) UpdateUser(ctx context.Context, user *User) error {
 		user.SkillLevel,
 		availabilityJSON,
 		user.Intent,
+		user.OpenToInvites,
+		user.AutoMatch,
+		user.BuddyMode,
+		scheduleJSON,
+		lat,
+		lon,
+		user.City,
+		user.TimeZone,
+		languagesJSON,
 	)
 
 	if err != nil {
@@ -192,3 +269,55 @@ func (r *repository) UpdateUserStats(ctx context.Context, userID string, stats S
 
 	return nil
 }
+
+// BlockUser records that blocker no longer wants to be matched with blocked
+func (r *repository) BlockUser(ctx context.Context, blockerID, blockedID string) error {
+	query := `
+		INSERT INTO user_blocks (blocker_id, blocked_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+		return fmt.Errorf("insert block: %w", err)
+	}
+
+	return nil
+}
+
+// UnblockUser removes a block
+func (r *repository) UnblockUser(ctx context.Context, blockerID, blockedID string) error {
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("delete block: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrNotBlocked
+	}
+
+	return nil
+}
+
+// scanPoint builds a location from nullable coordinate columns
+func scanPoint(lat, lon sql.NullFloat64) *geo.Point {
+	if !lat.Valid || !lon.Valid {
+		return nil
+	}
+	return &geo.Point{Lat: lat.Float64, Lon: lon.Float64}
+}
+
+// pointArgs returns nullable coordinate arguments for a location
+func pointArgs(p *geo.Point) (sql.NullFloat64, sql.NullFloat64) {
+	if p == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: p.Lat, Valid: true}, sql.NullFloat64{Float64: p.Lon, Valid: true}
+}
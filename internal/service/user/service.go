@@ -3,18 +3,32 @@ package user
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"bmatch/pkg/availability"
+	"bmatch/pkg/language"
 	"bmatch/pkg/logger"
 )
 
+// TagNormalizer resolves free-form tags to their canonical form
+type TagNormalizer interface {
+	Normalize(ctx context.Context, tags []string) ([]string, error)
+}
+
 type Service struct {
 	repo   Repository
+	tags   TagNormalizer
 	logger logger.Logger
+
+	// OnProfileUpdate is called after a user's tags, skill level or schedule
+	// changed, so services aggregating them can recompute
+	OnProfileUpdate func(ctx context.Context, userID string)
 }
 
-func NewService(repo Repository, logger logger.Logger) *Service {
+func NewService(repo Repository, tags TagNormalizer, logger logger.Logger) *Service {
 	return &Service{
 		repo:   repo,
+		tags:   tags,
 		logger: logger,
 	}
 }
@@ -59,7 +73,14 @@ func (s *Service) UpdateUser(ctx context.Context, userID string, req UpdateUserR
 		user.FullName = req.FullName
 	}
 	if len(req.Tags) > 0 {
-		user.Tags = req.Tags
+		tags, err := s.tags.Normalize(ctx, req.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("normalize tags: %w", err)
+		}
+		if len(tags) == 0 {
+			return nil, ErrInvalidTags
+		}
+		user.Tags = tags
 	}
 	if req.SkillLevel != "" {
 		user.SkillLevel = req.SkillLevel
@@ -67,9 +88,52 @@ func (s *Service) UpdateUser(ctx context.Context, userID string, req UpdateUserR
 	if len(req.Availability) > 0 {
 		user.Availability = req.Availability
 	}
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		user.Schedule = *req.Schedule
+		if user.TimeZone == "" && req.TimeZone == nil {
+			user.TimeZone = req.Schedule.TimeZone
+		}
+	} else if len(req.Availability) > 0 {
+		// Older clients still send coarse values; keep their time zone
+		user.Schedule = availability.FromLegacy(req.Availability, user.Schedule.TimeZone)
+	}
+	if req.Location != nil {
+		if err := req.Location.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLocation, err)
+		}
+		user.Location = req.Location
+	}
+	if req.City != nil {
+		user.City = strings.TrimSpace(*req.City)
+	}
+	if req.TimeZone != nil {
+		if err := availability.ValidateTimeZone(*req.TimeZone); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTimeZone, err)
+		}
+		user.TimeZone = *req.TimeZone
+	}
+	if req.Languages != nil {
+		languages, err := language.Normalize(req.Languages)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLanguage, err)
+		}
+		user.Languages = languages
+	}
 	if req.Intent != "" {
 		user.Intent = req.Intent
 	}
+	if req.OpenToInvites != nil {
+		user.OpenToInvites = *req.OpenToInvites
+	}
+	if req.AutoMatch != nil {
+		user.AutoMatch = *req.AutoMatch
+	}
+	if req.BuddyMode != nil {
+		user.BuddyMode = *req.BuddyMode
+	}
 
 	if err := s.repo.UpdateUser(ctx, user); err != nil {
 		s.logger.Error(ctx, "failed to update user",
@@ -80,9 +144,55 @@ func (s *Service) UpdateUser(ctx context.Context, userID string, req UpdateUserR
 	}
 
 	s.logger.Info(ctx, "user updated", logger.Field{Key: "user_id", Value: userID})
+
+	matchingChanged := len(req.Tags) > 0 || req.SkillLevel != "" || req.Schedule != nil || len(req.Availability) > 0
+	if matchingChanged && s.OnProfileUpdate != nil {
+		s.OnProfileUpdate(ctx, userID)
+	}
+
 	return user, nil
 }
 
+// BlockUser prevents two users from being matched with each other
+func (s *Service) BlockUser(ctx context.Context, blockerID, blockedID string) error {
+	if blockerID == blockedID {
+		return ErrBlockSelf
+	}
+
+	if _, err := s.repo.GetUserByID(ctx, blockedID); err != nil {
+		return err
+	}
+
+	if err := s.repo.BlockUser(ctx, blockerID, blockedID); err != nil {
+		s.logger.Error(ctx, "failed to block user",
+			logger.Field{Key: "user_id", Value: blockerID},
+			logger.Field{Key: "blocked_id", Value: blockedID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return err
+	}
+
+	s.logger.Info(ctx, "user blocked",
+		logger.Field{Key: "user_id", Value: blockerID},
+		logger.Field{Key: "blocked_id", Value: blockedID},
+	)
+	return nil
+}
+
+// UnblockUser lifts a block
+func (s *Service) UnblockUser(ctx context.Context, blockerID, blockedID string) error {
+	if err := s.repo.UnblockUser(ctx, blockerID, blockedID); err != nil {
+		s.logger.Error(ctx, "failed to unblock user",
+			logger.Field{Key: "user_id", Value: blockerID},
+			logger.Field{Key: "blocked_id", Value: blockedID},
+			logger.Field{Key: "error", Value: err},
+		)
+		return err
+	}
+
+	return nil
+}
+
 // IncrementGroupsJoined increments the groups joined counter
 func (s *Service) IncrementGroupsJoined(ctx context.Context, userID string) error {
 	user, err := s.repo.GetUserByID(ctx, userID)
@@ -1,19 +1,33 @@
 package user
 
-import "time"
+import (
+	"time"
+
+	"bmatch/pkg/availability"
+	"bmatch/pkg/geo"
+	"bmatch/pkg/language"
+)
 
 // Domain Models
 type User struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	FullName     string    `json:"full_name"`
-	Tags         []string  `json:"tags"`
-	SkillLevel   string    `json:"skill_level"`
-	Availability []string  `json:"availability"`
-	Intent       string    `json:"intent"`
-	Stats        Stats     `json:"stats"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            string                `json:"id"`
+	Email         string                `json:"email"`
+	FullName      string                `json:"full_name"`
+	Tags          []string              `json:"tags"`
+	SkillLevel    string                `json:"skill_level"`
+	Availability  []string              `json:"availability"` // legacy coarse values, superseded by Schedule
+	Schedule      availability.Schedule `json:"schedule"`
+	Location      *geo.Point            `json:"location,omitempty"`
+	City          string                `json:"city,omitempty"`
+	TimeZone      string                `json:"time_zone,omitempty"` // IANA name, e.g. "Europe/Berlin"
+	Languages     []language.Spoken     `json:"languages"`
+	Intent        string                `json:"intent"`
+	OpenToInvites bool                  `json:"open_to_invites"`
+	AutoMatch     bool                  `json:"auto_match"`
+	BuddyMode     bool                  `json:"buddy_mode"`
+	Stats         Stats                 `json:"stats"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
 }
 
 type Stats struct {
@@ -33,11 +47,19 @@ type CreateUserRequest struct {
 }
 
 type UpdateUserRequest struct {
-	FullName     string   `json:"full_name" binding:"omitempty,min=2,max=255"`
-	Tags         []string `json:"tags" binding:"omitempty,min=1,max=10"`
-	SkillLevel   string   `json:"skill_level" binding:"omitempty,oneof=BEGINNER INTERMEDIATE ADVANCED"`
-	Availability []string `json:"availability" binding:"omitempty,min=1"`
-	Intent       string   `json:"intent" binding:"omitempty,oneof=CASUAL SERIOUS"`
+	FullName      string                 `json:"full_name" binding:"omitempty,min=2,max=255"`
+	Tags          []string               `json:"tags" binding:"omitempty,min=1,max=10"`
+	SkillLevel    string                 `json:"skill_level" binding:"omitempty,oneof=BEGINNER INTERMEDIATE ADVANCED"`
+	Availability  []string               `json:"availability" binding:"omitempty,min=1"` // legacy, converted to a schedule when none is given
+	Schedule      *availability.Schedule `json:"schedule"`
+	Location      *geo.Point             `json:"location"`
+	City          *string                `json:"city" binding:"omitempty,max=100"`
+	TimeZone      *string                `json:"time_zone" binding:"omitempty,max=64"`
+	Languages     []language.Spoken      `json:"languages" binding:"omitempty,max=10"`
+	Intent        string                 `json:"intent" binding:"omitempty,oneof=CASUAL SERIOUS"`
+	OpenToInvites *bool                  `json:"open_to_invites"` // opt in to group owner recommendations
+	AutoMatch     *bool                  `json:"auto_match"`      // opt in to auto-formed groups
+	BuddyMode     *bool                  `json:"buddy_mode"`      // opt in to one-to-one buddy suggestions
 }
 
 type UserProfileResponse struct {
@@ -0,0 +1,362 @@
+// Package availability models recurring weekly time slots in a time zone and
+// computes how much two schedules overlap.
+package availability
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // zone data for images without /usr/share/zoneinfo
+)
+
+// Days of the week as stored in slots
+const (
+	Monday    = "MON"
+	Tuesday   = "TUE"
+	Wednesday = "WED"
+	Thursday  = "THU"
+	Friday    = "FRI"
+	Saturday  = "SAT"
+	Sunday    = "SUN"
+)
+
+// Legacy coarse availability values
+const (
+	LegacyWeekdays = "WEEKDAYS"
+	LegacyWeekends = "WEEKENDS"
+	LegacyEvenings = "EVENINGS"
+	LegacyMornings = "MORNINGS"
+)
+
+const minutesPerWeek = 7 * 24 * 60
+
+var days = []string{Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday}
+
+var (
+	ErrInvalidDay      = errors.New("invalid day")
+	ErrInvalidTime     = errors.New("invalid time")
+	ErrInvalidTimeZone = errors.New("invalid time zone")
+	ErrEmptySlot       = errors.New("slot start and end are equal")
+)
+
+// Slot is a recurring weekly window in the schedule's time zone. Times are
+// "HH:MM"; an end at or before the start runs past midnight into the next
+// day, and "24:00" ends at midnight.
+type Slot struct {
+	Day   string `json:"day"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Schedule is a set of weekly slots in an IANA time zone
+type Schedule struct {
+	TimeZone string `json:"time_zone"`
+	Slots    []Slot `json:"slots"`
+}
+
+// IsZero reports whether the schedule has no slots
+func (s Schedule) IsZero() bool {
+	return len(s.Slots) == 0
+}
+
+// Validate checks the time zone and every slot
+func (s Schedule) Validate() error {
+	if _, err := location(s.TimeZone); err != nil {
+		return err
+	}
+	for _, slot := range s.Slots {
+		if _, _, _, err := slot.parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Duration is the total weekly time covered by the schedule, counting
+// overlapping slots once
+func (s Schedule) Duration(ref time.Time) time.Duration {
+	total := 0
+	for _, iv := range s.intervals(ref) {
+		total += iv.end - iv.start
+	}
+	return time.Duration(total) * time.Minute
+}
+
+// Overlap is the weekly time both schedules cover. Slots are placed in the
+// week containing ref so daylight saving offsets in effect then are used.
+// Invalid schedules overlap with nothing.
+func Overlap(a, b Schedule, ref time.Time) time.Duration {
+	total := 0
+	for _, iv := range intersect(a.intervals(ref), b.intervals(ref)) {
+		total += iv.end - iv.start
+	}
+	return time.Duration(total) * time.Minute
+}
+
+// Intersect is the weekly time covered by every schedule, as a UTC schedule
+// placed in the week containing ref. No schedules intersect to an empty one.
+func Intersect(ref time.Time, schedules ...Schedule) Schedule {
+	shared := Schedule{TimeZone: "UTC", Slots: []Slot{}}
+	if len(schedules) == 0 {
+		return shared
+	}
+
+	ivs := schedules[0].intervals(ref)
+	for _, s := range schedules[1:] {
+		ivs = intersect(ivs, s.intervals(ref))
+	}
+
+	// Split intervals at midnight UTC into one slot per day
+	for _, iv := range ivs {
+		for start := iv.start; start < iv.end; {
+			day := start / (24 * 60)
+			end := min(iv.end, (day+1)*24*60)
+			shared.Slots = append(shared.Slots, Slot{
+				Day:   days[day],
+				Start: formatClock(start - day*24*60),
+				End:   formatClock(end - day*24*60),
+			})
+			start = end
+		}
+	}
+
+	return shared
+}
+
+// Coverage is the share of target's weekly time that s also covers, in
+// [0, 1]. It answers "how much of the group's meeting time can I attend".
+func Coverage(s, target Schedule, ref time.Time) float64 {
+	total := target.Duration(ref)
+	if total == 0 {
+		return 0
+	}
+	return float64(Overlap(s, target, ref)) / float64(total)
+}
+
+// Similarity is the overlap relative to the shorter schedule, in [0, 1]
+func Similarity(a, b Schedule, ref time.Time) float64 {
+	shorter := min(a.Duration(ref), b.Duration(ref))
+	if shorter == 0 {
+		return 0
+	}
+	return float64(Overlap(a, b, ref)) / float64(shorter)
+}
+
+// legacySlots maps coarse availability values to slots in local time. The
+// same mapping backfills schedules in the weekly availability migration.
+var legacySlots = map[string][]Slot{
+	LegacyWeekdays: everyDay([]string{Monday, Tuesday, Wednesday, Thursday, Friday}, "09:00", "17:00"),
+	LegacyWeekends: everyDay([]string{Saturday, Sunday}, "09:00", "21:00"),
+	LegacyEvenings: everyDay(days, "18:00", "22:00"),
+	LegacyMornings: everyDay(days, "07:00", "10:00"),
+}
+
+// FromLegacy converts coarse values like "WEEKENDS" into a schedule in the
+// given time zone, ignoring unknown values
+func FromLegacy(values []string, timeZone string) Schedule {
+	s := Schedule{TimeZone: timeZone, Slots: []Slot{}}
+	for _, v := range values {
+		s.Slots = append(s.Slots, legacySlots[strings.ToUpper(strings.TrimSpace(v))]...)
+	}
+	return s
+}
+
+func everyDay(names []string, start, end string) []Slot {
+	slots := make([]Slot, len(names))
+	for i, d := range names {
+		slots[i] = Slot{Day: d, Start: start, End: end}
+	}
+	return slots
+}
+
+// interval is a half-open range of minutes from Monday 00:00 UTC
+type interval struct {
+	start, end int
+}
+
+// intervals places the slots in the week containing ref, converts them to
+// UTC minutes of the week and merges them. Slots crossing the end of the
+// week wrap around to Monday.
+func (s Schedule) intervals(ref time.Time) []interval {
+	loc, err := location(s.TimeZone)
+	if err != nil {
+		return nil
+	}
+
+	local := ref.In(loc)
+	monday := time.Date(local.Year(), local.Month(), local.Day()-(int(local.Weekday())+6)%7, 0, 0, 0, 0, loc)
+
+	ivs := make([]interval, 0, len(s.Slots))
+	for _, slot := range s.Slots {
+		day, start, end, err := slot.parse()
+		if err != nil {
+			return nil
+		}
+		if end <= start {
+			end += 24 * 60
+		}
+
+		from := time.Date(monday.Year(), monday.Month(), monday.Day()+day, 0, start, 0, 0, loc)
+		to := time.Date(monday.Year(), monday.Month(), monday.Day()+day, 0, end, 0, 0, loc)
+
+		// The Unix epoch was a Thursday, three days after a Monday
+		offset := int((from.Unix()/60 + 3*24*60) % minutesPerWeek)
+		length := int(to.Sub(from).Minutes())
+
+		if offset+length > minutesPerWeek {
+			ivs = append(ivs, interval{offset, minutesPerWeek}, interval{0, offset + length - minutesPerWeek})
+		} else {
+			ivs = append(ivs, interval{offset, offset + length})
+		}
+	}
+
+	return merge(ivs)
+}
+
+// intersect returns the ranges covered by both sorted, merged interval lists
+func intersect(x, y []interval) []interval {
+	ivs := make([]interval, 0)
+	for i, j := 0, 0; i < len(x) && j < len(y); {
+		start := max(x[i].start, y[j].start)
+		end := min(x[i].end, y[j].end)
+		if end > start {
+			ivs = append(ivs, interval{start, end})
+		}
+		if x[i].end < y[j].end {
+			i++
+		} else {
+			j++
+		}
+	}
+	return ivs
+}
+
+func merge(ivs []interval) []interval {
+	if len(ivs) == 0 {
+		return ivs
+	}
+
+	sort.Slice(ivs, func(i, j int) bool {
+		return ivs[i].start < ivs[j].start
+	})
+
+	merged := ivs[:1]
+	for _, iv := range ivs[1:] {
+		last := &merged[len(merged)-1]
+		if iv.start <= last.end {
+			last.end = max(last.end, iv.end)
+			continue
+		}
+		merged = append(merged, iv)
+	}
+
+	return merged
+}
+
+// parse returns the day index from Monday and start and end in minutes
+func (s Slot) parse() (day, start, end int, err error) {
+	day = -1
+	for i, d := range days {
+		if strings.EqualFold(s.Day, d) {
+			day = i
+			break
+		}
+	}
+	if day < 0 {
+		return 0, 0, 0, fmt.Errorf("%w: %q", ErrInvalidDay, s.Day)
+	}
+
+	if start, err = parseClock(s.Start); err != nil {
+		return 0, 0, 0, err
+	}
+	if start == 24*60 {
+		return 0, 0, 0, fmt.Errorf("%w: %q", ErrInvalidTime, s.Start)
+	}
+	if end, err = parseClock(s.End); err != nil {
+		return 0, 0, 0, err
+	}
+	if start == end {
+		return 0, 0, 0, ErrEmptySlot
+	}
+
+	return day, start, end, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight, allowing "24:00"
+func parseClock(v string) (int, error) {
+	h, m, ok := strings.Cut(v, ":")
+	if !ok || len(h) != 2 || len(m) != 2 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTime, v)
+	}
+
+	hours, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTime, v)
+	}
+	minutes, err := strconv.Atoi(m)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTime, v)
+	}
+
+	if hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTime, v)
+	}
+
+	return hours*60 + minutes, nil
+}
+
+// formatClock formats minutes after midnight as "HH:MM", with "24:00" for midnight at the end of a day
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// ValidateTimeZone checks that name is a known IANA time zone; empty is UTC
+func ValidateTimeZone(name string) error {
+	_, err := location(name)
+	return err
+}
+
+// OffsetDifference is the absolute difference between the UTC offsets of two
+// time zones at the given instant
+func OffsetDifference(a, b string, at time.Time) (time.Duration, error) {
+	locA, err := location(a)
+	if err != nil {
+		return 0, err
+	}
+	locB, err := location(b)
+	if err != nil {
+		return 0, err
+	}
+
+	_, offsetA := at.In(locA).Zone()
+	_, offsetB := at.In(locB).Zone()
+
+	diff := time.Duration(offsetA-offsetB) * time.Second
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff, nil
+}
+
+var locations sync.Map // time zone name -> *time.Location
+
+// location loads a time zone once; an empty name is UTC
+func location(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, name)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
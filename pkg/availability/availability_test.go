@@ -0,0 +1,195 @@
+package availability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A Wednesday in January, outside European and US daylight saving
+var winter = time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+
+// A Wednesday in July, during European and US daylight saving
+var summer = time.Date(2025, time.July, 16, 12, 0, 0, 0, time.UTC)
+
+func TestValidate(t *testing.T) {
+	t.Run("accepts valid schedule", func(t *testing.T) {
+		s := Schedule{TimeZone: "Europe/Berlin", Slots: []Slot{
+			{Day: Monday, Start: "18:00", End: "21:30"},
+			{Day: Friday, Start: "22:00", End: "02:00"},
+			{Day: Sunday, Start: "20:00", End: "24:00"},
+		}}
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("rejects unknown time zone", func(t *testing.T) {
+		s := Schedule{TimeZone: "Mars/Olympus"}
+		assert.ErrorIs(t, s.Validate(), ErrInvalidTimeZone)
+	})
+
+	t.Run("rejects bad slots", func(t *testing.T) {
+		cases := map[string]struct {
+			slot Slot
+			err  error
+		}{
+			"day":        {Slot{Day: "FUNDAY", Start: "10:00", End: "11:00"}, ErrInvalidDay},
+			"format":     {Slot{Day: Monday, Start: "9:00", End: "11:00"}, ErrInvalidTime},
+			"minutes":    {Slot{Day: Monday, Start: "09:60", End: "11:00"}, ErrInvalidTime},
+			"hours":      {Slot{Day: Monday, Start: "10:00", End: "25:00"}, ErrInvalidTime},
+			"start 24":   {Slot{Day: Monday, Start: "24:00", End: "01:00"}, ErrInvalidTime},
+			"empty slot": {Slot{Day: Monday, Start: "10:00", End: "10:00"}, ErrEmptySlot},
+		}
+		for name, tc := range cases {
+			s := Schedule{TimeZone: "UTC", Slots: []Slot{tc.slot}}
+			assert.ErrorIs(t, s.Validate(), tc.err, name)
+		}
+	})
+}
+
+func TestDuration(t *testing.T) {
+	t.Run("counts overlapping slots once", func(t *testing.T) {
+		s := Schedule{TimeZone: "UTC", Slots: []Slot{
+			{Day: Monday, Start: "10:00", End: "12:00"},
+			{Day: Monday, Start: "11:00", End: "13:00"},
+		}}
+		assert.Equal(t, 3*time.Hour, s.Duration(winter))
+	})
+
+	t.Run("overnight slot", func(t *testing.T) {
+		s := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Sunday, Start: "22:00", End: "02:00"}}}
+		assert.Equal(t, 4*time.Hour, s.Duration(winter))
+	})
+
+	t.Run("invalid schedule is empty", func(t *testing.T) {
+		s := Schedule{TimeZone: "Nowhere/City", Slots: []Slot{{Day: Monday, Start: "10:00", End: "12:00"}}}
+		assert.Zero(t, s.Duration(winter))
+	})
+}
+
+func TestOverlap(t *testing.T) {
+	t.Run("same time zone", func(t *testing.T) {
+		a := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Tuesday, Start: "18:00", End: "22:00"}}}
+		b := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Tuesday, Start: "20:00", End: "23:00"}}}
+		assert.Equal(t, 2*time.Hour, Overlap(a, b, winter))
+		assert.Equal(t, Overlap(a, b, winter), Overlap(b, a, winter))
+	})
+
+	t.Run("across time zones", func(t *testing.T) {
+		// 19:00-21:00 in Berlin is 18:00-20:00 UTC in winter
+		berlin := Schedule{TimeZone: "Europe/Berlin", Slots: []Slot{{Day: Tuesday, Start: "19:00", End: "21:00"}}}
+		utc := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Tuesday, Start: "19:00", End: "21:00"}}}
+		assert.Equal(t, time.Hour, Overlap(berlin, utc, winter))
+	})
+
+	t.Run("crosses day boundary", func(t *testing.T) {
+		// Monday 20:00-22:00 in New York is Tuesday 10:00-12:00 in Tokyo (winter)
+		ny := Schedule{TimeZone: "America/New_York", Slots: []Slot{{Day: Monday, Start: "20:00", End: "22:00"}}}
+		tokyo := Schedule{TimeZone: "Asia/Tokyo", Slots: []Slot{{Day: Tuesday, Start: "09:00", End: "11:00"}}}
+		assert.Equal(t, time.Hour, Overlap(ny, tokyo, winter))
+	})
+
+	t.Run("wraps around the week", func(t *testing.T) {
+		// Sunday 23:00 - Monday 02:00 UTC against Monday 00:00-01:00 UTC
+		a := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Sunday, Start: "23:00", End: "02:00"}}}
+		b := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Monday, Start: "00:00", End: "01:00"}}}
+		assert.Equal(t, time.Hour, Overlap(a, b, winter))
+	})
+
+	t.Run("follows daylight saving", func(t *testing.T) {
+		// London moves to UTC+1 in summer, shifting the slot an hour earlier in UTC
+		london := Schedule{TimeZone: "Europe/London", Slots: []Slot{{Day: Wednesday, Start: "10:00", End: "12:00"}}}
+		utc := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Wednesday, Start: "11:00", End: "13:00"}}}
+		assert.Equal(t, time.Hour, Overlap(london, utc, winter))
+		assert.Zero(t, Overlap(london, utc, summer))
+	})
+
+	t.Run("no overlap", func(t *testing.T) {
+		a := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Monday, Start: "10:00", End: "12:00"}}}
+		b := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Tuesday, Start: "10:00", End: "12:00"}}}
+		assert.Zero(t, Overlap(a, b, winter))
+		assert.Zero(t, Overlap(a, Schedule{}, winter))
+	})
+}
+
+func TestCoverageAndSimilarity(t *testing.T) {
+	user := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Monday, Start: "18:00", End: "22:00"}}}
+	group := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Monday, Start: "20:00", End: "21:00"}}}
+
+	assert.InDelta(t, 1.0, Coverage(user, group, winter), 1e-9)
+	assert.InDelta(t, 0.25, Coverage(group, user, winter), 1e-9)
+	assert.InDelta(t, 1.0, Similarity(user, group, winter), 1e-9)
+	assert.Zero(t, Coverage(user, Schedule{}, winter))
+	assert.Zero(t, Similarity(user, Schedule{}, winter))
+}
+
+func TestIntersect(t *testing.T) {
+	t.Run("time covered by everyone in UTC", func(t *testing.T) {
+		// 19:00-22:00 in Berlin is 18:00-21:00 UTC in winter
+		berlin := Schedule{TimeZone: "Europe/Berlin", Slots: []Slot{{Day: Tuesday, Start: "19:00", End: "22:00"}}}
+		london := Schedule{TimeZone: "Europe/London", Slots: []Slot{{Day: Tuesday, Start: "17:00", End: "20:00"}}}
+		utc := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Tuesday, Start: "19:00", End: "23:00"}}}
+
+		shared := Intersect(winter, berlin, london, utc)
+		assert.Equal(t, "UTC", shared.TimeZone)
+		assert.Equal(t, []Slot{{Day: Tuesday, Start: "19:00", End: "20:00"}}, shared.Slots)
+		assert.Equal(t, time.Hour, Overlap(shared, berlin, winter))
+	})
+
+	t.Run("splits at midnight", func(t *testing.T) {
+		a := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Sunday, Start: "22:00", End: "03:00"}}}
+		b := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Sunday, Start: "23:00", End: "02:00"}}}
+		assert.Equal(t, []Slot{
+			{Day: Monday, Start: "00:00", End: "02:00"},
+			{Day: Sunday, Start: "23:00", End: "24:00"},
+		}, Intersect(winter, a, b).Slots)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		a := Schedule{TimeZone: "UTC", Slots: []Slot{{Day: Monday, Start: "10:00", End: "12:00"}}}
+		assert.True(t, Intersect(winter).IsZero())
+		assert.True(t, Intersect(winter, a, Schedule{}).IsZero())
+		assert.NoError(t, Intersect(winter, a).Validate())
+	})
+}
+
+func TestFromLegacy(t *testing.T) {
+	t.Run("expands known values", func(t *testing.T) {
+		s := FromLegacy([]string{"WEEKENDS", "evenings"}, "Europe/Paris")
+		assert.Equal(t, "Europe/Paris", s.TimeZone)
+		assert.Len(t, s.Slots, 2+7)
+		assert.NoError(t, s.Validate())
+		assert.Contains(t, s.Slots, Slot{Day: Saturday, Start: "09:00", End: "21:00"})
+	})
+
+	t.Run("ignores unknown values", func(t *testing.T) {
+		s := FromLegacy([]string{"SOMETIMES"}, "UTC")
+		assert.True(t, s.IsZero())
+		assert.NotNil(t, s.Slots)
+	})
+}
+
+func TestOffsetDifference(t *testing.T) {
+	t.Run("follows daylight saving", func(t *testing.T) {
+		// New York is UTC-5 in winter and UTC-4 in summer; Tokyo stays UTC+9
+		diff, err := OffsetDifference("America/New_York", "Asia/Tokyo", winter)
+		assert.NoError(t, err)
+		assert.Equal(t, 14*time.Hour, diff)
+
+		diff, err = OffsetDifference("Asia/Tokyo", "America/New_York", summer)
+		assert.NoError(t, err)
+		assert.Equal(t, 13*time.Hour, diff)
+	})
+
+	t.Run("half hour zones", func(t *testing.T) {
+		diff, err := OffsetDifference("Asia/Kolkata", "", winter)
+		assert.NoError(t, err)
+		assert.Equal(t, 5*time.Hour+30*time.Minute, diff)
+	})
+
+	t.Run("unknown zone", func(t *testing.T) {
+		_, err := OffsetDifference("UTC", "Atlantis/Capital", winter)
+		assert.ErrorIs(t, err, ErrInvalidTimeZone)
+		assert.ErrorIs(t, ValidateTimeZone("Atlantis/Capital"), ErrInvalidTimeZone)
+	})
+}
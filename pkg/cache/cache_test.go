@@ -0,0 +1,562 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend gives tests direct access to a cache's storage and clock. It
+// matches the methods of miniredis.Miniredis used by the tests.
+type backend interface {
+	Set(k, v string) error
+	Get(k string) (string, error)
+	Exists(k string) bool
+	TTL(k string) time.Duration
+	SetTTL(k string, ttl time.Duration)
+	FastForward(d time.Duration)
+	Close()
+}
+
+// forEachCache runs fn against every Cache implementation so they keep the
+// same semantics
+func forEachCache(t *testing.T, fn func(t *testing.T, store backend, cache Cache)) {
+	t.Run("redis", func(t *testing.T) {
+		mr, cache := setupTestRedis(t)
+		defer mr.Close()
+
+		fn(t, mr, cache)
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		store, cache := setupTestMemory(t, 0)
+		defer store.Close()
+
+		fn(t, store, cache)
+	})
+}
+
+func TestCache_Set(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("successful set", func(t *testing.T) {
+			err := cache.Set(ctx, "test-key", "test-value", time.Minute)
+			assert.NoError(t, err)
+
+			// Verify the value was stored
+			val, err := store.Get("test-key")
+			assert.NoError(t, err)
+			assert.Equal(t, "test-value", val)
+		})
+
+		t.Run("set with zero TTL", func(t *testing.T) {
+			err := cache.Set(ctx, "no-ttl-key", "no-ttl-value", 0)
+			assert.NoError(t, err)
+
+			val, err := store.Get("no-ttl-key")
+			assert.NoError(t, err)
+			assert.Equal(t, "no-ttl-value", val)
+		})
+
+		t.Run("set overwrites existing key", func(t *testing.T) {
+			err := cache.Set(ctx, "overwrite-key", "original", time.Minute)
+			assert.NoError(t, err)
+
+			err = cache.Set(ctx, "overwrite-key", "updated", time.Minute)
+			assert.NoError(t, err)
+
+			val, err := store.Get("overwrite-key")
+			assert.NoError(t, err)
+			assert.Equal(t, "updated", val)
+		})
+
+		t.Run("set with TTL expires", func(t *testing.T) {
+			err := cache.Set(ctx, "expire-key", "expire-value", 1*time.Second)
+			assert.NoError(t, err)
+
+			// Fast forward past the TTL
+			store.FastForward(2 * time.Second)
+
+			exists := store.Exists("expire-key")
+			assert.False(t, exists)
+		})
+	})
+}
+
+func TestCache_Get(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("successful get", func(t *testing.T) {
+			store.Set("existing-key", "existing-value")
+
+			val, err := cache.Get(ctx, "existing-key")
+			assert.NoError(t, err)
+			assert.Equal(t, "existing-value", val)
+		})
+
+		t.Run("get non-existent key", func(t *testing.T) {
+			val, err := cache.Get(ctx, "non-existent-key")
+			assert.Error(t, err)
+			assert.ErrorIs(t, err, ErrCacheMiss)
+			assert.Empty(t, val)
+		})
+
+		t.Run("get expired key", func(t *testing.T) {
+			store.Set("expired-key", "expired-value")
+			store.SetTTL("expired-key", 1*time.Second)
+			store.FastForward(2 * time.Second)
+
+			val, err := cache.Get(ctx, "expired-key")
+			assert.Error(t, err)
+			assert.ErrorIs(t, err, ErrCacheMiss)
+			assert.Empty(t, val)
+		})
+	})
+}
+
+func TestCache_Del(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("delete existing key", func(t *testing.T) {
+			store.Set("delete-key", "delete-value")
+
+			err := cache.Del(ctx, "delete-key")
+			assert.NoError(t, err)
+
+			exists := store.Exists("delete-key")
+			assert.False(t, exists)
+		})
+
+		t.Run("delete non-existent key", func(t *testing.T) {
+			err := cache.Del(ctx, "non-existent-delete-key")
+			assert.NoError(t, err) // Redis Del doesn't error on non-existent keys
+		})
+
+		t.Run("delete multiple times", func(t *testing.T) {
+			store.Set("multi-delete-key", "value")
+
+			err := cache.Del(ctx, "multi-delete-key")
+			assert.NoError(t, err)
+
+			// Delete again
+			err = cache.Del(ctx, "multi-delete-key")
+			assert.NoError(t, err)
+		})
+	})
+}
+
+func TestCache_DelIfEqual(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("deletes matching value", func(t *testing.T) {
+			store.Set("owned", "token")
+
+			deleted, err := cache.DelIfEqual(ctx, "owned", "token")
+			require.NoError(t, err)
+			assert.True(t, deleted)
+			assert.False(t, store.Exists("owned"))
+		})
+
+		t.Run("keeps other value", func(t *testing.T) {
+			store.Set("taken", "other-token")
+
+			deleted, err := cache.DelIfEqual(ctx, "taken", "token")
+			require.NoError(t, err)
+			assert.False(t, deleted)
+
+			val, err := store.Get("taken")
+			require.NoError(t, err)
+			assert.Equal(t, "other-token", val)
+		})
+
+		t.Run("missing key", func(t *testing.T) {
+			deleted, err := cache.DelIfEqual(ctx, "missing", "token")
+			require.NoError(t, err)
+			assert.False(t, deleted)
+		})
+	})
+}
+
+func TestCache_ExpireIfEqual(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("expires matching value", func(t *testing.T) {
+			store.Set("owned", "token")
+
+			expired, err := cache.ExpireIfEqual(ctx, "owned", "token", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, expired)
+			assert.Equal(t, time.Minute, store.TTL("owned"))
+		})
+
+		t.Run("keeps other value", func(t *testing.T) {
+			store.Set("taken", "other-token")
+
+			expired, err := cache.ExpireIfEqual(ctx, "taken", "token", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, expired)
+			assert.Zero(t, store.TTL("taken"))
+		})
+
+		t.Run("missing key", func(t *testing.T) {
+			expired, err := cache.ExpireIfEqual(ctx, "missing", "token", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, expired)
+		})
+	})
+}
+
+func TestCache_SetNX(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("setnx on non-existent key", func(t *testing.T) {
+			ok, err := cache.SetNX(ctx, "setnx-key", "setnx-value", time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			val, err := store.Get("setnx-key")
+			assert.NoError(t, err)
+			assert.Equal(t, "setnx-value", val)
+		})
+
+		t.Run("setnx on existing key", func(t *testing.T) {
+			store.Set("existing-setnx-key", "original-value")
+
+			ok, err := cache.SetNX(ctx, "existing-setnx-key", "new-value", time.Minute)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			// Verify original value is unchanged
+			val, err := store.Get("existing-setnx-key")
+			assert.NoError(t, err)
+			assert.Equal(t, "original-value", val)
+		})
+
+		t.Run("setnx with TTL", func(t *testing.T) {
+			ok, err := cache.SetNX(ctx, "setnx-ttl-key", "setnx-ttl-value", 1*time.Second)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			// Verify TTL is set
+			ttl := store.TTL("setnx-ttl-key")
+			assert.True(t, ttl > 0)
+
+			// Fast forward and verify expiration
+			store.FastForward(2 * time.Second)
+			exists := store.Exists("setnx-ttl-key")
+			assert.False(t, exists)
+		})
+
+		t.Run("setnx after key expires", func(t *testing.T) {
+			ok, err := cache.SetNX(ctx, "expire-then-setnx", "first-value", 1*time.Second)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			// Fast forward to expire the key
+			store.FastForward(2 * time.Second)
+
+			// Now SetNX should succeed
+			ok, err = cache.SetNX(ctx, "expire-then-setnx", "second-value", time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			val, err := store.Get("expire-then-setnx")
+			assert.NoError(t, err)
+			assert.Equal(t, "second-value", val)
+		})
+	})
+}
+
+func TestCache_ContextCancellation(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		t.Run("set with cancelled context", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel() // Cancel immediately
+
+			err := cache.Set(ctx, "cancel-key", "cancel-value", time.Minute)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "context canceled")
+		})
+
+		t.Run("get with cancelled context", func(t *testing.T) {
+			store.Set("cancel-get-key", "value")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := cache.Get(ctx, "cancel-get-key")
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "context canceled")
+		})
+	})
+}
+
+func TestCache_IntegrationScenario(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		// Scenario: Distributed lock pattern
+		t.Run("distributed lock pattern", func(t *testing.T) {
+			lockKey := "resource-lock"
+			lockValue := "process-1"
+
+			// Acquire lock
+			acquired, err := cache.SetNX(ctx, lockKey, lockValue, 5*time.Second)
+			assert.NoError(t, err)
+			assert.True(t, acquired)
+
+			// Try to acquire again (should fail)
+			acquired, err = cache.SetNX(ctx, lockKey, "process-2", 5*time.Second)
+			assert.NoError(t, err)
+			assert.False(t, acquired)
+
+			// Release lock
+			err = cache.Del(ctx, lockKey)
+			assert.NoError(t, err)
+
+			// Now acquire should succeed
+			acquired, err = cache.SetNX(ctx, lockKey, "process-2", 5*time.Second)
+			assert.NoError(t, err)
+			assert.True(t, acquired)
+		})
+
+		// Scenario: Cache with refresh
+		t.Run("cache refresh pattern", func(t *testing.T) {
+			cacheKey := "user:123"
+
+			// Set initial value
+			err := cache.Set(ctx, cacheKey, "user-data-v1", time.Minute)
+			assert.NoError(t, err)
+
+			// Get value
+			val, err := cache.Get(ctx, cacheKey)
+			assert.NoError(t, err)
+			assert.Equal(t, "user-data-v1", val)
+
+			// Update cache
+			err = cache.Set(ctx, cacheKey, "user-data-v2", time.Minute)
+			assert.NoError(t, err)
+
+			// Get updated value
+			val, err = cache.Get(ctx, cacheKey)
+			assert.NoError(t, err)
+			assert.Equal(t, "user-data-v2", val)
+
+			// Delete cache
+			err = cache.Del(ctx, cacheKey)
+			assert.NoError(t, err)
+
+			// Verify deletion
+			_, err = cache.Get(ctx, cacheKey)
+			assert.Error(t, err)
+			assert.ErrorIs(t, err, ErrCacheMiss)
+		})
+	})
+}
+
+func TestCache_SortedSet(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		require.NoError(t, cache.ZAdd(ctx, "queue", "carol", 30))
+		require.NoError(t, cache.ZAdd(ctx, "queue", "alice", 10))
+		require.NoError(t, cache.ZAdd(ctx, "queue", "bob", 20))
+
+		t.Run("range is ordered by score", func(t *testing.T) {
+			members, err := cache.ZRangeByScore(ctx, "queue", math.Inf(-1), math.Inf(1))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"alice", "bob", "carol"}, members)
+		})
+
+		t.Run("range is bounded by score", func(t *testing.T) {
+			members, err := cache.ZRangeByScore(ctx, "queue", 15, 30)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"bob", "carol"}, members)
+		})
+
+		t.Run("zadd updates score of existing member", func(t *testing.T) {
+			require.NoError(t, cache.ZAdd(ctx, "queue", "alice", 40))
+
+			rank, err := cache.ZRank(ctx, "queue", "alice")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), rank)
+		})
+
+		t.Run("rank of missing member", func(t *testing.T) {
+			_, err := cache.ZRank(ctx, "queue", "dave")
+			assert.ErrorIs(t, err, ErrCacheMiss)
+		})
+
+		t.Run("remove members", func(t *testing.T) {
+			require.NoError(t, cache.ZRem(ctx, "queue", "alice", "bob"))
+
+			members, err := cache.ZRangeByScore(ctx, "queue", math.Inf(-1), math.Inf(1))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"carol"}, members)
+		})
+
+		t.Run("range of missing key is empty", func(t *testing.T) {
+			members, err := cache.ZRangeByScore(ctx, "missing", math.Inf(-1), math.Inf(1))
+			assert.NoError(t, err)
+			assert.Empty(t, members)
+		})
+	})
+}
+
+func TestCache_DelMany(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		store.Set("a", "1")
+		store.Set("b", "2")
+		store.Set("c", "3")
+
+		require.NoError(t, cache.Del(ctx, "a", "b", "missing"))
+		assert.False(t, store.Exists("a"))
+		assert.False(t, store.Exists("b"))
+		assert.True(t, store.Exists("c"))
+
+		assert.NoError(t, cache.Del(ctx), "no keys is a no-op")
+	})
+}
+
+func TestCache_MGetMSet(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("mset stores every value with the ttl", func(t *testing.T) {
+			err := cache.MSet(ctx, map[string]string{"k1": "v1", "k2": "v2"}, time.Minute)
+			require.NoError(t, err)
+
+			assert.Equal(t, time.Minute, store.TTL("k1"))
+			assert.Equal(t, time.Minute, store.TTL("k2"))
+		})
+
+		t.Run("mget leaves out missing keys", func(t *testing.T) {
+			values, err := cache.MGet(ctx, "k1", "missing", "k2")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2"}, values)
+		})
+
+		t.Run("mget ignores keys of other types", func(t *testing.T) {
+			require.NoError(t, cache.ZAdd(ctx, "zset", "m", 1))
+
+			values, err := cache.MGet(ctx, "k1", "zset")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"k1": "v1"}, values)
+		})
+
+		t.Run("empty batches", func(t *testing.T) {
+			values, err := cache.MGet(ctx)
+			assert.NoError(t, err)
+			assert.Empty(t, values)
+
+			assert.NoError(t, cache.MSet(ctx, nil, time.Minute))
+		})
+	})
+}
+
+func TestCache_IncrExpireExists(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("incr starts at one", func(t *testing.T) {
+			n, err := cache.Incr(ctx, "counter")
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), n)
+
+			n, err = cache.Incr(ctx, "counter")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+
+			val, err := cache.Get(ctx, "counter")
+			require.NoError(t, err)
+			assert.Equal(t, "2", val)
+		})
+
+		t.Run("incr of a non-integer fails", func(t *testing.T) {
+			store.Set("text", "abc")
+
+			_, err := cache.Incr(ctx, "text")
+			assert.Error(t, err)
+		})
+
+		t.Run("expire existing key", func(t *testing.T) {
+			ok, err := cache.Expire(ctx, "counter", time.Second)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			store.FastForward(2 * time.Second)
+			_, err = cache.Get(ctx, "counter")
+			assert.ErrorIs(t, err, ErrCacheMiss)
+		})
+
+		t.Run("expire missing key", func(t *testing.T) {
+			ok, err := cache.Expire(ctx, "missing", time.Second)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+
+		t.Run("exists counts existing keys", func(t *testing.T) {
+			store.Set("e1", "1")
+			store.Set("e2", "2")
+
+			n, err := cache.Exists(ctx, "e1", "e2", "missing")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+
+			n, err = cache.Exists(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, n)
+		})
+	})
+}
+
+func TestCache_Pipelined(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		store.Set("old", "1")
+
+		err := cache.Pipelined(ctx, func(p Pipe) {
+			p.Set("key", "value", time.Minute)
+			p.Incr("counter")
+			p.Incr("counter")
+			p.Expire("counter", time.Minute)
+			p.Del("old")
+			p.ZAdd("queue", "alice", 1)
+			p.ZAdd("queue", "bob", 2)
+			p.ZRem("queue", "alice")
+		})
+		require.NoError(t, err)
+
+		val, err := cache.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", val)
+
+		val, err = cache.Get(ctx, "counter")
+		require.NoError(t, err)
+		assert.Equal(t, "2", val)
+		assert.Equal(t, time.Minute, store.TTL("counter"))
+
+		assert.False(t, store.Exists("old"))
+
+		members, err := cache.ZRangeByScore(ctx, "queue", math.Inf(-1), math.Inf(1))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bob"}, members)
+
+		t.Run("empty pipeline", func(t *testing.T) {
+			assert.NoError(t, cache.Pipelined(ctx, func(p Pipe) {}))
+		})
+	})
+}
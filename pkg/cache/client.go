@@ -2,12 +2,49 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrCacheMiss is returned when a key, or a sorted set member, does not exist
+var ErrCacheMiss = errors.New("cache: miss")
+
 type Cache interface {
 	Set(ctx context.Context, key string, value string, ttl time.Duration) error
 	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
-	Del(ctx context.Context, key string) error
+	Del(ctx context.Context, keys ...string) error
+	// DelIfEqual deletes key only while it still holds value, atomically
+	DelIfEqual(ctx context.Context, key, value string) (bool, error)
+	// ExpireIfEqual sets the TTL of key only while it still holds value, atomically
+	ExpireIfEqual(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+
+	// Batches; MGet leaves missing keys out of the result
+	MGet(ctx context.Context, keys ...string) (map[string]string, error)
+	MSet(ctx context.Context, values map[string]string, ttl time.Duration) error
+
+	// Counters and expiry
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Exists(ctx context.Context, keys ...string) (int64, error)
+
+	// Pipelined sends the commands queued by fn in one round trip. They are
+	// not atomic: other clients' commands may run in between.
+	Pipelined(ctx context.Context, fn func(p Pipe)) error
+
+	// Sorted sets
+	ZAdd(ctx context.Context, key string, member string, score float64) error
+	ZRem(ctx context.Context, key string, members ...string) error
+	ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error)
+	ZRank(ctx context.Context, key string, member string) (int64, error)
+}
+
+// Pipe queues write commands for Cache.Pipelined
+type Pipe interface {
+	Set(key string, value string, ttl time.Duration)
+	Del(keys ...string)
+	Incr(key string)
+	Expire(key string, ttl time.Duration)
+	ZAdd(key string, member string, score float64)
+	ZRem(key string, members ...string)
 }
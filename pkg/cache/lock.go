@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lock is a lease on a key held by one owner at a time. The key holds a
+// random token, so releasing a lease that expired and was taken by another
+// owner leaves their lease alone.
+type Lock struct {
+	cache Cache
+	key   string
+	token string
+}
+
+// TryLock takes the lease on key for ttl. It returns nil without an error
+// when another owner holds it.
+func TryLock(ctx context.Context, c Cache, key string, ttl time.Duration) (*Lock, error) {
+	token := uuid.NewString()
+
+	acquired, err := c.SetNX(ctx, key, token, ttl)
+	if err != nil || !acquired {
+		return nil, err
+	}
+
+	return &Lock{cache: c, key: key, token: token}, nil
+}
+
+// Extend resets the lease to ttl. It reports false when the lease already
+// expired, and another owner may hold it.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) (bool, error) {
+	return l.cache.ExpireIfEqual(ctx, l.key, l.token, ttl)
+}
+
+// Release gives the lease up unless it already expired. It runs even when
+// ctx is canceled, so a lease taken for a canceled request is not held for
+// its full TTL.
+func (l *Lock) Release(ctx context.Context) error {
+	_, err := l.cache.DelIfEqual(context.WithoutCancel(ctx), l.key, l.token)
+	return err
+}
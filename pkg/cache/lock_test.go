@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryLock(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("one owner at a time", func(t *testing.T) {
+			lock, err := TryLock(ctx, cache, "lock:one", time.Minute)
+			require.NoError(t, err)
+			require.NotNil(t, lock)
+
+			other, err := TryLock(ctx, cache, "lock:one", time.Minute)
+			require.NoError(t, err)
+			assert.Nil(t, other)
+
+			require.NoError(t, lock.Release(ctx))
+			assert.False(t, store.Exists("lock:one"))
+
+			again, err := TryLock(ctx, cache, "lock:one", time.Minute)
+			require.NoError(t, err)
+			assert.NotNil(t, again)
+		})
+
+		t.Run("expired lease does not release the next owner", func(t *testing.T) {
+			stale, err := TryLock(ctx, cache, "lock:expired", time.Second)
+			require.NoError(t, err)
+			require.NotNil(t, stale)
+
+			store.FastForward(2 * time.Second)
+
+			current, err := TryLock(ctx, cache, "lock:expired", time.Minute)
+			require.NoError(t, err)
+			require.NotNil(t, current)
+
+			require.NoError(t, stale.Release(ctx))
+			assert.True(t, store.Exists("lock:expired"))
+
+			other, err := TryLock(ctx, cache, "lock:expired", time.Minute)
+			require.NoError(t, err)
+			assert.Nil(t, other)
+		})
+
+		t.Run("release after cancel", func(t *testing.T) {
+			cancelCtx, cancel := context.WithCancel(ctx)
+			lock, err := TryLock(cancelCtx, cache, "lock:cancel", time.Minute)
+			require.NoError(t, err)
+			require.NotNil(t, lock)
+
+			cancel()
+			require.NoError(t, lock.Release(cancelCtx))
+			assert.False(t, store.Exists("lock:cancel"))
+		})
+
+		t.Run("extend keeps the lease", func(t *testing.T) {
+			lock, err := TryLock(ctx, cache, "lock:extend", time.Second)
+			require.NoError(t, err)
+			require.NotNil(t, lock)
+
+			extended, err := lock.Extend(ctx, time.Minute)
+			require.NoError(t, err)
+			assert.True(t, extended)
+			assert.Equal(t, time.Minute, store.TTL("lock:extend"))
+		})
+
+		t.Run("extend after expiry", func(t *testing.T) {
+			stale, err := TryLock(ctx, cache, "lock:extend-expired", time.Second)
+			require.NoError(t, err)
+			require.NotNil(t, stale)
+
+			store.FastForward(2 * time.Second)
+			current, err := TryLock(ctx, cache, "lock:extend-expired", 10*time.Second)
+			require.NoError(t, err)
+			require.NotNil(t, current)
+
+			extended, err := stale.Extend(ctx, time.Minute)
+			require.NoError(t, err)
+			assert.False(t, extended)
+			assert.Equal(t, 10*time.Second, store.TTL("lock:extend-expired"))
+		})
+	})
+}
@@ -0,0 +1,546 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	errWrongType  = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInteger = errors.New("ERR value is not an integer or out of range")
+)
+
+// MemoryCache is an in-process Cache for tests and single-instance runs.
+// It holds at most maxItems keys, evicting the least recently used one, and
+// drops expired keys when they are accessed and on every cleanup. Locks
+// taken with SetNX only exclude callers in the same process.
+type MemoryCache struct {
+	mu       sync.Mutex
+	maxItems int
+	items    map[string]*list.Element
+	lru      *list.List // of *memoryEntry, most recently used first
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	zset      map[string]float64 // set for sorted sets
+	expiresAt time.Time          // zero when the key does not expire
+}
+
+// NewMemoryCache returns a MemoryCache holding at most maxItems keys;
+// maxItems <= 0 leaves it unbounded
+func NewMemoryCache(maxItems int) *MemoryCache {
+	return &MemoryCache{
+		maxItems: maxItems,
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+		now:      time.Now,
+	}
+}
+
+// StartCleanup drops expired keys every interval until ctx is done, so keys
+// that are never read again do not hold memory until they are evicted
+func (m *MemoryCache) StartCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.removeExpired()
+			}
+		}
+	}()
+}
+
+// Len is the number of keys held, including expired keys not yet dropped
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
+
+func (m *MemoryCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, value, ttl)
+	return nil
+}
+
+func (m *MemoryCache) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.lookup(key) != nil {
+		return false, nil
+	}
+	m.set(key, value, ttl)
+	return true, nil
+}
+
+// Get returns the value of key, or ErrCacheMiss
+func (m *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.lookup(key)
+	if e == nil {
+		return "", ErrCacheMiss
+	}
+	if e.zset != nil {
+		return "", errWrongType
+	}
+	return e.value, nil
+}
+
+func (m *MemoryCache) Del(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.del(keys...)
+	return nil
+}
+
+// DelIfEqual deletes key only while it still holds value
+func (m *MemoryCache) DelIfEqual(ctx context.Context, key, value string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.lookup(key)
+	if e == nil || e.zset != nil || e.value != value {
+		return false, nil
+	}
+	m.del(key)
+	return true, nil
+}
+
+func (m *MemoryCache) ExpireIfEqual(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.lookup(key)
+	if e == nil || e.zset != nil || e.value != value {
+		return false, nil
+	}
+	return m.expire(key, ttl), nil
+}
+
+func (m *MemoryCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if e := m.lookup(key); e != nil && e.zset == nil {
+			values[key] = e.value
+		}
+	}
+	return values, nil
+}
+
+func (m *MemoryCache) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, value := range values {
+		m.set(key, value, ttl)
+	}
+	return nil
+}
+
+func (m *MemoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.incr(key)
+}
+
+// Expire sets the TTL of key and reports whether the key exists
+func (m *MemoryCache) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.expire(key, ttl), nil
+}
+
+// Exists counts how many of keys exist
+func (m *MemoryCache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for _, key := range keys {
+		if m.lookup(key) != nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Pipelined runs the queued commands under one lock, so unlike with Redis
+// no other command runs in between. Like Redis, a failing command does not
+// stop the others; the first error is returned.
+func (m *MemoryCache) Pipelined(ctx context.Context, fn func(p Pipe)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	pipe := &memoryPipe{}
+	fn(pipe)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var first error
+	for _, cmd := range pipe.cmds {
+		if err := cmd(m); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (m *MemoryCache) ZAdd(ctx context.Context, key, member string, score float64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.zadd(key, member, score)
+}
+
+func (m *MemoryCache) ZRem(ctx context.Context, key string, members ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.zrem(key, members...)
+}
+
+// ZRangeByScore returns members with min <= score <= max in ascending order;
+// pass math.Inf to leave a side unbounded
+func (m *MemoryCache) ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.lookup(key)
+	if e == nil {
+		return []string{}, nil
+	}
+	if e.zset == nil {
+		return nil, errWrongType
+	}
+
+	members := []string{}
+	for _, member := range sortedMembers(e.zset) {
+		if score := e.zset[member]; score >= min && score <= max {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// ZRank returns the 0-based ascending position of member, or ErrCacheMiss
+func (m *MemoryCache) ZRank(ctx context.Context, key, member string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.lookup(key)
+	if e == nil {
+		return 0, ErrCacheMiss
+	}
+	if e.zset == nil {
+		return 0, errWrongType
+	}
+	if _, ok := e.zset[member]; !ok {
+		return 0, ErrCacheMiss
+	}
+
+	var rank int64
+	for _, other := range sortedMembers(e.zset) {
+		if other == member {
+			break
+		}
+		rank++
+	}
+	return rank, nil
+}
+
+// lookup returns the live entry of key and marks it recently used. Expired
+// entries are dropped. Callers hold mu.
+func (m *MemoryCache) lookup(key string) *memoryEntry {
+	el, ok := m.items[key]
+	if !ok {
+		return nil
+	}
+
+	e := el.Value.(*memoryEntry)
+	if m.expired(e) {
+		m.remove(el)
+		return nil
+	}
+
+	m.lru.MoveToFront(el)
+	return e
+}
+
+// set stores a string value, replacing any previous value and TTL, and
+// evicts the least recently used keys beyond maxItems
+func (m *MemoryCache) set(key, value string, ttl time.Duration) {
+	e := m.entry(key)
+	e.value = value
+	e.zset = nil
+	e.expiresAt = m.expiry(ttl)
+}
+
+// entry returns the live entry of key, adding an empty one if there is none
+func (m *MemoryCache) entry(key string) *memoryEntry {
+	if e := m.lookup(key); e != nil {
+		return e
+	}
+
+	e := &memoryEntry{key: key}
+	m.items[key] = m.lru.PushFront(e)
+
+	for m.maxItems > 0 && m.lru.Len() > m.maxItems {
+		m.remove(m.lru.Back())
+	}
+	return e
+}
+
+func (m *MemoryCache) del(keys ...string) {
+	for _, key := range keys {
+		if el, ok := m.items[key]; ok {
+			m.remove(el)
+		}
+	}
+}
+
+func (m *MemoryCache) incr(key string) (int64, error) {
+	e := m.lookup(key)
+	if e == nil {
+		m.set(key, "1", 0)
+		return 1, nil
+	}
+	if e.zset != nil {
+		return 0, errWrongType
+	}
+
+	n, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil || n == math.MaxInt64 {
+		return 0, errNotInteger
+	}
+
+	// Like Redis, the TTL is kept
+	n++
+	e.value = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// expire sets the TTL of key; a TTL <= 0 deletes it, like Redis
+func (m *MemoryCache) expire(key string, ttl time.Duration) bool {
+	el, ok := m.items[key]
+	if !ok || m.expired(el.Value.(*memoryEntry)) {
+		return false
+	}
+
+	if ttl <= 0 {
+		m.remove(el)
+		return true
+	}
+	el.Value.(*memoryEntry).expiresAt = m.now().Add(ttl)
+	return true
+}
+
+func (m *MemoryCache) zadd(key, member string, score float64) error {
+	if e := m.lookup(key); e != nil && e.zset == nil {
+		return errWrongType
+	}
+
+	e := m.entry(key)
+	if e.zset == nil {
+		e.zset = make(map[string]float64)
+	}
+	e.zset[member] = score
+	return nil
+}
+
+// zrem removes members; like Redis, an emptied sorted set is deleted
+func (m *MemoryCache) zrem(key string, members ...string) error {
+	e := m.lookup(key)
+	if e == nil {
+		return nil
+	}
+	if e.zset == nil {
+		return errWrongType
+	}
+
+	for _, member := range members {
+		delete(e.zset, member)
+	}
+	if len(e.zset) == 0 {
+		m.del(key)
+	}
+	return nil
+}
+
+func (m *MemoryCache) remove(el *list.Element) {
+	m.lru.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
+
+func (m *MemoryCache) removeExpired() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, el := range m.items {
+		if m.expired(el.Value.(*memoryEntry)) {
+			m.remove(el)
+		}
+	}
+}
+
+func (m *MemoryCache) expired(e *memoryEntry) bool {
+	return !e.expiresAt.IsZero() && !m.now().Before(e.expiresAt)
+}
+
+func (m *MemoryCache) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(ttl)
+}
+
+// sortedMembers orders members by score, then lexicographically like Redis
+func sortedMembers(zset map[string]float64) []string {
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		si, sj := zset[members[i]], zset[members[j]]
+		if si != sj {
+			return si < sj
+		}
+		return members[i] < members[j]
+	})
+	return members
+}
+
+// memoryPipe queues commands for MemoryCache.Pipelined
+type memoryPipe struct {
+	cmds []func(m *MemoryCache) error
+}
+
+func (p *memoryPipe) Set(key, value string, ttl time.Duration) {
+	p.cmds = append(p.cmds, func(m *MemoryCache) error {
+		m.set(key, value, ttl)
+		return nil
+	})
+}
+
+func (p *memoryPipe) Del(keys ...string) {
+	p.cmds = append(p.cmds, func(m *MemoryCache) error {
+		m.del(keys...)
+		return nil
+	})
+}
+
+func (p *memoryPipe) Incr(key string) {
+	p.cmds = append(p.cmds, func(m *MemoryCache) error {
+		_, err := m.incr(key)
+		return err
+	})
+}
+
+func (p *memoryPipe) Expire(key string, ttl time.Duration) {
+	p.cmds = append(p.cmds, func(m *MemoryCache) error {
+		m.expire(key, ttl)
+		return nil
+	})
+}
+
+func (p *memoryPipe) ZAdd(key, member string, score float64) {
+	p.cmds = append(p.cmds, func(m *MemoryCache) error {
+		return m.zadd(key, member, score)
+	})
+}
+
+func (p *memoryPipe) ZRem(key string, members ...string) {
+	p.cmds = append(p.cmds, func(m *MemoryCache) error {
+		return m.zrem(key, members...)
+	})
+}
@@ -0,0 +1,197 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore exposes a MemoryCache the way miniredis exposes Redis, with a
+// fake clock that only moves on FastForward
+type memoryStore struct {
+	cache *MemoryCache
+	mu    sync.Mutex
+	now   time.Time
+}
+
+// setupTestMemory creates a MemoryCache running on a fake clock
+func setupTestMemory(t *testing.T, maxItems int) (*memoryStore, *MemoryCache) {
+	t.Helper()
+
+	cache := NewMemoryCache(maxItems)
+	store := &memoryStore{cache: cache, now: time.Now()}
+	cache.now = store.clock
+
+	return store, cache
+}
+
+func (s *memoryStore) clock() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+func (s *memoryStore) Set(k, v string) error {
+	return s.cache.Set(context.Background(), k, v, 0)
+}
+
+func (s *memoryStore) Get(k string) (string, error) {
+	return s.cache.Get(context.Background(), k)
+}
+
+func (s *memoryStore) Exists(k string) bool {
+	n, _ := s.cache.Exists(context.Background(), k)
+	return n == 1
+}
+
+// TTL returns 0 for keys that are missing or do not expire, like miniredis
+func (s *memoryStore) TTL(k string) time.Duration {
+	s.cache.mu.Lock()
+	defer s.cache.mu.Unlock()
+
+	el, ok := s.cache.items[k]
+	if !ok {
+		return 0
+	}
+	expiresAt := el.Value.(*memoryEntry).expiresAt
+	if expiresAt.IsZero() {
+		return 0
+	}
+	return expiresAt.Sub(s.clock())
+}
+
+func (s *memoryStore) SetTTL(k string, ttl time.Duration) {
+	_, _ = s.cache.Expire(context.Background(), k, ttl)
+}
+
+func (s *memoryStore) FastForward(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+func (s *memoryStore) Close() {}
+
+func TestMemoryCache_Eviction(t *testing.T) {
+	store, cache := setupTestMemory(t, 3)
+	ctx := context.Background()
+
+	t.Run("evicts least recently used key", func(t *testing.T) {
+		require.NoError(t, cache.Set(ctx, "a", "1", 0))
+		require.NoError(t, cache.Set(ctx, "b", "2", 0))
+		require.NoError(t, cache.Set(ctx, "c", "3", 0))
+
+		// Reading a makes b the least recently used
+		_, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+
+		require.NoError(t, cache.Set(ctx, "d", "4", 0))
+
+		assert.Equal(t, 3, cache.Len())
+		assert.True(t, store.Exists("a"))
+		assert.False(t, store.Exists("b"))
+		assert.True(t, store.Exists("c"))
+		assert.True(t, store.Exists("d"))
+	})
+
+	t.Run("sorted sets count as one key", func(t *testing.T) {
+		require.NoError(t, cache.Del(ctx, "a", "c", "d"))
+
+		for i := range 10 {
+			require.NoError(t, cache.ZAdd(ctx, "zset", fmt.Sprint(i), float64(i)))
+		}
+
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("failed SetNX does not evict", func(t *testing.T) {
+		require.NoError(t, cache.Set(ctx, "x", "1", 0))
+		require.NoError(t, cache.Set(ctx, "y", "1", 0))
+
+		ok, err := cache.SetNX(ctx, "x", "2", 0)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.Equal(t, 3, cache.Len())
+		assert.True(t, store.Exists("zset"))
+	})
+}
+
+func TestMemoryCache_StartCleanup(t *testing.T) {
+	store, cache := setupTestMemory(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, cache.Set(ctx, "short", "1", time.Second))
+	require.NoError(t, cache.Set(ctx, "long", "1", time.Hour))
+	require.NoError(t, cache.Set(ctx, "forever", "1", 0))
+
+	store.FastForward(2 * time.Second)
+	cache.StartCleanup(ctx, 10*time.Millisecond)
+
+	// Expired keys are dropped without being read
+	assert.Eventually(t, func() bool {
+		return cache.Len() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.True(t, store.Exists("long"))
+	assert.True(t, store.Exists("forever"))
+}
+
+func TestMemoryCache_SetNXConcurrent(t *testing.T) {
+	cache := NewMemoryCache(0)
+	ctx := context.Background()
+
+	var (
+		wg   sync.WaitGroup
+		wins atomic.Int32
+	)
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := cache.SetNX(ctx, "lock", fmt.Sprint(i), time.Minute)
+			assert.NoError(t, err)
+			if ok {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), wins.Load())
+}
+
+func TestMemoryCache_WrongType(t *testing.T) {
+	cache := NewMemoryCache(0)
+	ctx := context.Background()
+
+	require.NoError(t, cache.ZAdd(ctx, "zset", "m", 1))
+	require.NoError(t, cache.Set(ctx, "text", "abc", 0))
+
+	_, err := cache.Get(ctx, "zset")
+	assert.ErrorIs(t, err, errWrongType)
+
+	err = cache.ZAdd(ctx, "text", "m", 1)
+	assert.ErrorIs(t, err, errWrongType)
+
+	_, err = cache.Incr(ctx, "zset")
+	assert.ErrorIs(t, err, errWrongType)
+
+	// MGet skips keys that are not strings, like Redis returns nil for them
+	values, err := cache.MGet(ctx, "zset", "text")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"text": "abc"}, values)
+
+	// Set replaces a sorted set
+	require.NoError(t, cache.Set(ctx, "zset", "now a string", 0))
+	val, err := cache.Get(ctx, "zset")
+	require.NoError(t, err)
+	assert.Equal(t, "now a string", val)
+}
@@ -2,11 +2,30 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// delIfEqualScript deletes KEYS[1] when it holds ARGV[1]
+var delIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// expireIfEqualScript sets the TTL of KEYS[1] to ARGV[2] ms when it holds ARGV[1]
+var expireIfEqualScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
 type RedisCache struct {
 	client *redis.Client
 }
@@ -23,14 +42,164 @@ func (r *RedisCache) Set(ctx context.Context, key string, value string, ttl time
 	return r.client.Set(ctx, key, value, ttl).Err()
 }
 
+// Get returns the value of key, or ErrCacheMiss
 func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
-	return r.client.Get(ctx, key).Result()
+	return missing(r.client.Get(ctx, key).Result())
+}
+
+func (r *RedisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// DelIfEqual deletes key only while it still holds value
+func (r *RedisCache) DelIfEqual(ctx context.Context, key, value string) (bool, error) {
+	n, err := delIfEqualScript.Run(ctx, r.client, []string{key}, value).Int64()
+	return n == 1, err
+}
+
+// ExpireIfEqual sets the TTL of key only while it still holds value
+func (r *RedisCache) ExpireIfEqual(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	n, err := expireIfEqualScript.Run(ctx, r.client, []string{key}, value, ttl.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+func (r *RedisCache) MGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	results, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if value, ok := result.(string); ok {
+			values[keys[i]] = value
+		}
+	}
+	return values, nil
+}
+
+// MSet sets every value with the same TTL. Redis MSET takes no TTL, so the
+// values are sent as pipelined SETs.
+func (r *RedisCache) MSet(ctx context.Context, values map[string]string, ttl time.Duration) error {
+	return r.Pipelined(ctx, func(p Pipe) {
+		for key, value := range values {
+			p.Set(key, value, ttl)
+		}
+	})
+}
+
+func (r *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// Expire sets the TTL of key and reports whether the key exists
+func (r *RedisCache) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.client.Expire(ctx, key, ttl).Result()
 }
 
-func (r *RedisCache) Del(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+// Exists counts how many of keys exist
+func (r *RedisCache) Exists(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	return r.client.Exists(ctx, keys...).Result()
+}
+
+func (r *RedisCache) Pipelined(ctx context.Context, fn func(p Pipe)) error {
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		fn(&redisPipe{ctx: ctx, pipe: pipe})
+		return nil
+	})
+	return err
 }
 
 func (r *RedisCache) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
 	return r.client.SetNX(ctx, key, value, ttl).Result()
 }
+
+func (r *RedisCache) ZAdd(ctx context.Context, key, member string, score float64) error {
+	return r.client.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+func (r *RedisCache) ZRem(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return r.client.ZRem(ctx, key, args...).Err()
+}
+
+// ZRangeByScore returns members with min <= score <= max in ascending order;
+// pass math.Inf to leave a side unbounded
+func (r *RedisCache) ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error) {
+	return r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: formatScore(min),
+		Max: formatScore(max),
+	}).Result()
+}
+
+// ZRank returns the 0-based ascending position of member, or ErrCacheMiss
+func (r *RedisCache) ZRank(ctx context.Context, key, member string) (int64, error) {
+	return missing(r.client.ZRank(ctx, key, member).Result())
+}
+
+// missing translates redis.Nil into ErrCacheMiss
+func missing[T any](value T, err error) (T, error) {
+	if errors.Is(err, redis.Nil) {
+		return value, ErrCacheMiss
+	}
+	return value, err
+}
+
+// redisPipe implements Pipe on a go-redis pipeline
+type redisPipe struct {
+	ctx  context.Context
+	pipe redis.Pipeliner
+}
+
+func (p *redisPipe) Set(key, value string, ttl time.Duration) {
+	p.pipe.Set(p.ctx, key, value, ttl)
+}
+
+func (p *redisPipe) Del(keys ...string) {
+	if len(keys) > 0 {
+		p.pipe.Del(p.ctx, keys...)
+	}
+}
+
+func (p *redisPipe) Incr(key string) {
+	p.pipe.Incr(p.ctx, key)
+}
+
+func (p *redisPipe) Expire(key string, ttl time.Duration) {
+	p.pipe.Expire(p.ctx, key, ttl)
+}
+
+func (p *redisPipe) ZAdd(key, member string, score float64) {
+	p.pipe.ZAdd(p.ctx, key, redis.Z{Score: score, Member: member})
+}
+
+func (p *redisPipe) ZRem(key string, members ...string) {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	p.pipe.ZRem(p.ctx, key, args...)
+}
+
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, -1):
+		return "-inf"
+	case math.IsInf(score, 1):
+		return "+inf"
+	default:
+		return strconv.FormatFloat(score, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// GetJSON decodes the JSON value of key. It returns ErrCacheMiss when the
+// key does not exist.
+func GetJSON[T any](ctx context.Context, c Cache, key string) (T, error) {
+	var value T
+
+	data, err := c.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return value, fmt.Errorf("unmarshal %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetJSON stores value as JSON
+func SetJSON[T any](ctx context.Context, c Cache, key string, value T, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", key, err)
+	}
+	return c.Set(ctx, key, string(data), ttl)
+}
+
+// Results of a Loader lookup, as reported to LoaderOptions.OnLookup
+const (
+	LookupHit   = "hit"
+	LookupMiss  = "miss"
+	LookupError = "error"
+)
+
+// LoaderOptions tunes a Loader. The zero value loads each missing key once
+// per instance and takes no fill lock.
+type LoaderOptions struct {
+	// LockTTL, when set, makes the instance loading a missing key hold a
+	// fill lock at lock:<key>. Other instances wait up to LockWait, polling
+	// every LockPoll, for the value to be stored, then load it themselves
+	// without storing it.
+	LockTTL  time.Duration
+	LockWait time.Duration
+	LockPoll time.Duration
+
+	// OnLookup, when set, is called with the result of every lookup
+	OnLookup func(result string)
+
+	// OnError, when set, is called with cache errors the Loader recovers
+	// from by loading
+	OnError func(ctx context.Context, key string, err error)
+}
+
+// Loader fills a cache on misses, running one load per key at a time
+type Loader struct {
+	cache  Cache
+	opts   LoaderOptions
+	flight singleflight.Group
+}
+
+func NewLoader(c Cache, opts LoaderOptions) *Loader {
+	return &Loader{cache: c, opts: opts}
+}
+
+// GetOrLoad returns the cached JSON value of key, or loads, stores and
+// returns it. Concurrent misses of key share one load, which is not
+// cancelled when the caller that started it goes away. Values that do not
+// decode are reloaded. When the cache fails, values are loaded without
+// being stored; load errors are never cached.
+func GetOrLoad[T any](ctx context.Context, l *Loader, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+
+	data, err := l.cache.Get(ctx, key)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal([]byte(data), &value); err == nil {
+			l.lookup(LookupHit)
+			return value, nil
+		}
+		// Values that no longer decode, e.g. of an older type, are reloaded
+		var zero T
+		value = zero
+		l.lookup(LookupMiss)
+	case errors.Is(err, ErrCacheMiss):
+		l.lookup(LookupMiss)
+	default:
+		l.lookup(LookupError)
+		l.error(ctx, key, err)
+		return load(ctx)
+	}
+
+	shared, err, _ := l.flight.Do(key, func() (any, error) {
+		return l.fill(context.WithoutCancel(ctx), key, ttl, func(ctx context.Context) (any, error) {
+			return load(ctx)
+		})
+	})
+	if err != nil {
+		return value, err
+	}
+
+	// Every caller decodes its own copy of the shared load
+	if err := json.Unmarshal(shared.([]byte), &value); err != nil {
+		return value, fmt.Errorf("unmarshal %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// fill loads key and stores it, unless another instance holds its fill
+// lock. A failed store only costs the next caller a load.
+func (l *Loader) fill(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (any, error)) ([]byte, error) {
+	store := true
+	if l.opts.LockTTL > 0 {
+		lock, err := TryLock(ctx, l.cache, "lock:"+key, l.opts.LockTTL)
+		switch {
+		case err != nil:
+			l.error(ctx, key, err)
+			store = false
+		case lock == nil:
+			if data, ok := l.wait(ctx, key); ok {
+				return data, nil
+			}
+			store = false
+		default:
+			defer func() {
+				if err := lock.Release(ctx); err != nil {
+					l.error(ctx, key, err)
+				}
+			}()
+		}
+	}
+
+	loaded, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(loaded)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", key, err)
+	}
+
+	if store {
+		if err := l.cache.Set(ctx, key, string(data), ttl); err != nil {
+			l.error(ctx, key, err)
+		}
+	}
+	return data, nil
+}
+
+// wait polls for key to be stored by the instance holding its fill lock
+func (l *Loader) wait(ctx context.Context, key string) ([]byte, bool) {
+	deadline := time.Now().Add(l.opts.LockWait)
+	for time.Now().Before(deadline) {
+		time.Sleep(l.opts.LockPoll)
+		if data, err := l.cache.Get(ctx, key); err == nil {
+			return []byte(data), true
+		}
+	}
+	return nil, false
+}
+
+func (l *Loader) lookup(result string) {
+	if l.opts.OnLookup != nil {
+		l.opts.OnLookup(result)
+	}
+}
+
+func (l *Loader) error(ctx context.Context, key string, err error) {
+	if l.opts.OnError != nil {
+		l.opts.OnError(ctx, key, err)
+	}
+}
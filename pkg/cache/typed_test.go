@@ -0,0 +1,208 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags"`
+}
+
+func TestGetSetJSON(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+
+		t.Run("round trip", func(t *testing.T) {
+			item := testItem{Name: "go", Count: 3, Tags: []string{"a", "b"}}
+			require.NoError(t, SetJSON(ctx, cache, "item", item, time.Minute))
+
+			got, err := GetJSON[testItem](ctx, cache, "item")
+			require.NoError(t, err)
+			assert.Equal(t, item, got)
+			assert.Equal(t, time.Minute, store.TTL("item"))
+		})
+
+		t.Run("miss", func(t *testing.T) {
+			_, err := GetJSON[testItem](ctx, cache, "missing")
+			assert.ErrorIs(t, err, ErrCacheMiss)
+		})
+
+		t.Run("undecodable value", func(t *testing.T) {
+			store.Set("bad", "not json")
+
+			_, err := GetJSON[testItem](ctx, cache, "bad")
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, ErrCacheMiss)
+		})
+
+		t.Run("unencodable value", func(t *testing.T) {
+			err := SetJSON(ctx, cache, "chan", make(chan int), time.Minute)
+			assert.Error(t, err)
+			assert.False(t, store.Exists("chan"))
+		})
+	})
+}
+
+func TestGetOrLoad(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+		loader := NewLoader(cache, LoaderOptions{})
+
+		t.Run("loads and stores on a miss", func(t *testing.T) {
+			calls := 0
+			load := func(ctx context.Context) (testItem, error) {
+				calls++
+				return testItem{Name: "loaded"}, nil
+			}
+
+			got, err := GetOrLoad(ctx, loader, "load", time.Minute, load)
+			require.NoError(t, err)
+			assert.Equal(t, "loaded", got.Name)
+
+			got, err = GetOrLoad(ctx, loader, "load", time.Minute, load)
+			require.NoError(t, err)
+			assert.Equal(t, "loaded", got.Name)
+			assert.Equal(t, 1, calls, "second call is served from the cache")
+			assert.Equal(t, time.Minute, store.TTL("load"))
+		})
+
+		t.Run("load errors are returned and not cached", func(t *testing.T) {
+			errLoad := errors.New("db down")
+			_, err := GetOrLoad(ctx, loader, "failing", time.Minute, func(ctx context.Context) (testItem, error) {
+				return testItem{}, errLoad
+			})
+			assert.ErrorIs(t, err, errLoad)
+			assert.False(t, store.Exists("failing"))
+		})
+
+		t.Run("undecodable values are reloaded", func(t *testing.T) {
+			store.Set("stale", `{"name": 42}`)
+
+			got, err := GetOrLoad(ctx, loader, "stale", time.Minute, func(ctx context.Context) (testItem, error) {
+				return testItem{Name: "fresh"}, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testItem{Name: "fresh"}, got)
+
+			cached, err := GetJSON[testItem](ctx, cache, "stale")
+			require.NoError(t, err)
+			assert.Equal(t, "fresh", cached.Name)
+		})
+
+		t.Run("concurrent misses share one load", func(t *testing.T) {
+			var calls atomic.Int32
+			release := make(chan struct{})
+			load := func(ctx context.Context) (testItem, error) {
+				calls.Add(1)
+				<-release
+				return testItem{Name: "shared", Tags: []string{"x"}}, nil
+			}
+
+			const callers = 10
+			results := make([]testItem, callers)
+			var wg sync.WaitGroup
+			for i := range callers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got, err := GetOrLoad(ctx, loader, "shared", time.Minute, load)
+					assert.NoError(t, err)
+					results[i] = got
+				}()
+			}
+
+			// Let every caller reach the cache before the load finishes
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, int32(1), calls.Load())
+			for _, got := range results {
+				assert.Equal(t, "shared", got.Name)
+			}
+
+			// Callers get their own copies
+			results[0].Tags[0] = "changed"
+			assert.Equal(t, "x", results[1].Tags[0])
+		})
+	})
+}
+
+func TestGetOrLoad_CacheDown(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	cache := NewRedisCache(mr.Addr())
+	mr.Close()
+
+	loader := NewLoader(cache, LoaderOptions{})
+	got, err := GetOrLoad(context.Background(), loader, "key", time.Minute, func(ctx context.Context) (testItem, error) {
+		return testItem{Name: "from db"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "from db", got.Name)
+}
+
+func TestGetOrLoad_FillLock(t *testing.T) {
+	forEachCache(t, func(t *testing.T, store backend, cache Cache) {
+		ctx := context.Background()
+		results := make(map[string]int)
+		loader := NewLoader(cache, LoaderOptions{
+			LockTTL:  time.Second,
+			LockWait: 200 * time.Millisecond,
+			LockPoll: 10 * time.Millisecond,
+			OnLookup: func(result string) { results[result]++ },
+		})
+
+		t.Run("releases the lock after storing", func(t *testing.T) {
+			got, err := GetOrLoad(ctx, loader, "filled", time.Minute, func(ctx context.Context) (testItem, error) {
+				return testItem{Name: "loaded"}, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "loaded", got.Name)
+			assert.True(t, store.Exists("filled"))
+			assert.False(t, store.Exists("lock:filled"))
+
+			_, err = GetOrLoad(ctx, loader, "filled", time.Minute, func(ctx context.Context) (testItem, error) {
+				return testItem{}, errors.New("not called")
+			})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int{LookupMiss: 1, LookupHit: 1}, results)
+		})
+
+		t.Run("waits for the instance holding the lock", func(t *testing.T) {
+			store.Set("lock:waiting", "other")
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				store.Set("waiting", `{"name":"stored by other"}`)
+			}()
+
+			got, err := GetOrLoad(ctx, loader, "waiting", time.Minute, func(ctx context.Context) (testItem, error) {
+				return testItem{}, errors.New("not called")
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "stored by other", got.Name)
+		})
+
+		t.Run("loads without storing when the holder is slow", func(t *testing.T) {
+			store.Set("lock:slow", "other")
+
+			got, err := GetOrLoad(ctx, loader, "slow", time.Minute, func(ctx context.Context) (testItem, error) {
+				return testItem{Name: "loaded"}, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "loaded", got.Name)
+			assert.False(t, store.Exists("slow"))
+		})
+	})
+}
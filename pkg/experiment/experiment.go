@@ -0,0 +1,231 @@
+// Package experiment defines A/B experiments loaded from a JSON file and
+// assigns users to arms deterministically.
+package experiment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event types recorded for experiments. An impression is the exposure;
+// the others are outcomes.
+const (
+	EventImpression = "IMPRESSION" // group shown in discover results
+	EventView       = "VIEW"       // group page opened
+	EventApply      = "APPLY"
+	EventJoin       = "JOIN"
+)
+
+// buckets is the resolution of arm weights: a weight of 1 is 0.01% of users
+const buckets = 10000
+
+type Experiment struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	Arms    []Arm  `json:"arms"`
+}
+
+// Arm receives a share of users proportional to its weight. Params are
+// opaque to this package and interpreted by whatever the experiment varies.
+type Arm struct {
+	Name   string          `json:"name"`
+	Weight int             `json:"weight"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Assignment is the arm a user sees in an experiment
+type Assignment struct {
+	Experiment string          `json:"experiment"`
+	Arm        string          `json:"arm"`
+	Params     json.RawMessage `json:"params,omitempty"`
+}
+
+// Bucket maps a user to [0, 10000) by hashing the experiment key and user
+// ID, so a user keeps their arm for the life of an experiment while
+// different experiments split users independently
+func Bucket(key, userID string) int {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{':'})
+	h.Write([]byte(userID))
+	return int(h.Sum64() % buckets)
+}
+
+// Assign returns the user's arm; false if the experiment has no weighted arms
+func (e Experiment) Assign(userID string) (Assignment, bool) {
+	total := 0
+	for _, arm := range e.Arms {
+		total += arm.Weight
+	}
+	if total <= 0 {
+		return Assignment{}, false
+	}
+
+	point := Bucket(e.Key, userID) * total / buckets
+	for _, arm := range e.Arms {
+		if point < arm.Weight {
+			return Assignment{Experiment: e.Key, Arm: arm.Name, Params: arm.Params}, true
+		}
+		point -= arm.Weight
+	}
+
+	return Assignment{}, false
+}
+
+// Validate checks that arms are named uniquely with non-negative weights
+func (e Experiment) Validate() error {
+	if e.Key == "" {
+		return errors.New("experiment key is required")
+	}
+	if len(e.Arms) == 0 {
+		return fmt.Errorf("experiment %s: at least one arm is required", e.Key)
+	}
+
+	seen := make(map[string]bool, len(e.Arms))
+	for _, arm := range e.Arms {
+		if arm.Name == "" {
+			return fmt.Errorf("experiment %s: arm name is required", e.Key)
+		}
+		if seen[arm.Name] {
+			return fmt.Errorf("experiment %s: duplicate arm %s", e.Key, arm.Name)
+		}
+		if arm.Weight < 0 {
+			return fmt.Errorf("experiment %s: arm %s has negative weight", e.Key, arm.Name)
+		}
+		seen[arm.Name] = true
+	}
+
+	return nil
+}
+
+// Registry holds the experiments defined in a JSON file of the form
+// {"experiments": [...]}. Reload picks up edits without a restart.
+type Registry struct {
+	path string
+
+	mu          sync.RWMutex
+	experiments []Experiment
+	modTime     time.Time
+}
+
+// NewRegistry loads path; an empty path yields a registry with no experiments
+func NewRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	if path == "" {
+		return r, nil
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Experiments returns a snapshot of all defined experiments
+func (r *Registry) Experiments() []Experiment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Experiment(nil), r.experiments...)
+}
+
+// Get returns the experiment with the given key
+func (r *Registry) Get(key string) (Experiment, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.experiments {
+		if e.Key == key {
+			return e, true
+		}
+	}
+	return Experiment{}, false
+}
+
+// Active returns the first enabled experiment
+func (r *Registry) Active() (Experiment, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.experiments {
+		if e.Enabled {
+			return e, true
+		}
+	}
+	return Experiment{}, false
+}
+
+// Reload re-reads the file if it changed since the last load. An invalid
+// file is rejected and the previous experiments stay in effect.
+func (r *Registry) Reload() error {
+	if r.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("stat experiments file: %w", err)
+	}
+
+	r.mu.RLock()
+	unchanged := info.ModTime().Equal(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("read experiments file: %w", err)
+	}
+
+	var file struct {
+		Experiments []Experiment `json:"experiments"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parse experiments file: %w", err)
+	}
+
+	keys := make(map[string]bool, len(file.Experiments))
+	for _, e := range file.Experiments {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+		if keys[e.Key] {
+			return fmt.Errorf("duplicate experiment %s", e.Key)
+		}
+		keys[e.Key] = true
+	}
+
+	r.mu.Lock()
+	r.experiments = file.Experiments
+	r.modTime = info.ModTime()
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Watch reloads the file every interval until ctx is done
+func (r *Registry) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	if r.path == "" || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Reload(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
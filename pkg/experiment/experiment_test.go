@@ -0,0 +1,146 @@
+package experiment
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket(t *testing.T) {
+	t.Run("deterministic", func(t *testing.T) {
+		assert.Equal(t, Bucket("exp", "user-1"), Bucket("exp", "user-1"))
+	})
+
+	t.Run("in range", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			b := Bucket("exp", fmt.Sprintf("user-%d", i))
+			assert.GreaterOrEqual(t, b, 0)
+			assert.Less(t, b, buckets)
+		}
+	})
+}
+
+func TestExperiment_Assign(t *testing.T) {
+	exp := Experiment{
+		Key: "discover-ranking",
+		Arms: []Arm{
+			{Name: "control", Weight: 50},
+			{Name: "mmr", Weight: 50, Params: []byte(`{"diversity_lambda":0.7}`)},
+		},
+	}
+
+	t.Run("same user keeps their arm", func(t *testing.T) {
+		first, ok := exp.Assign("user-42")
+		require.True(t, ok)
+
+		for i := 0; i < 10; i++ {
+			again, _ := exp.Assign("user-42")
+			assert.Equal(t, first, again)
+		}
+	})
+
+	t.Run("splits users by weight", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			a, ok := exp.Assign(fmt.Sprintf("user-%d", i))
+			require.True(t, ok)
+			counts[a.Arm]++
+		}
+
+		assert.InDelta(t, 5000, counts["control"], 300)
+		assert.InDelta(t, 5000, counts["mmr"], 300)
+	})
+
+	t.Run("carries arm params", func(t *testing.T) {
+		for i := 0; ; i++ {
+			a, _ := exp.Assign(fmt.Sprintf("user-%d", i))
+			if a.Arm == "mmr" {
+				assert.JSONEq(t, `{"diversity_lambda":0.7}`, string(a.Params))
+				assert.Equal(t, "discover-ranking", a.Experiment)
+				return
+			}
+		}
+	})
+
+	t.Run("zero weight arm gets nobody", func(t *testing.T) {
+		e := Experiment{Key: "k", Arms: []Arm{{Name: "a", Weight: 1}, {Name: "b", Weight: 0}}}
+		for i := 0; i < 1000; i++ {
+			a, ok := e.Assign(fmt.Sprintf("user-%d", i))
+			require.True(t, ok)
+			assert.Equal(t, "a", a.Arm)
+		}
+	})
+
+	t.Run("no weighted arms", func(t *testing.T) {
+		_, ok := Experiment{Key: "k", Arms: []Arm{{Name: "a"}}}.Assign("user-1")
+		assert.False(t, ok)
+	})
+}
+
+func TestExperiment_Validate(t *testing.T) {
+	assert.NoError(t, Experiment{Key: "k", Arms: []Arm{{Name: "a", Weight: 1}}}.Validate())
+	assert.Error(t, Experiment{Arms: []Arm{{Name: "a", Weight: 1}}}.Validate())
+	assert.Error(t, Experiment{Key: "k"}.Validate())
+	assert.Error(t, Experiment{Key: "k", Arms: []Arm{{Name: "a"}, {Name: "a"}}}.Validate())
+	assert.Error(t, Experiment{Key: "k", Arms: []Arm{{Name: "a", Weight: -1}}}.Validate())
+}
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "experiments.json")
+	start := time.Now().Add(-time.Hour)
+
+	writeFile(t, path, `{"experiments": [
+		{"key": "old", "enabled": false, "arms": [{"name": "a", "weight": 1}]},
+		{"key": "new", "enabled": true, "arms": [{"name": "a", "weight": 1}]}
+	]}`, start)
+
+	r, err := NewRegistry(path)
+	require.NoError(t, err)
+
+	t.Run("loads experiments", func(t *testing.T) {
+		assert.Len(t, r.Experiments(), 2)
+
+		active, ok := r.Active()
+		require.True(t, ok)
+		assert.Equal(t, "new", active.Key)
+
+		_, ok = r.Get("old")
+		assert.True(t, ok)
+	})
+
+	t.Run("reload picks up edits", func(t *testing.T) {
+		writeFile(t, path, `{"experiments": [
+			{"key": "new", "enabled": false, "arms": [{"name": "a", "weight": 1}]}
+		]}`, start.Add(time.Minute))
+
+		require.NoError(t, r.Reload())
+
+		_, ok := r.Active()
+		assert.False(t, ok)
+		assert.Len(t, r.Experiments(), 1)
+	})
+
+	t.Run("invalid file keeps previous experiments", func(t *testing.T) {
+		writeFile(t, path, `{"experiments": [{"key": "", "arms": []}]}`, start.Add(2*time.Minute))
+
+		assert.Error(t, r.Reload())
+		assert.Len(t, r.Experiments(), 1)
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		empty, err := NewRegistry("")
+		require.NoError(t, err)
+		assert.Empty(t, empty.Experiments())
+	})
+}
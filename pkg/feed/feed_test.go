@@ -0,0 +1,145 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	return &Feed{
+		ID:      "https://example.com/feeds/groups",
+		Title:   "New groups",
+		HomeURL: "https://example.com",
+		FeedURL: "https://example.com/feeds/groups.atom",
+		Items: []Item{
+			{
+				ID:        "urn:uuid:1",
+				URL:       "https://example.com/groups/1",
+				Title:     "Go & Rust <study>",
+				Content:   "Weekly sessions",
+				Tags:      []string{"golang", "rust"},
+				Published: published,
+			},
+			{
+				ID:        "urn:uuid:2",
+				Title:     "Climbing",
+				Published: published.Add(-time.Hour),
+				Updated:   published.Add(time.Hour),
+			},
+		},
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	f := testFeed()
+	assert.Equal(t, f.Items[1].Updated, f.LastModified(), "newest item update wins")
+
+	f.Updated = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, f.Updated, f.LastModified())
+
+	assert.True(t, (&Feed{}).LastModified().IsZero())
+}
+
+func TestAtom(t *testing.T) {
+	body, err := testFeed().Atom()
+	require.NoError(t, err)
+
+	var doc atomFeed
+	require.NoError(t, xml.Unmarshal(body, &doc))
+
+	assert.Equal(t, "New groups", doc.Title)
+	assert.Equal(t, "2025-03-01T11:00:00Z", doc.Updated)
+	require.Len(t, doc.Entries, 2)
+	assert.Equal(t, "Go & Rust <study>", doc.Entries[0].Title, "special characters survive escaping")
+	assert.Equal(t, "2025-03-01T10:00:00Z", doc.Entries[0].Updated, "updated defaults to published")
+	assert.Equal(t, []atomCategory{{Term: "golang"}, {Term: "rust"}}, doc.Entries[0].Categories)
+	assert.Nil(t, doc.Entries[1].Content)
+	assert.Contains(t, string(body), `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, string(body), `rel="self"`)
+}
+
+func TestAtomEmpty(t *testing.T) {
+	body, err := (&Feed{ID: "urn:feed", Title: "Empty"}).Atom()
+	require.NoError(t, err)
+
+	var doc atomFeed
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "1970-01-01T00:00:00Z", doc.Updated)
+	assert.Empty(t, doc.Entries)
+}
+
+func TestJSON(t *testing.T) {
+	body, err := testFeed().JSON()
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(body, &doc))
+
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", doc["version"])
+	assert.Equal(t, "https://example.com/feeds/groups.atom", doc["feed_url"])
+
+	items := doc["items"].([]any)
+	require.Len(t, items, 2)
+	first := items[0].(map[string]any)
+	assert.Equal(t, "urn:uuid:1", first["id"])
+	assert.Equal(t, "2025-03-01T10:00:00Z", first["date_published"])
+	assert.NotContains(t, first, "date_modified")
+	assert.Equal(t, []any{"golang", "rust"}, first["tags"])
+	assert.Equal(t, "2025-03-01T11:00:00Z", items[1].(map[string]any)["date_modified"])
+}
+
+func TestETag(t *testing.T) {
+	a := ETag([]byte("a"))
+	assert.Equal(t, a, ETag([]byte("a")))
+	assert.NotEqual(t, a, ETag([]byte("b")))
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, a)
+}
+
+func TestNotModified(t *testing.T) {
+	etag := `"abc"`
+	lastModified := time.Date(2025, 3, 1, 10, 0, 0, 500, time.UTC)
+
+	request := func(headers map[string]string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/feeds/groups.atom", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return r
+	}
+
+	t.Run("no validators", func(t *testing.T) {
+		assert.False(t, NotModified(request(nil), etag, lastModified))
+	})
+
+	t.Run("matching etag among several, weak or not", func(t *testing.T) {
+		assert.True(t, NotModified(request(map[string]string{"If-None-Match": `"x", W/"abc"`}), etag, lastModified))
+		assert.True(t, NotModified(request(map[string]string{"If-None-Match": "*"}), etag, lastModified))
+	})
+
+	t.Run("etag mismatch wins over a fresh date", func(t *testing.T) {
+		r := request(map[string]string{
+			"If-None-Match":     `"old"`,
+			"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat),
+		})
+		assert.False(t, NotModified(r, etag, lastModified))
+	})
+
+	t.Run("if-modified-since at second precision", func(t *testing.T) {
+		same := request(map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)})
+		assert.True(t, NotModified(same, etag, lastModified))
+
+		before := request(map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)})
+		assert.False(t, NotModified(before, etag, lastModified))
+
+		invalid := request(map[string]string{"If-Modified-Since": "yesterday"})
+		assert.False(t, NotModified(invalid, etag, lastModified))
+	})
+}
@@ -0,0 +1,69 @@
+// Package geo provides coordinates and great-circle distances.
+package geo
+
+import (
+	"errors"
+	"math"
+)
+
+// Mean Earth radius used by the haversine formula
+const EarthRadiusKm = 6371.0
+
+var ErrInvalidPoint = errors.New("latitude must be within [-90, 90] and longitude within [-180, 180]")
+
+// Point is a WGS84 coordinate in degrees
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Validate checks that the coordinate is on the globe
+func (p Point) Validate() error {
+	if math.IsNaN(p.Lat) || math.IsNaN(p.Lon) ||
+		p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return ErrInvalidPoint
+	}
+	return nil
+}
+
+// Distance is the haversine great-circle distance between a and b in km
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// BoundingBox returns the south-west and north-east corners of a box that
+// contains every point within radiusKm of p. Near the poles and across the
+// antimeridian it spans all longitudes, so it can only narrow a search
+// before the exact Distance check.
+func BoundingBox(p Point, radiusKm float64) (sw, ne Point) {
+	angle := radiusKm / EarthRadiusKm
+	dLat := degrees(angle)
+
+	sw = Point{Lat: p.Lat - dLat, Lon: -180}
+	ne = Point{Lat: p.Lat + dLat, Lon: 180}
+	if sw.Lat <= -90 || ne.Lat >= 90 {
+		sw.Lat = math.Max(sw.Lat, -90)
+		ne.Lat = math.Min(ne.Lat, 90)
+		return sw, ne
+	}
+
+	dLon := degrees(math.Asin(math.Sin(angle) / math.Cos(radians(p.Lat))))
+	if p.Lon-dLon >= -180 && p.Lon+dLon <= 180 {
+		sw.Lon = p.Lon - dLon
+		ne.Lon = p.Lon + dLon
+	}
+	return sw, ne
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,93 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	berlin := Point{Lat: 52.52, Lon: 13.405}
+	paris := Point{Lat: 48.8566, Lon: 2.3522}
+
+	t.Run("known city pair", func(t *testing.T) {
+		assert.InDelta(t, 878, Distance(berlin, paris), 5)
+	})
+
+	t.Run("symmetric and zero for same point", func(t *testing.T) {
+		assert.InDelta(t, Distance(berlin, paris), Distance(paris, berlin), 1e-9)
+		assert.Zero(t, Distance(berlin, berlin))
+	})
+
+	t.Run("across the antimeridian", func(t *testing.T) {
+		a := Point{Lat: 0, Lon: 179.5}
+		b := Point{Lat: 0, Lon: -179.5}
+		assert.InDelta(t, 111.2, Distance(a, b), 0.5)
+	})
+
+	t.Run("antipodes", func(t *testing.T) {
+		assert.InDelta(t, math.Pi*EarthRadiusKm, Distance(Point{0, 0}, Point{0, 180}), 1e-6)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Point{Lat: -90, Lon: 180}.Validate())
+	assert.ErrorIs(t, Point{Lat: 91, Lon: 0}.Validate(), ErrInvalidPoint)
+	assert.ErrorIs(t, Point{Lat: 0, Lon: -181}.Validate(), ErrInvalidPoint)
+	assert.ErrorIs(t, Point{Lat: math.NaN(), Lon: 0}.Validate(), ErrInvalidPoint)
+}
+
+func TestBoundingBox(t *testing.T) {
+	berlin := Point{Lat: 52.52, Lon: 13.405}
+
+	cases := map[string]struct {
+		origin   Point
+		radiusKm float64
+		fullLon  bool
+	}{
+		"mid latitude":        {origin: berlin, radiusKm: 50},
+		"equator":             {origin: Point{Lat: 0, Lon: 0}, radiusKm: 500},
+		"across antimeridian": {origin: Point{Lat: 0, Lon: 179.5}, radiusKm: 200, fullLon: true},
+		"reaching the pole":   {origin: Point{Lat: 89, Lon: 10}, radiusKm: 200, fullLon: true},
+		"whole globe":         {origin: berlin, radiusKm: 20000, fullLon: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sw, ne := BoundingBox(tc.origin, tc.radiusKm)
+
+			assert.GreaterOrEqual(t, sw.Lat, -90.0)
+			assert.LessOrEqual(t, ne.Lat, 90.0)
+			if tc.fullLon {
+				assert.Equal(t, -180.0, sw.Lon)
+				assert.Equal(t, 180.0, ne.Lon)
+			}
+
+			// Points on the circle, just inside the radius, lie in the box
+			for bearing := 0.0; bearing < 360; bearing += 15 {
+				p := destination(tc.origin, bearing, tc.radiusKm*0.999)
+				assert.True(t, p.Lat >= sw.Lat && p.Lat <= ne.Lat && p.Lon >= sw.Lon && p.Lon <= ne.Lon,
+					"point %v at bearing %v outside box %v %v", p, bearing, sw, ne)
+			}
+		})
+	}
+
+	t.Run("tight at mid latitude", func(t *testing.T) {
+		sw, ne := BoundingBox(berlin, 50)
+		assert.InDelta(t, 50, Distance(berlin, Point{Lat: ne.Lat, Lon: berlin.Lon}), 0.01)
+		assert.Less(t, ne.Lon-sw.Lon, 2.0)
+	})
+}
+
+// destination is the point distanceKm from p along the initial bearing in degrees
+func destination(p Point, bearing, distanceKm float64) Point {
+	angle := distanceKm / EarthRadiusKm
+	lat1, lon1, theta := radians(p.Lat), radians(p.Lon), radians(bearing)
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angle) + math.Cos(lat1)*math.Sin(angle)*math.Cos(theta))
+	lon2 := lon1 + math.Atan2(math.Sin(theta)*math.Sin(angle)*math.Cos(lat1), math.Cos(angle)-math.Sin(lat1)*math.Sin(lat2))
+
+	lon := math.Mod(degrees(lon2)+540, 360) - 180
+	return Point{Lat: degrees(lat2), Lon: lon}
+}
@@ -0,0 +1,128 @@
+// Package language models spoken languages with a proficiency level and
+// scores how well a speaker fits a language.
+package language
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Proficiency levels, lowest first
+const (
+	Basic          = "BASIC"
+	Conversational = "CONVERSATIONAL"
+	Fluent         = "FLUENT"
+	Native         = "NATIVE"
+)
+
+var (
+	ErrInvalidCode        = errors.New("language must be an ISO 639 code like \"en\" or \"id\"")
+	ErrInvalidProficiency = errors.New("proficiency must be BASIC, CONVERSATIONAL, FLUENT or NATIVE")
+)
+
+// proficiencyWeights scores how comfortably a speaker follows a group run in
+// the language
+var proficiencyWeights = map[string]float64{
+	Basic:          0.25,
+	Conversational: 0.5,
+	Fluent:         0.85,
+	Native:         1,
+}
+
+var proficiencyRanks = map[string]int{
+	Basic:          1,
+	Conversational: 2,
+	Fluent:         3,
+	Native:         4,
+}
+
+// Spoken is a language a user speaks
+type Spoken struct {
+	Code        string `json:"code"`
+	Proficiency string `json:"proficiency"`
+}
+
+// NormalizeCode lowercases and checks an ISO 639-1 or 639-3 code
+func NormalizeCode(code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) < 2 || len(code) > 3 {
+		return "", fmt.Errorf("%w: %q", ErrInvalidCode, code)
+	}
+	for _, r := range code {
+		if r < 'a' || r > 'z' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidCode, code)
+		}
+	}
+	return code, nil
+}
+
+// NormalizeProficiency uppercases and checks a proficiency level
+func NormalizeProficiency(level string) (string, error) {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if _, ok := proficiencyRanks[level]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidProficiency, level)
+	}
+	return level, nil
+}
+
+// Normalize validates a list of spoken languages, keeping the highest
+// proficiency when a language is listed twice
+func Normalize(spoken []Spoken) ([]Spoken, error) {
+	result := make([]Spoken, 0, len(spoken))
+	index := make(map[string]int, len(spoken))
+	for _, s := range spoken {
+		code, err := NormalizeCode(s.Code)
+		if err != nil {
+			return nil, err
+		}
+		level, err := NormalizeProficiency(s.Proficiency)
+		if err != nil {
+			return nil, err
+		}
+
+		if i, ok := index[code]; ok {
+			if proficiencyRanks[level] > proficiencyRanks[result[i].Proficiency] {
+				result[i].Proficiency = level
+			}
+			continue
+		}
+		index[code] = len(result)
+		result = append(result, Spoken{Code: code, Proficiency: level})
+	}
+	return result, nil
+}
+
+// Proficiency returns the speaker's level in code, or "" if not spoken
+func Proficiency(spoken []Spoken, code string) string {
+	for _, s := range spoken {
+		if s.Code == code {
+			return s.Proficiency
+		}
+	}
+	return ""
+}
+
+// Speaks reports whether the speaker has code at minLevel or above; an
+// empty minLevel accepts any level
+func Speaks(spoken []Spoken, code, minLevel string) bool {
+	level := Proficiency(spoken, code)
+	if level == "" {
+		return false
+	}
+	return proficiencyRanks[level] >= proficiencyRanks[minLevel]
+}
+
+// Score is how well the speaker fits a group run in code, in [0, 1]
+func Score(spoken []Spoken, code string) float64 {
+	return proficiencyWeights[Proficiency(spoken, code)]
+}
+
+// Codes returns the language codes of the speaker
+func Codes(spoken []Spoken) []string {
+	codes := make([]string, len(spoken))
+	for i, s := range spoken {
+		codes[i] = s.Code
+	}
+	return codes
+}
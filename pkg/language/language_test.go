@@ -0,0 +1,60 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	code, err := NormalizeCode(" EN ")
+	assert.NoError(t, err)
+	assert.Equal(t, "en", code)
+
+	code, err = NormalizeCode("fil")
+	assert.NoError(t, err)
+	assert.Equal(t, "fil", code)
+
+	for _, bad := range []string{"", "e", "engl", "e1", "en-US"} {
+		_, err := NormalizeCode(bad)
+		assert.ErrorIs(t, err, ErrInvalidCode, bad)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Run("normalizes and keeps the highest level of duplicates", func(t *testing.T) {
+		spoken, err := Normalize([]Spoken{
+			{Code: "EN", Proficiency: "fluent"},
+			{Code: "id", Proficiency: Native},
+			{Code: "en", Proficiency: Basic},
+			{Code: "en", Proficiency: Native},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []Spoken{
+			{Code: "en", Proficiency: Native},
+			{Code: "id", Proficiency: Native},
+		}, spoken)
+	})
+
+	t.Run("rejects unknown proficiency", func(t *testing.T) {
+		_, err := Normalize([]Spoken{{Code: "en", Proficiency: "EXPERT"}})
+		assert.ErrorIs(t, err, ErrInvalidProficiency)
+	})
+}
+
+func TestSpeaksAndScore(t *testing.T) {
+	spoken := []Spoken{
+		{Code: "en", Proficiency: Fluent},
+		{Code: "id", Proficiency: Basic},
+	}
+
+	assert.True(t, Speaks(spoken, "en", Conversational))
+	assert.True(t, Speaks(spoken, "en", ""))
+	assert.False(t, Speaks(spoken, "id", Conversational))
+	assert.False(t, Speaks(spoken, "de", ""))
+
+	assert.Equal(t, 0.85, Score(spoken, "en"))
+	assert.Equal(t, 0.25, Score(spoken, "id"))
+	assert.Zero(t, Score(spoken, "de"))
+	assert.Equal(t, []string{"en", "id"}, Codes(spoken))
+}
@@ -0,0 +1,78 @@
+// Package rankeval implements offline metrics for ranked recommendations
+// with binary relevance.
+package rankeval
+
+import "math"
+
+// PrecisionAtK is the share of the top k results that are relevant
+func PrecisionAtK(ranked []string, relevant map[string]bool, k int) float64 {
+	if k <= 0 {
+		return 0
+	}
+	return float64(hits(ranked, relevant, k)) / float64(k)
+}
+
+// RecallAtK is the share of relevant items found in the top k results
+func RecallAtK(ranked []string, relevant map[string]bool, k int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	return float64(hits(ranked, relevant, k)) / float64(len(relevant))
+}
+
+// NDCGAtK is the discounted cumulative gain of the top k results divided by
+// that of an ideal ranking which puts every relevant item first
+func NDCGAtK(ranked []string, relevant map[string]bool, k int) float64 {
+	dcg := 0.0
+	for i, item := range top(ranked, k) {
+		if relevant[item] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+
+	ideal := 0.0
+	for i := 0; i < min(k, len(relevant)); i++ {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+
+	if ideal == 0 {
+		return 0
+	}
+	return dcg / ideal
+}
+
+// Coverage is the share of the catalog that appears in at least one top k list
+func Coverage(rankings [][]string, catalogSize, k int) float64 {
+	if catalogSize == 0 {
+		return 0
+	}
+
+	seen := make(map[string]bool)
+	for _, ranked := range rankings {
+		for _, item := range top(ranked, k) {
+			seen[item] = true
+		}
+	}
+
+	return float64(len(seen)) / float64(catalogSize)
+}
+
+func hits(ranked []string, relevant map[string]bool, k int) int {
+	n := 0
+	for _, item := range top(ranked, k) {
+		if relevant[item] {
+			n++
+		}
+	}
+	return n
+}
+
+func top(ranked []string, k int) []string {
+	if k < 0 {
+		k = 0
+	}
+	if len(ranked) > k {
+		return ranked[:k]
+	}
+	return ranked
+}
@@ -0,0 +1,63 @@
+package rankeval
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func relevantSet(items ...string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+func TestPrecisionAtK(t *testing.T) {
+	ranked := []string{"a", "b", "c", "d"}
+
+	t.Run("counts hits in the top k", func(t *testing.T) {
+		assert.Equal(t, 0.5, PrecisionAtK(ranked, relevantSet("a", "c"), 4))
+		assert.Equal(t, 0.5, PrecisionAtK(ranked, relevantSet("a", "c"), 2))
+	})
+
+	t.Run("short lists are penalised", func(t *testing.T) {
+		assert.Equal(t, 0.1, PrecisionAtK([]string{"a"}, relevantSet("a"), 10))
+	})
+
+	t.Run("non-positive k", func(t *testing.T) {
+		assert.Equal(t, 0.0, PrecisionAtK(ranked, relevantSet("a"), 0))
+	})
+}
+
+func TestRecallAtK(t *testing.T) {
+	ranked := []string{"a", "b", "c", "d"}
+
+	assert.Equal(t, 0.5, RecallAtK(ranked, relevantSet("a", "z"), 4))
+	assert.Equal(t, 0.0, RecallAtK(ranked, relevantSet("c"), 2))
+	assert.Equal(t, 0.0, RecallAtK(ranked, relevantSet(), 4))
+}
+
+func TestNDCGAtK(t *testing.T) {
+	t.Run("ideal ranking scores 1", func(t *testing.T) {
+		assert.InDelta(t, 1.0, NDCGAtK([]string{"a", "b", "c"}, relevantSet("a", "b"), 3), 1e-9)
+	})
+
+	t.Run("lower positions are discounted", func(t *testing.T) {
+		got := NDCGAtK([]string{"x", "a"}, relevantSet("a"), 2)
+		assert.InDelta(t, 1/math.Log2(3), got, 1e-9)
+	})
+
+	t.Run("no relevant items", func(t *testing.T) {
+		assert.Equal(t, 0.0, NDCGAtK([]string{"a"}, relevantSet(), 3))
+	})
+}
+
+func TestCoverage(t *testing.T) {
+	rankings := [][]string{{"a", "b", "c"}, {"b", "d"}}
+
+	assert.Equal(t, 0.5, Coverage(rankings, 6, 2))
+	assert.Equal(t, 0.0, Coverage(rankings, 0, 2))
+}
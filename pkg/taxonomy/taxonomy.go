@@ -0,0 +1,109 @@
+package taxonomy
+
+import "sort"
+
+// Taxonomy is an immutable forest of tags built from child -> parent edges
+type Taxonomy struct {
+	parents  map[string]string
+	children map[string][]string
+}
+
+// New builds a Taxonomy from a child -> parent map. Edges that would
+// introduce a cycle are ignored.
+func New(parents map[string]string) *Taxonomy {
+	t := &Taxonomy{
+		parents:  make(map[string]string, len(parents)),
+		children: make(map[string][]string),
+	}
+
+	// Insert in a stable order so cycle breaking is deterministic
+	keys := make([]string, 0, len(parents))
+	for child := range parents {
+		keys = append(keys, child)
+	}
+	sort.Strings(keys)
+
+	for _, child := range keys {
+		parent := parents[child]
+		if parent == "" || parent == child || t.IsAncestor(child, parent) {
+			continue
+		}
+		t.parents[child] = parent
+		t.children[parent] = append(t.children[parent], child)
+	}
+
+	return t
+}
+
+// Parent returns the direct parent of tag
+func (t *Taxonomy) Parent(tag string) (string, bool) {
+	p, ok := t.parents[tag]
+	return p, ok
+}
+
+// Children returns the direct children of tag
+func (t *Taxonomy) Children(tag string) []string {
+	return t.children[tag]
+}
+
+// IsAncestor reports whether ancestor is tag itself or lies on its path to the root
+func (t *Taxonomy) IsAncestor(ancestor, tag string) bool {
+	for cur, ok := tag, true; ok; cur, ok = t.parents[cur] {
+		if cur == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// Distance returns the number of edges between a and b through their lowest
+// common ancestor. ok is false when the tags are in different trees.
+func (t *Taxonomy) Distance(a, b string) (int, bool) {
+	if a == b {
+		return 0, true
+	}
+
+	depthFromA := make(map[string]int)
+	for d, cur, ok := 0, a, true; ok; cur, ok = t.parents[cur] {
+		depthFromA[cur] = d
+		d++
+	}
+
+	for d, cur, ok := 0, b, true; ok; cur, ok = t.parents[cur] {
+		if da, found := depthFromA[cur]; found {
+			return da + d, true
+		}
+		d++
+	}
+
+	return 0, false
+}
+
+// Related returns the tags within maxDistance edges of tag, excluding tag itself,
+// ordered by distance and then name
+func (t *Taxonomy) Related(tag string, maxDistance int) []string {
+	visited := map[string]bool{tag: true}
+	frontier := []string{tag}
+	related := make([]string, 0)
+
+	for d := 0; d < maxDistance && len(frontier) > 0; d++ {
+		next := make([]string, 0)
+		for _, cur := range frontier {
+			neighbors := append([]string(nil), t.children[cur]...)
+			if p, ok := t.parents[cur]; ok {
+				neighbors = append(neighbors, p)
+			}
+			for _, n := range neighbors {
+				if !visited[n] {
+					visited[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		sort.Strings(next)
+		related = append(related, next...)
+		frontier = next
+	}
+
+	return related
+}
@@ -0,0 +1,99 @@
+package taxonomy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTaxonomy builds:
+//
+//	frontend -> react -> nextjs
+//	frontend -> vue
+//	backend  -> go
+func newTestTaxonomy() *Taxonomy {
+	return New(map[string]string{
+		"react":  "frontend",
+		"vue":    "frontend",
+		"nextjs": "react",
+		"go":     "backend",
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("builds parent and child edges", func(t *testing.T) {
+		tx := newTestTaxonomy()
+
+		parent, ok := tx.Parent("react")
+		assert.True(t, ok)
+		assert.Equal(t, "frontend", parent)
+		assert.ElementsMatch(t, []string{"react", "vue"}, tx.Children("frontend"))
+	})
+
+	t.Run("ignores self references", func(t *testing.T) {
+		tx := New(map[string]string{"go": "go"})
+
+		_, ok := tx.Parent("go")
+		assert.False(t, ok)
+	})
+
+	t.Run("breaks cycles", func(t *testing.T) {
+		tx := New(map[string]string{"a": "b", "b": "a"})
+
+		_, aHasParent := tx.Parent("a")
+		_, bHasParent := tx.Parent("b")
+		assert.True(t, aHasParent != bHasParent, "exactly one edge of the cycle is kept")
+	})
+}
+
+func TestTaxonomy_Distance(t *testing.T) {
+	tx := newTestTaxonomy()
+
+	tests := []struct {
+		name string
+		a, b string
+		want int
+		ok   bool
+	}{
+		{"same tag", "react", "react", 0, true},
+		{"parent and child", "frontend", "react", 1, true},
+		{"child and parent", "react", "frontend", 1, true},
+		{"siblings", "react", "vue", 2, true},
+		{"grandchild and uncle", "nextjs", "vue", 3, true},
+		{"different trees", "react", "go", 0, false},
+		{"unknown tag", "react", "rust", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tx.Distance(tt.a, tt.b)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTaxonomy_Related(t *testing.T) {
+	tx := newTestTaxonomy()
+
+	t.Run("distance one", func(t *testing.T) {
+		assert.Equal(t, []string{"frontend", "nextjs"}, tx.Related("react", 1))
+	})
+
+	t.Run("distance two", func(t *testing.T) {
+		assert.Equal(t, []string{"frontend", "nextjs", "vue"}, tx.Related("react", 2))
+	})
+
+	t.Run("unknown tag", func(t *testing.T) {
+		assert.Empty(t, tx.Related("rust", 2))
+	})
+}
+
+func TestTaxonomy_IsAncestor(t *testing.T) {
+	tx := newTestTaxonomy()
+
+	assert.True(t, tx.IsAncestor("frontend", "nextjs"))
+	assert.True(t, tx.IsAncestor("react", "react"))
+	assert.False(t, tx.IsAncestor("nextjs", "frontend"))
+	assert.False(t, tx.IsAncestor("backend", "react"))
+}